@@ -31,18 +31,31 @@ import (
 	"github.com/pkg/errors"
 )
 
+var ErrStateNotArchived = errors.New("accounts: historical state is only available in archive mode")
+
 type Accounts struct {
 	sync.RWMutex
 
 	tree *avl.Tree
 
 	profile *avl.GCProfile
+
+	archive bool
 }
 
 func NewAccounts(kv store.KV) *Accounts {
 	return &Accounts{tree: avl.New(kv)}
 }
 
+// WithArchive makes accounts retain the state root committed at every block height. Garbage
+// collection is disabled while archiving, as it would otherwise prune nodes of past states.
+func (a *Accounts) WithArchive(archive bool) *Accounts {
+	a.archive = archive
+	a.tree.WithArchive(archive)
+
+	return a
+}
+
 // GC periodically garbage collects every 5 seconds. Only one
 // instance of GC worker can run at any time.
 func (a *Accounts) GC(ctx context.Context, wg *sync.WaitGroup) {
@@ -73,6 +86,38 @@ func (a *Accounts) Snapshot() *avl.Tree {
 	return snapshot
 }
 
+// SnapshotAt returns a snapshot of all accounts as they were once the block at the given
+// height was finalized.
+func (a *Accounts) SnapshotAt(height uint64) (*avl.Tree, error) {
+	if !a.archive {
+		return nil, ErrStateNotArchived
+	}
+
+	a.RLock()
+	snapshot, err := a.tree.SnapshotAt(height)
+	a.RUnlock()
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "accounts: failed to load state at height %d", height)
+	}
+
+	return snapshot, nil
+}
+
+// SnapshotWithRoot returns a snapshot of all accounts whose merkle root is the given one. Only the
+// latest state may be loaded, unless historical states are archived.
+func (a *Accounts) SnapshotWithRoot(root MerkleNodeID) (*avl.Tree, error) {
+	a.RLock()
+	snapshot, err := a.tree.SnapshotWithRoot(root)
+	a.RUnlock()
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "accounts: failed to load state with merkle root %x", root)
+	}
+
+	return snapshot, nil
+}
+
 func (a *Accounts) Commit(new *avl.Tree) error {
 	a.Lock()
 	defer a.Unlock()
@@ -86,6 +131,10 @@ func (a *Accounts) Commit(new *avl.Tree) error {
 		return errors.Wrap(err, "accounts: failed to write")
 	}
 
+	if a.archive {
+		return nil
+	}
+
 	profile := a.tree.GetGCProfile(0)
 	if profile != nil {
 		atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&a.profile)), unsafe.Pointer(profile))
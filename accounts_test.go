@@ -53,6 +53,39 @@ func TestSmartContract(t *testing.T) {
 	assert.NoError(t, quick.Check(fn, nil))
 }
 
+func TestAccountsSnapshotAt(t *testing.T) {
+	var id AccountID
+
+	accounts := NewAccounts(store.NewInmem())
+
+	_, err := accounts.SnapshotAt(0)
+	assert.Equal(t, ErrStateNotArchived, err)
+
+	accounts.WithArchive(true)
+
+	for height := uint64(1); height <= 3; height++ {
+		snapshot := accounts.Snapshot()
+		snapshot.SetViewID(height)
+
+		WriteAccountBalance(snapshot, id, height*100)
+
+		assert.NoError(t, accounts.Commit(snapshot))
+	}
+
+	for height := uint64(1); height <= 3; height++ {
+		snapshot, err := accounts.SnapshotAt(height)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		balance, _ := ReadAccountBalance(snapshot, id)
+		assert.Equal(t, height*100, balance)
+	}
+
+	// Archiving must keep the garbage collector from pruning past states.
+	assert.Nil(t, accounts.profile)
+}
+
 //func BenchmarkAccountsCommit(b *testing.B) {
 //	dbs := []string{"level"}
 //
@@ -0,0 +1,368 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/perlin-network/wavelet"
+	"github.com/perlin-network/wavelet/api/pb"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/log"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/pkg/errors"
+	"github.com/valyala/fastjson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcMethod describes the scope and the rate limit group of a method of the gRPC API.
+type grpcMethod struct {
+	scope          string
+	rateLimitGroup string
+}
+
+var grpcMethods = map[string]grpcMethod{
+	"/wavelet.api.API/SendTransaction": {conf.ScopeSubmitTx, rateLimitTxSend},
+	"/wavelet.api.API/GetAccount":      {conf.ScopeReadOnly, rateLimitAccounts},
+	"/wavelet.api.API/GetTransaction":  {conf.ScopeReadOnly, rateLimitTx},
+	"/wavelet.api.API/GetLedgerStatus": {conf.ScopeReadOnly, rateLimitLedger},
+	"/wavelet.api.API/GetContractPage": {conf.ScopeReadOnly, rateLimitContract},
+	"/wavelet.api.API/Subscribe":       {conf.ScopeReadOnly, rateLimitPoll},
+}
+
+var _ pb.APIServer = (*grpcServer)(nil)
+
+// grpcServer serves the gRPC API from the same ledger and sinks of events as the HTTP API.
+type grpcServer struct {
+	g *Gateway
+}
+
+// newGRPCServer creates a server for the gRPC API, with every call authorized and rate limited
+// as requests to the HTTP API are.
+func (g *Gateway) newGRPCServer() *grpc.Server {
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(func(
+			ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			if err := g.checkGRPC(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(
+			srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+		) error {
+			if err := g.checkGRPC(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+
+			return handler(srv, ss)
+		}),
+	)
+
+	pb.RegisterAPIServer(s, &grpcServer{g: g})
+
+	return s
+}
+
+// checkGRPC authorizes and rate limits a call to a method of the gRPC API.
+func (g *Gateway) checkGRPC(ctx context.Context, fullMethod string) error {
+	method, ok := grpcMethods[fullMethod]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", fullMethod)
+	}
+
+	var token string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = parseBearerToken(values[0])
+		}
+	}
+
+	var ip net.IP
+
+	remoteAddr := ""
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+
+		if addr, ok := p.Addr.(*net.TCPAddr); ok {
+			ip = addr.IP
+		}
+	}
+
+	switch checkScope(token, method.scope, "GRPC", fullMethod, remoteAddr) {
+	case http.StatusUnauthorized:
+		return status.Error(codes.Unauthenticated, http.StatusText(http.StatusUnauthorized))
+	case http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, http.StatusText(http.StatusForbidden))
+	}
+
+	if _, delay := g.rateLimiter.reserve(method.rateLimitGroup, token, ip, time.Now()); delay > 0 {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded; retry after %s", delay)
+	}
+
+	return nil
+}
+
+// grpcError converts an error response of the HTTP API into an error of the gRPC API.
+func grpcError(e *errResponse) error {
+	code := codes.Internal
+
+	switch e.HTTPStatusCode {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	}
+
+	return status.Error(code, e.Err.Error())
+}
+
+func (s *grpcServer) SendTransaction(
+	_ context.Context, req *pb.SendTransactionRequest,
+) (*pb.SendTransactionResponse, error) {
+	if len(req.Sender) != wavelet.SizeAccountID {
+		return nil, grpcError(ErrBadRequest(errors.Errorf("sender public key must be size %d", wavelet.SizeAccountID)))
+	}
+
+	if req.Tag > uint32(sys.TagBatch) {
+		return nil, grpcError(ErrBadRequest(errors.New("unknown transaction tag specified")))
+	}
+
+	if len(req.Signature) != wavelet.SizeSignature {
+		return nil, grpcError(ErrBadRequest(errors.Errorf("signature must be size %d", wavelet.SizeSignature)))
+	}
+
+	var (
+		sender    wavelet.AccountID
+		signature wavelet.Signature
+	)
+
+	copy(sender[:], req.Sender)
+	copy(signature[:], req.Signature)
+
+	tx := wavelet.NewSignedTransaction(sender, req.Nonce, req.Block, sys.Tag(req.Tag), req.Payload, signature)
+
+	if err := wavelet.ValidateTransaction(s.g.ledger.Snapshot(), tx); err != nil {
+		return nil, grpcError(ErrBadRequest(err))
+	}
+
+	s.g.ledger.AddTransaction(tx)
+
+	return &pb.SendTransactionResponse{Id: tx.ID[:]}, nil
+}
+
+func (s *grpcServer) GetAccount(_ context.Context, req *pb.GetAccountRequest) (*pb.Account, error) {
+	if len(req.Id) != wavelet.SizeAccountID {
+		return nil, grpcError(ErrBadRequest(errors.Errorf("account ID must be %d bytes long", wavelet.SizeAccountID)))
+	}
+
+	var id wavelet.AccountID
+	copy(id[:], req.Id)
+
+	snapshot := s.g.ledger.Snapshot()
+
+	if at, ok := req.At.(*pb.GetAccountRequest_Height); ok {
+		var errRes *errResponse
+		if snapshot, errRes = s.g.snapshotAtHeight(at.Height); errRes != nil {
+			return nil, grpcError(errRes)
+		}
+	}
+
+	res := &pb.Account{Id: id[:]}
+
+	res.Balance, _ = wavelet.ReadAccountBalance(snapshot, id)
+	res.GasBalance, _ = wavelet.ReadAccountContractGasBalance(snapshot, id)
+	res.Stake, _ = wavelet.ReadAccountStake(snapshot, id)
+	res.Reward, _ = wavelet.ReadAccountReward(snapshot, id)
+	_, res.IsContract = wavelet.ReadAccountContractCode(snapshot, id)
+	res.NumPages, _ = wavelet.ReadAccountContractNumPages(snapshot, id)
+
+	return res, nil
+}
+
+func (s *grpcServer) GetTransaction(_ context.Context, req *pb.GetTransactionRequest) (*pb.Transaction, error) {
+	if len(req.Id) != wavelet.SizeTransactionID {
+		return nil, grpcError(ErrBadRequest(
+			errors.Errorf("transaction ID must be %d bytes long", wavelet.SizeTransactionID),
+		))
+	}
+
+	var id wavelet.TransactionID
+	copy(id[:], req.Id)
+
+	tx := s.g.ledger.FindTransaction(id)
+	if tx == nil {
+		return nil, grpcError(ErrNotFound(errors.Errorf("could not find transaction with ID %x", id)))
+	}
+
+	res := &pb.Transaction{
+		Id:        tx.ID[:],
+		Sender:    tx.Sender[:],
+		Nonce:     tx.Nonce,
+		Height:    tx.Block,
+		Tag:       uint32(tx.Tag),
+		Payload:   tx.Payload,
+		Signature: tx.Signature[:],
+		Status:    pb.TransactionStatus_RECEIVED,
+	}
+
+	if tx.Block <= s.g.ledger.Blocks().Latest().Index {
+		res.Status = pb.TransactionStatus_APPLIED
+	}
+
+	return res, nil
+}
+
+func (s *grpcServer) GetLedgerStatus(context.Context, *pb.GetLedgerStatusRequest) (*pb.LedgerStatus, error) {
+	ledger := s.g.ledger
+
+	publicKey := s.g.keys.PublicKey()
+	accountsLen := wavelet.ReadAccountsLen(ledger.Snapshot())
+
+	res := &pb.LedgerStatus{
+		PublicKey:      publicKey[:],
+		NumAccounts:    accountsLen,
+		PreferredVotes: uint64(ledger.Finalizer().Progress()),
+		Block:          grpcBlock(ledger.Blocks().Latest()),
+		NumMissingTx:   uint64(ledger.Transactions().MissingLen()),
+		NumTx:          uint64(ledger.Transactions().PendingLen()),
+		NumTxInStore:   uint64(ledger.Transactions().Len()),
+	}
+
+	if preferred := ledger.Finalizer().Preferred(); preferred != nil {
+		res.Preferred = grpcBlock(preferred.Value().(*wavelet.Block))
+	}
+
+	if progress := ledger.SyncProgress(); progress.Syncing() {
+		res.Sync = &pb.SyncProgress{
+			Height:        progress.Height,
+			ChunksDone:    uint64(progress.ChunksDone),
+			ChunksTotal:   uint64(progress.ChunksTotal),
+			ChunksResumed: uint64(progress.ChunksResumed),
+			BytesDone:     progress.BytesDone,
+			EtaMs:         int64(progress.ETA(time.Now()) / time.Millisecond),
+		}
+	}
+
+	if s.g.client != nil {
+		res.Address = s.g.client.ID().Address()
+
+		for _, id := range s.g.client.ClosestPeerIDs() {
+			publicKey := id.PublicKey()
+			res.Peers = append(res.Peers, &pb.Peer{Address: id.Address(), PublicKey: publicKey[:]})
+		}
+	}
+
+	return res, nil
+}
+
+func grpcBlock(block *wavelet.Block) *pb.Block {
+	return &pb.Block{
+		Id:              block.ID[:],
+		Height:          block.Index,
+		MerkleRoot:      block.Merkle[:],
+		NumTransactions: uint64(len(block.Transactions)),
+	}
+}
+
+func (s *grpcServer) GetContractPage(_ context.Context, req *pb.GetContractPageRequest) (*pb.ContractPage, error) {
+	if len(req.Id) != wavelet.SizeTransactionID {
+		return nil, grpcError(ErrBadRequest(
+			errors.Errorf("contract ID must be %d bytes long", wavelet.SizeTransactionID),
+		))
+	}
+
+	var id wavelet.TransactionID
+	copy(id[:], req.Id)
+
+	snapshot := s.g.ledger.Snapshot()
+
+	if at, ok := req.At.(*pb.GetContractPageRequest_Height); ok {
+		var errRes *errResponse
+		if snapshot, errRes = s.g.snapshotAtHeight(at.Height); errRes != nil {
+			return nil, grpcError(errRes)
+		}
+	}
+
+	numPages, available := wavelet.ReadAccountContractNumPages(snapshot, id)
+	if !available {
+		return nil, grpcError(ErrNotFound(errors.Errorf("could not find any pages for contract with ID %x", id)))
+	}
+
+	if req.Index >= numPages {
+		return nil, grpcError(ErrBadRequest(errors.Errorf(
+			"contract with ID %x only has %d pages, but you requested page %d", id, numPages, req.Index,
+		)))
+	}
+
+	page, _ := wavelet.ReadAccountContractPage(snapshot, id, req.Index)
+
+	return &pb.ContractPage{Page: page, NumPages: numPages}, nil
+}
+
+func (s *grpcServer) Subscribe(req *pb.SubscribeRequest, stream pb.API_SubscribeServer) error {
+	name := strings.ToLower(req.Sink.String())
+
+	sink, exists := s.g.sink(name)
+	if !exists {
+		return status.Errorf(codes.InvalidArgument, "unknown sink %s", req.Sink)
+	}
+
+	filters := sink.clientFilters(func(queryKey string) string {
+		return req.Filters[queryKey]
+	})
+
+	var parser fastjson.Parser
+
+	err := sink.subscribe(stream.Context(), filters, s.g.newClientQueue(), func(buf []byte) error {
+		v, err := parser.ParseBytes(buf)
+		if err != nil {
+			return nil
+		}
+
+		return stream.Send(&pb.Event{
+			Module: string(v.GetStringBytes(log.KeyModule)),
+			Event:  string(v.GetStringBytes(log.KeyEvent)),
+			Json:   buf,
+		})
+	})
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return nil
+	}
+
+	if err == errSlowClient {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package api
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/perlin-network/noise/skademlia"
+	"github.com/perlin-network/wavelet/api/pb"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestGRPC(t *testing.T) (*Gateway, pb.APIClient) {
+	gateway := New()
+	gateway.setup()
+
+	gateway.ledger = createLedger(t)
+
+	keys, err := skademlia.NewKeys(1, 1)
+	require.NoError(t, err)
+
+	gateway.keys = keys
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := gateway.newGRPCServer()
+	go func() {
+		_ = s.Serve(ln)
+	}()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		s.Stop()
+	})
+
+	return gateway, pb.NewAPIClient(conn)
+}
+
+func TestGRPC(t *testing.T) {
+	gateway, client := newTestGRPC(t)
+
+	ctx := context.Background()
+
+	status, err := client.GetLedgerStatus(ctx, &pb.GetLedgerStatusRequest{})
+	require.NoError(t, err)
+
+	publicKey := gateway.keys.PublicKey()
+	assert.Equal(t, publicKey[:], status.PublicKey)
+	assert.EqualValues(t, 0, status.Block.Height)
+
+	_, err = client.GetAccount(ctx, &pb.GetAccountRequest{Id: []byte{1, 2, 3}})
+	assertCode(t, codes.InvalidArgument, err)
+
+	account, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: publicKey[:]})
+	require.NoError(t, err)
+	assert.Equal(t, publicKey[:], account.Id)
+	assert.False(t, account.IsContract)
+
+	_, err = client.GetAccount(ctx, &pb.GetAccountRequest{
+		Id: publicKey[:],
+		At: &pb.GetAccountRequest_Height{Height: 100},
+	})
+	assertCode(t, codes.InvalidArgument, err)
+
+	_, err = client.GetTransaction(ctx, &pb.GetTransactionRequest{Id: make([]byte, 32)})
+	assertCode(t, codes.NotFound, err)
+
+	tx := newTransaction(gateway.keys, sys.TagTransfer, 1, 0, []byte("payload"))
+	gateway.ledger.AddTransaction(tx)
+
+	res, err := client.GetTransaction(ctx, &pb.GetTransactionRequest{Id: tx.ID[:]})
+	require.NoError(t, err)
+	assert.Equal(t, tx.Sender[:], res.Sender)
+	assert.Equal(t, tx.Payload, res.Payload)
+	assert.Equal(t, tx.Signature[:], res.Signature)
+
+	_, err = client.SendTransaction(ctx, &pb.SendTransactionRequest{Sender: []byte{1}})
+	assertCode(t, codes.InvalidArgument, err)
+
+	_, err = client.GetContractPage(ctx, &pb.GetContractPageRequest{Id: make([]byte, 32)})
+	assertCode(t, codes.NotFound, err)
+}
+
+func TestGRPCAuthorize(t *testing.T) {
+	defer conf.Reset()
+
+	conf.Update(
+		conf.WithSecret("secret"),
+		conf.WithAPIPublicScopes(conf.ScopeReadOnly),
+		conf.WithAPIKeys(conf.APIKey{Name: "explorer", Key: "explorer", Scopes: []string{conf.ScopeReadOnly}}),
+	)
+
+	_, client := newTestGRPC(t)
+
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", authPrefix+key)
+	}
+
+	req := &pb.SendTransactionRequest{Sender: []byte{1}}
+
+	_, err := client.GetLedgerStatus(context.Background(), &pb.GetLedgerStatusRequest{})
+	assert.NoError(t, err)
+
+	_, err = client.SendTransaction(context.Background(), req)
+	assertCode(t, codes.Unauthenticated, err)
+
+	_, err = client.SendTransaction(withKey("unknown"), req)
+	assertCode(t, codes.Unauthenticated, err)
+
+	_, err = client.SendTransaction(withKey("explorer"), req)
+	assertCode(t, codes.PermissionDenied, err)
+
+	// The request is authorized, but invalid.
+	_, err = client.SendTransaction(withKey("secret"), req)
+	assertCode(t, codes.InvalidArgument, err)
+}
+
+func TestGRPCRateLimit(t *testing.T) {
+	defer conf.Reset()
+
+	conf.Update(conf.WithAPIRateLimit(rateLimitLedger, conf.APIRateLimit{PerSecond: 1, Burst: 1}))
+
+	_, client := newTestGRPC(t)
+
+	_, err := client.GetLedgerStatus(context.Background(), &pb.GetLedgerStatusRequest{})
+	assert.NoError(t, err)
+
+	_, err = client.GetLedgerStatus(context.Background(), &pb.GetLedgerStatusRequest{})
+	assertCode(t, codes.ResourceExhausted, err)
+}
+
+func TestGRPCSubscribe(t *testing.T) {
+	gateway, client := newTestGRPC(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Subscribe(ctx, &pb.SubscribeRequest{
+		Sink:    pb.Sink_ACCOUNTS,
+		Filters: map[string]string{"id": "bb"},
+	})
+	require.NoError(t, err)
+
+	events := make(chan *pb.Event)
+
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				close(events)
+				return
+			}
+
+			events <- event
+		}
+	}()
+
+	// Keep broadcasting until the subscription has joined the sink.
+	for {
+		_, err := gateway.Write([]byte(`{"mod":"accounts","event":"balance_updated","account_id":"aa"}`))
+		require.NoError(t, err)
+
+		_, err = gateway.Write([]byte(`{"mod":"accounts","event":"balance_updated","account_id":"bb"}`))
+		require.NoError(t, err)
+
+		select {
+		case event, ok := <-events:
+			require.True(t, ok)
+
+			assert.Equal(t, "accounts", event.Module)
+			assert.Equal(t, "balance_updated", event.Event)
+			assert.Contains(t, string(event.Json), `"mod":"accounts","event":"balance_updated","account_id":"bb"}`)
+
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func assertCode(t *testing.T, code codes.Code, err error) {
+	t.Helper()
+
+	if assert.Error(t, err) {
+		assert.Equal(t, code, status.Code(err))
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fastjson"
+)
+
+// Error codes of JSON-RPC 2.0. Codes from -32000 to -32099 are for errors returned by the HTTP
+// API a call is made to.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+
+	rpcUnauthorized = -32001
+	rpcForbidden    = -32003
+	rpcNotFound     = -32004
+	rpcRateLimited  = -32029
+)
+
+// rpcMaxBatchSize is the maximum number of calls that may be batched into a single request to /rpc.
+const rpcMaxBatchSize = 100
+
+// rpcMethod converts the params of a JSON-RPC call into a request to a route of the HTTP API.
+type rpcMethod func(params *fastjson.Value) (method string, uri string, body []byte, err error)
+
+var rpcMethods = map[string]rpcMethod{
+	"ledgerStatus": func(params *fastjson.Value) (string, string, []byte, error) {
+		return http.MethodGet, "/ledger", nil, nil
+	},
+	"getAccount": func(params *fastjson.Value) (string, string, []byte, error) {
+		id, err := rpcHexParam(params, "id")
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		return http.MethodGet, "/accounts/" + id + rpcQuery(params, "height"), nil, nil
+	},
+	"getTransaction": func(params *fastjson.Value) (string, string, []byte, error) {
+		id, err := rpcHexParam(params, "id")
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		return http.MethodGet, "/tx/" + id, nil, nil
+	},
+	"listTransactions": func(params *fastjson.Value) (string, string, []byte, error) {
+		return http.MethodGet, "/tx" + rpcQuery(params, "sender", "offset", "limit"), nil, nil
+	},
+	"sendTransaction": func(params *fastjson.Value) (string, string, []byte, error) {
+		if params == nil {
+			return "", "", nil, errors.New("params must be specified")
+		}
+
+		return http.MethodPost, "/tx/send", params.MarshalTo(nil), nil
+	},
+	"getContractCode": func(params *fastjson.Value) (string, string, []byte, error) {
+		id, err := rpcHexParam(params, "id")
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		return http.MethodGet, "/contract/" + id + rpcQuery(params, "height"), nil, nil
+	},
+	"getContractPages": func(params *fastjson.Value) (string, string, []byte, error) {
+		id, err := rpcHexParam(params, "id")
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		index := "0"
+		if v := params.Get("index"); v != nil {
+			index = rpcParamString(v)
+		}
+
+		return http.MethodGet, "/contract/" + id + "/page/" + url.PathEscape(index) + rpcQuery(params, "height"), nil, nil
+	},
+}
+
+// rpcHexParam returns a param which is a hex-encoded string, such as an account or transaction ID.
+func rpcHexParam(params *fastjson.Value, key string) (string, error) {
+	if params == nil || params.Get(key) == nil {
+		return "", errors.Errorf("param %q must be specified", key)
+	}
+
+	raw, err := params.Get(key).StringBytes()
+	if err != nil {
+		return "", errors.Errorf("param %q must be a string", key)
+	}
+
+	if _, err := hex.DecodeString(string(raw)); err != nil {
+		return "", errors.Wrapf(err, "param %q must be presented as valid hex", key)
+	}
+
+	return string(raw), nil
+}
+
+// rpcQuery converts the params of a call with the given keys into a query string.
+func rpcQuery(params *fastjson.Value, keys ...string) string {
+	if params == nil {
+		return ""
+	}
+
+	values := url.Values{}
+
+	for _, key := range keys {
+		if v := params.Get(key); v != nil && v.Type() != fastjson.TypeNull {
+			values.Set(key, rpcParamString(v))
+		}
+	}
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	return "?" + values.Encode()
+}
+
+func rpcParamString(v *fastjson.Value) string {
+	if v.Type() == fastjson.TypeString {
+		return string(v.GetStringBytes())
+	}
+
+	return string(v.MarshalTo(nil))
+}
+
+type rpcError struct {
+	code    int
+	message string
+	status  int
+}
+
+// rpcErrorOf converts an error response of the HTTP API into an error of a JSON-RPC call.
+func rpcErrorOf(status int, body []byte) *rpcError {
+	e := &rpcError{code: rpcInternalError, message: http.StatusText(status), status: status}
+
+	switch status {
+	case http.StatusBadRequest:
+		e.code = rpcInvalidParams
+	case http.StatusUnauthorized:
+		e.code = rpcUnauthorized
+	case http.StatusForbidden:
+		e.code = rpcForbidden
+	case http.StatusNotFound:
+		e.code = rpcNotFound
+	case http.StatusTooManyRequests:
+		e.code = rpcRateLimited
+	}
+
+	var parser fastjson.Parser
+
+	if v, err := parser.ParseBytes(body); err == nil {
+		if msg := v.GetStringBytes("error"); len(msg) > 0 {
+			e.message = string(msg)
+		}
+	}
+
+	return e
+}
+
+// rpc serves JSON-RPC 2.0 calls, which may be batched, by making each call to the route of the
+// HTTP API it mirrors. Calls are authorized and rate limited as requests to the route are.
+func (g *Gateway) rpc(ctx *fasthttp.RequestCtx) {
+	parser := g.parserPool.Get()
+	defer g.parserPool.Put(parser)
+
+	arena := g.arenaPool.Get()
+	defer func() {
+		arena.Reset()
+		g.arenaPool.Put(arena)
+	}()
+
+	v, err := parser.ParseBytes(ctx.PostBody())
+	if err != nil {
+		g.renderRPC(ctx, rpcErrorResponse(arena, nil, &rpcError{code: rpcParseError, message: err.Error()}))
+		return
+	}
+
+	if v.Type() != fastjson.TypeArray {
+		if res := g.call(ctx, arena, v); res != nil {
+			g.renderRPC(ctx, res)
+		} else {
+			ctx.SetStatusCode(http.StatusNoContent)
+		}
+
+		return
+	}
+
+	calls, _ := v.Array()
+
+	if len(calls) == 0 || len(calls) > rpcMaxBatchSize {
+		err := &rpcError{
+			code:    rpcInvalidRequest,
+			message: "batch must contain between 1 and " + strconv.Itoa(rpcMaxBatchSize) + " calls",
+		}
+		g.renderRPC(ctx, rpcErrorResponse(arena, nil, err))
+
+		return
+	}
+
+	batch := arena.NewArray()
+	n := 0
+
+	for _, call := range calls {
+		if res := g.call(ctx, arena, call); res != nil {
+			batch.SetArrayItem(n, res)
+			n++
+		}
+	}
+
+	if n == 0 {
+		ctx.SetStatusCode(http.StatusNoContent)
+		return
+	}
+
+	g.renderRPC(ctx, batch)
+}
+
+// call makes a single JSON-RPC call, and returns its response. Notifications, which are calls
+// without an ID, have no response.
+func (g *Gateway) call(ctx *fasthttp.RequestCtx, arena *fastjson.Arena, call *fastjson.Value) *fastjson.Value {
+	if call.Type() != fastjson.TypeObject || string(call.GetStringBytes("jsonrpc")) != "2.0" {
+		return rpcErrorResponse(arena, nil, &rpcError{code: rpcInvalidRequest, message: "invalid request"})
+	}
+
+	id := call.Get("id")
+
+	respond := func(res *fastjson.Value) *fastjson.Value {
+		if id == nil {
+			return nil
+		}
+
+		return res
+	}
+
+	name := string(call.GetStringBytes("method"))
+
+	method, exists := rpcMethods[name]
+	if !exists {
+		return respond(rpcErrorResponse(arena, id, &rpcError{
+			code: rpcMethodNotFound, message: "method " + strconv.Quote(name) + " not found",
+		}))
+	}
+
+	params := call.Get("params")
+	if params != nil && params.Type() == fastjson.TypeNull {
+		params = nil
+	}
+
+	if params != nil && params.Type() != fastjson.TypeObject {
+		return respond(rpcErrorResponse(arena, id, &rpcError{
+			code: rpcInvalidParams, message: "params must be an object",
+		}))
+	}
+
+	httpMethod, uri, body, err := method(params)
+	if err != nil {
+		return respond(rpcErrorResponse(arena, id, &rpcError{code: rpcInvalidParams, message: err.Error()}))
+	}
+
+	var req fasthttp.Request
+
+	req.Header.SetMethod(httpMethod)
+	req.SetRequestURI(uri)
+	req.SetBody(body)
+
+	if auth := ctx.Request.Header.Peek("Authorization"); len(auth) > 0 {
+		req.Header.SetBytesV("Authorization", auth)
+	}
+
+	var inner fasthttp.RequestCtx
+
+	inner.Init(&req, ctx.RemoteAddr(), nil)
+	g.router.Handler(&inner)
+
+	status := inner.Response.StatusCode()
+
+	if status != http.StatusOK {
+		return respond(rpcErrorResponse(arena, id, rpcErrorOf(status, inner.Response.Body())))
+	}
+
+	var result *fastjson.Value
+
+	if bytes.HasPrefix(inner.Response.Header.ContentType(), []byte("application/json")) {
+		var parser fastjson.Parser
+
+		if result, err = parser.ParseBytes(inner.Response.Body()); err != nil {
+			return respond(rpcErrorResponse(arena, id, &rpcError{code: rpcInternalError, message: err.Error()}))
+		}
+	} else {
+		result = arena.NewString(hex.EncodeToString(inner.Response.Body()))
+	}
+
+	res := arena.NewObject()
+	res.Set("jsonrpc", arena.NewString("2.0"))
+	res.Set("result", result)
+	res.Set("id", id)
+
+	return respond(res)
+}
+
+func rpcErrorResponse(arena *fastjson.Arena, id *fastjson.Value, e *rpcError) *fastjson.Value {
+	if id == nil {
+		id = arena.NewNull()
+	}
+
+	o := arena.NewObject()
+	o.Set("code", arena.NewNumberInt(e.code))
+	o.Set("message", arena.NewString(e.message))
+
+	if e.status != 0 {
+		data := arena.NewObject()
+		data.Set("status", arena.NewNumberInt(e.status))
+		o.Set("data", data)
+	}
+
+	res := arena.NewObject()
+	res.Set("jsonrpc", arena.NewString("2.0"))
+	res.Set("error", o)
+	res.Set("id", id)
+
+	return res
+}
+
+func (g *Gateway) renderRPC(ctx *fasthttp.RequestCtx, res *fastjson.Value) {
+	ctx.SetContentType("application/json")
+	ctx.Response.SetBody(res.MarshalTo(nil))
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callRPC(t *testing.T, gateway *Gateway, body string, headers ...string) (int, string) {
+	t.Helper()
+
+	request, err := http.NewRequest("POST", "http://localhost/rpc", strings.NewReader(body))
+	require.NoError(t, err)
+
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+
+	w, err := serve(gateway.router, request)
+	require.NoError(t, err)
+
+	res, err := ioutil.ReadAll(w.Body)
+	require.NoError(t, err)
+
+	return w.StatusCode, string(res)
+}
+
+func TestRPC(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	gateway.ledger = createLedger(t)
+
+	idHex := "1c00000000000000000000000000000000000000000000000000000000000000"
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		want     string
+	}{
+		{
+			name:     "parse error",
+			body:     `{`,
+			wantCode: http.StatusOK,
+			want:     `{"jsonrpc":"2.0","error":{"code":-32700,"message":"cannot parse JSON: cannot parse object: missing '}'; unparsed tail: \"\""},"id":null}`, // nolint:lll
+		},
+		{
+			name:     "invalid request",
+			body:     `{"method":"ledgerStatus","id":1}`,
+			wantCode: http.StatusOK,
+			want:     `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}`,
+		},
+		{
+			name:     "empty batch",
+			body:     `[]`,
+			wantCode: http.StatusOK,
+			want:     `{"jsonrpc":"2.0","error":{"code":-32600,"message":"batch must contain between 1 and 100 calls"},"id":null}`, // nolint:lll
+		},
+		{
+			name:     "method not found",
+			body:     `{"jsonrpc":"2.0","method":"restart","id":"a"}`,
+			wantCode: http.StatusOK,
+			want:     `{"jsonrpc":"2.0","error":{"code":-32601,"message":"method \"restart\" not found"},"id":"a"}`,
+		},
+		{
+			name:     "invalid params",
+			body:     `{"jsonrpc":"2.0","method":"getAccount","params":{"id":"../node/restart"},"id":1}`,
+			wantCode: http.StatusOK,
+			want:     `{"jsonrpc":"2.0","error":{"code":-32602,"message":"param \"id\" must be presented as valid hex: encoding/hex: invalid byte: U+002E '.'"},"id":1}`, // nolint:lll
+		},
+		{
+			name:     "notification",
+			body:     `{"jsonrpc":"2.0","method":"getAccount","params":{"id":"` + idHex + `"}}`,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "get account",
+			body:     `{"jsonrpc":"2.0","method":"getAccount","params":{"id":"` + idHex + `"},"id":1}`,
+			wantCode: http.StatusOK,
+			want:     `{"jsonrpc":"2.0","result":{"public_key":"` + idHex + `","balance":0,"gas_balance":0,"stake":0,"reward":0,"is_contract":false},"id":1}`, // nolint:lll
+		},
+		{
+			name: "batch",
+			body: `[
+				{"jsonrpc":"2.0","method":"listTransactions","params":{"sender":"` + idHex + `","limit":10},"id":1},
+				{"jsonrpc":"2.0","method":"getTransaction","params":{"id":"` + idHex + `"},"id":2},
+				{"jsonrpc":"2.0","method":"getAccount","params":{"id":"` + idHex + `"}},
+				1
+			]`,
+			wantCode: http.StatusOK,
+			want: `[` +
+				`{"jsonrpc":"2.0","result":[],"id":1},` +
+				`{"jsonrpc":"2.0","error":{"code":-32004,"message":"could not find transaction with ID ` + idHex + `","data":{"status":404}},"id":2},` + // nolint:lll
+				`{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}` +
+				`]`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			code, res := callRPC(t, gateway, tc.body)
+			assert.Equal(t, tc.wantCode, code)
+
+			if len(tc.want) > 0 {
+				assert.JSONEq(t, tc.want, res)
+			}
+		})
+	}
+}
+
+func TestRPCAuthorize(t *testing.T) {
+	defer conf.Reset()
+
+	conf.Update(
+		conf.WithSecret("secret"),
+		conf.WithAPIPublicScopes(conf.ScopeReadOnly),
+	)
+
+	gateway := New()
+	gateway.setup()
+
+	gateway.ledger = createLedger(t)
+
+	body := `[
+		{"jsonrpc":"2.0","method":"listTransactions","id":1},
+		{"jsonrpc":"2.0","method":"sendTransaction","params":{"sender":"00"},"id":2}
+	]`
+
+	_, res := callRPC(t, gateway, body)
+	assert.Contains(t, res, `"result":[]`)
+	assert.Contains(t, res, `"error":{"code":-32001,"message":"Unauthorized","data":{"status":401}},"id":2`)
+
+	// The call is authorized, but its params are invalid.
+	_, res = callRPC(t, gateway, body, "Authorization", "Bearer secret")
+	assert.Contains(t, res, `"code":-32602`)
+	assert.NotContains(t, res, `"code":-32001`)
+}
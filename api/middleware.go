@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"github.com/perlin-network/wavelet"
 	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/log"
 	"github.com/pkg/errors"
 	"github.com/valyala/fasthttp"
 	"net/http"
@@ -114,14 +115,93 @@ func oAuth2(ctx *fasthttp.RequestCtx) string {
 	return parseBearerToken(string(auth))
 }
 
-func (g *Gateway) auth(next fasthttp.RequestHandler) fasthttp.RequestHandler {
-	return func(ctx *fasthttp.RequestCtx) {
-		if len(conf.GetSecret()) > 0 && oAuth2(ctx) == conf.GetSecret() {
-			next(ctx)
-			return
+// authorize restricts access to routes of a scope to requests bearing an API key granted the
+// scope, unless the scope is public. The shared secret is granted all scopes.
+func (g *Gateway) authorize(scope string) middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			status := checkScope(
+				oAuth2(ctx), scope, string(ctx.Method()), string(ctx.Path()), ctx.RemoteAddr().String(),
+			)
+
+			switch status {
+			case fasthttp.StatusUnauthorized:
+				ctx.Error(fasthttp.StatusMessage(fasthttp.StatusUnauthorized), fasthttp.StatusUnauthorized)
+				ctx.Response.Header.Set("WWW-Authenticate", "Bearer realm=Restricted")
+			case fasthttp.StatusForbidden:
+				ctx.Error(fasthttp.StatusMessage(fasthttp.StatusForbidden), fasthttp.StatusForbidden)
+			default:
+				next(ctx)
+			}
+		}
+	}
+}
+
+// checkScope checks whether a request bearing a token may access a scope, and returns the HTTP
+// status code the request should be answered with should it not. Every request authorized by a
+// key is logged for auditing.
+//
+// Public scopes may be accessed bearing any token, as clients bear tokens which are not keys,
+// such as rate limit tiers or a stale shared secret.
+func checkScope(token, scope, method, path, remoteAddr string) int {
+	key, ok := apiKey(token)
+
+	if !ok && conf.IsAPIPublicScope(scope) {
+		return fasthttp.StatusOK
+	}
+
+	logger := log.Node()
+
+	if !ok {
+		if len(token) > 0 {
+			logger.Warn().
+				Str("scope", scope).
+				Str("method", method).
+				Str("path", path).
+				Str("remote_addr", remoteAddr).
+				Msg("Rejected API request bearing an unknown key.")
 		}
 
-		ctx.Error(fasthttp.StatusMessage(fasthttp.StatusUnauthorized), fasthttp.StatusUnauthorized)
-		ctx.Response.Header.Set("WWW-Authenticate", "Bearer realm=Restricted")
+		return fasthttp.StatusUnauthorized
 	}
+
+	if !key.HasScope(scope) && !conf.IsAPIPublicScope(scope) {
+		logger.Warn().
+			Str("api_key", key.Name).
+			Str("scope", scope).
+			Str("method", method).
+			Str("path", path).
+			Str("remote_addr", remoteAddr).
+			Msg("Rejected API request bearing a key without the required scope.")
+
+		return fasthttp.StatusForbidden
+	}
+
+	logger.Info().
+		Str("api_key", key.Name).
+		Str("scope", scope).
+		Str("method", method).
+		Str("path", path).
+		Str("remote_addr", remoteAddr).
+		Msg("Authorized API request.")
+
+	return fasthttp.StatusOK
+}
+
+// apiKey returns the API key of a bearer token. The shared secret is
+// treated as a key which is granted all scopes.
+func apiKey(token string) (conf.APIKey, bool) {
+	if len(token) == 0 {
+		return conf.APIKey{}, false
+	}
+
+	if key, ok := conf.GetAPIKey(token); ok {
+		return key, true
+	}
+
+	if secret := conf.GetSecret(); len(secret) > 0 && token == secret {
+		return conf.APIKey{Name: "secret", Key: secret, Scopes: conf.Scopes}, true
+	}
+
+	return conf.APIKey{}, false
 }
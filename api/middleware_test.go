@@ -0,0 +1,95 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package api
+
+import (
+	"net"
+	"testing"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestAuthorize(t *testing.T) {
+	defer conf.Reset()
+
+	conf.Update(
+		conf.WithSecret("secret"),
+		conf.WithAPIKeys(
+			conf.APIKey{Name: "explorer", Key: "explorer", Scopes: []string{conf.ScopeReadOnly}},
+			conf.APIKey{Name: "profiler", Key: "profiler", Scopes: []string{conf.ScopeDebug}},
+		),
+		conf.WithAPIRateLimitTier("premium", 2),
+	)
+
+	g := New()
+
+	request := func(scope string, token string) int {
+		handler := g.authorize(scope)(func(ctx *fasthttp.RequestCtx) {
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		})
+
+		var req fasthttp.Request
+		if len(token) > 0 {
+			req.Header.Set("Authorization", authPrefix+token)
+		}
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Init(&req, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234}, nil)
+
+		handler(ctx)
+
+		return ctx.Response.StatusCode()
+	}
+
+	// Public scopes may be accessed without a key.
+	assert.Equal(t, fasthttp.StatusOK, request(conf.ScopeReadOnly, ""))
+	assert.Equal(t, fasthttp.StatusOK, request(conf.ScopeSubmitTx, ""))
+	assert.Equal(t, fasthttp.StatusUnauthorized, request(conf.ScopeDebug, ""))
+	assert.Equal(t, fasthttp.StatusUnauthorized, request(conf.ScopeNodeAdmin, ""))
+
+	// Tokens which are not keys, such as rate limit tiers, may only access public scopes.
+	assert.Equal(t, fasthttp.StatusOK, request(conf.ScopeReadOnly, "premium"))
+	assert.Equal(t, fasthttp.StatusOK, request(conf.ScopeSubmitTx, "unknown"))
+	assert.Equal(t, fasthttp.StatusUnauthorized, request(conf.ScopeDebug, "premium"))
+	assert.Equal(t, fasthttp.StatusUnauthorized, request(conf.ScopeNodeAdmin, "unknown"))
+
+	// Keys may only access their own scopes, and public scopes.
+	assert.Equal(t, fasthttp.StatusOK, request(conf.ScopeDebug, "profiler"))
+	assert.Equal(t, fasthttp.StatusOK, request(conf.ScopeSubmitTx, "profiler"))
+	assert.Equal(t, fasthttp.StatusForbidden, request(conf.ScopeNodeAdmin, "profiler"))
+	assert.Equal(t, fasthttp.StatusForbidden, request(conf.ScopeDebug, "explorer"))
+
+	// The shared secret is granted all scopes.
+	for _, scope := range conf.Scopes {
+		assert.Equal(t, fasthttp.StatusOK, request(scope, "secret"))
+	}
+
+	// Keys are required to access any scope once no scopes are public.
+	conf.Update(conf.WithAPIPublicScopes())
+
+	assert.Equal(t, fasthttp.StatusUnauthorized, request(conf.ScopeReadOnly, ""))
+	assert.Equal(t, fasthttp.StatusUnauthorized, request(conf.ScopeReadOnly, "premium"))
+	assert.Equal(t, fasthttp.StatusOK, request(conf.ScopeReadOnly, "explorer"))
+	assert.Equal(t, fasthttp.StatusForbidden, request(conf.ScopeSubmitTx, "explorer"))
+}
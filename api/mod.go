@@ -29,6 +29,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -36,6 +37,8 @@ import (
 	"github.com/buaazp/fasthttprouter"
 	"github.com/perlin-network/noise/skademlia"
 	"github.com/perlin-network/wavelet"
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/perlin-network/wavelet/conf"
 	"github.com/perlin-network/wavelet/log"
 	"github.com/perlin-network/wavelet/store"
 	"github.com/perlin-network/wavelet/sys"
@@ -51,8 +54,17 @@ import (
 const (
 	statusApplied  = "applied"
 	statusReceived = "received"
+	statusRejected = "rejected"
+	statusPending  = "pending"
 )
 
+// route is a route of the HTTP API, which the OpenAPI document of the API is generated from.
+type route struct {
+	method, path   string
+	rateLimitGroup string
+	scope          string
+}
+
 type Gateway struct {
 	client *skademlia.Client
 	ledger *wavelet.Ledger
@@ -61,11 +73,17 @@ type Gateway struct {
 	keys *skademlia.Keypair
 
 	router  *fasthttprouter.Router
+	routes  []route
 	servers []*fasthttp.Server
 
+	grpcPort   int
+	grpcServer *grpc.Server
+
 	sinks     map[string]*sink
 	sinksLock sync.RWMutex
 
+	webhooks *webhooks
+
 	enableTimeout bool
 
 	rateLimiter *rateLimiter
@@ -77,12 +95,20 @@ type Gateway struct {
 func New() *Gateway {
 	return &Gateway{
 		sinks:       make(map[string]*sink),
+		webhooks:    newWebhooks(),
 		parserPool:  new(fastjson.ParserPool),
 		arenaPool:   new(fastjson.ArenaPool),
-		rateLimiter: newRateLimiter(1000),
+		rateLimiter: newRateLimiter(),
 	}
 }
 
+// WithGRPCPort serves the gRPC API on a port alongside the HTTP API. The gRPC API is disabled
+// if the port is 0.
+func (g *Gateway) WithGRPCPort(port int) *Gateway {
+	g.grpcPort = port
+	return g
+}
+
 func (g *Gateway) setup() {
 	// Setup websocket logging sinks.
 	sinkNetwork := g.registerWebsocketSink("ws://network/")
@@ -97,55 +123,92 @@ func (g *Gateway) setup() {
 	// Setup HTTP router.
 
 	r := fasthttprouter.New()
+	g.routes = nil
 
 	// If the route does not exist for a method type (e.g. OPTIONS), fasthttprouter will consider it to not exist.
 	// So, we need to override notFound handler for OPTIONS method type to handle CORS.
 	r.HandleOPTIONS = false
 	r.NotFound = g.notFound()
 
-	// Websocket endpoints.
-	r.GET("/poll/network", g.applyMiddleware(g.poll(sinkNetwork), "/poll/network"))
-	r.GET("/poll/consensus", g.applyMiddleware(g.poll(sinkConsensus), "/poll/consensus"))
-	r.GET("/poll/accounts", g.applyMiddleware(g.poll(sinkAccounts), "/poll/accounts"))
-	r.GET("/poll/contract", g.applyMiddleware(g.poll(sinkContracts), "/poll/contract"))
-	r.GET("/poll/tx", g.applyMiddleware(g.poll(sinkTransactions), "/poll/tx"))
-	r.GET("/poll/metrics", g.applyMiddleware(g.poll(sinkMetrics), "/poll/metrics"))
+	// Websocket endpoints. Sockets to /poll subscribe to topics over the socket.
+	g.handle(r, http.MethodGet, "/poll", g.poll(nil), rateLimitPoll, conf.ScopeReadOnly)
+	g.handle(r, http.MethodGet, "/poll/network", g.poll(sinkNetwork), rateLimitPoll, conf.ScopeReadOnly)
+	g.handle(r, http.MethodGet, "/poll/consensus", g.poll(sinkConsensus), rateLimitPoll, conf.ScopeReadOnly)
+	g.handle(r, http.MethodGet, "/poll/accounts", g.poll(sinkAccounts), rateLimitPoll, conf.ScopeReadOnly)
+	g.handle(r, http.MethodGet, "/poll/contract", g.poll(sinkContracts), rateLimitPoll, conf.ScopeReadOnly)
+	g.handle(r, http.MethodGet, "/poll/tx", g.poll(sinkTransactions), rateLimitPoll, conf.ScopeReadOnly)
+	g.handle(r, http.MethodGet, "/poll/metrics", g.poll(sinkMetrics), rateLimitPoll, conf.ScopeReadOnly)
 
 	// Debug endpoint.
-	r.GET("/debug/*p", g.applyMiddleware(pprofhandler.PprofHandler, "/debug/*p"))
+	g.handle(r, http.MethodGet, "/debug/*p", pprofhandler.PprofHandler, rateLimitDebug, conf.ScopeDebug)
 
 	// Ledger endpoint.
-	r.GET("/ledger", g.applyMiddleware(g.ledgerStatus, "/ledger"))
+	g.handle(r, http.MethodGet, "/ledger", g.ledgerStatus, rateLimitLedger, conf.ScopeReadOnly)
+	g.handle(r, http.MethodGet, "/ledger/diff", g.diffState, rateLimitLedger, conf.ScopeReadOnly)
 
 	// Account endpoints.
-	r.GET("/accounts/:id", g.applyMiddleware(g.getAccount, ""))
+	g.handle(r, http.MethodGet, "/accounts/:id", g.getAccount, rateLimitAccounts, conf.ScopeReadOnly)
 
 	// Contract endpoints.
-	r.GET("/contract/:id/page/:index", g.applyMiddleware(g.getContractPages, "/contract/:id/page/:index", g.contractScope))
-	r.GET("/contract/:id/page", g.applyMiddleware(g.getContractPages, "/contract/:id/page", g.contractScope))
-	r.GET("/contract/:id", g.applyMiddleware(g.getContractCode, "/contract/:id", g.contractScope))
+	g.handle(r, http.MethodGet, "/contract/:id/page/:index",
+		g.getContractPages, rateLimitContract, conf.ScopeReadOnly, g.contractScope,
+	)
+	g.handle(r, http.MethodGet, "/contract/:id/page",
+		g.getContractPages, rateLimitContract, conf.ScopeReadOnly, g.contractScope,
+	)
+	g.handle(r, http.MethodGet, "/contract/:id",
+		g.getContractCode, rateLimitContract, conf.ScopeReadOnly, g.contractScope,
+	)
 
 	// Transaction endpoints.
-	r.POST("/tx/send", g.applyMiddleware(g.sendTransaction, ""))
-	r.GET("/tx/:id", g.applyMiddleware(g.getTransaction, ""))
-	r.GET("/tx", g.applyMiddleware(g.listTransactions, "/tx"))
+	g.handle(r, http.MethodPost, "/tx/send", g.sendTransaction, rateLimitTxSend, conf.ScopeSubmitTx)
+	g.handle(r, http.MethodGet, "/tx/:id", g.getTransaction, rateLimitTx, conf.ScopeReadOnly)
+	g.handle(r, http.MethodGet, "/tx", g.listTransactions, rateLimitTx, conf.ScopeReadOnly)
 
 	// Connectivity endpoints
-	r.POST("/node/connect", g.applyMiddleware(g.connect, "/node/connect", g.auth))
-	r.POST("/node/disconnect", g.applyMiddleware(g.disconnect, "/node/disconnect", g.auth))
-	r.POST("/node/restart", g.applyMiddleware(g.restart, "/node/restart", g.auth))
+	g.handle(r, http.MethodPost, "/node/connect", g.connect, rateLimitNode, conf.ScopeNodeAdmin)
+	g.handle(r, http.MethodPost, "/node/disconnect", g.disconnect, rateLimitNode, conf.ScopeNodeAdmin)
+	g.handle(r, http.MethodPost, "/node/restart", g.restart, rateLimitNode, conf.ScopeNodeAdmin)
+	g.handle(r, http.MethodGet, "/node/peers", g.peers, rateLimitNode, conf.ScopeReadOnly)
+
+	// Webhook endpoints. Webhooks may be pointed at any URL, so are restricted to node admins.
+	g.handle(r, http.MethodPost, "/webhooks", g.registerWebhook, rateLimitWebhooks, conf.ScopeNodeAdmin)
+	g.handle(r, http.MethodGet, "/webhooks", g.listWebhooks, rateLimitWebhooks, conf.ScopeNodeAdmin)
+	g.handle(r, http.MethodGet, "/webhooks/dead", g.listDeadLetters, rateLimitWebhooks, conf.ScopeNodeAdmin)
+	g.handle(r, http.MethodDelete, "/webhooks/:id", g.deleteWebhook, rateLimitWebhooks, conf.ScopeNodeAdmin)
+
+	// JSON-RPC endpoint. Each call is rate limited and authorized as requests to the route it mirrors are.
+	g.handle(r, http.MethodPost, "/rpc", g.rpc, "", "")
+
+	// OpenAPI document of the routes above.
+	g.handle(r, http.MethodGet, "/openapi.json", g.openAPI, "", "")
 
 	g.router = r
 }
 
+// handle registers a route. Unless they are empty, requests to the route are rate limited by
+// rateLimitGroup, and restricted to API keys granted scope.
+func (g *Gateway) handle(
+	r *fasthttprouter.Router, method, path string, f fasthttp.RequestHandler,
+	rateLimitGroup, scope string, m ...middleware,
+) {
+	if len(scope) > 0 {
+		m = append([]middleware{g.authorize(scope)}, m...)
+	}
+
+	r.Handle(method, path, g.applyMiddleware(f, rateLimitGroup, m...))
+
+	g.routes = append(g.routes, route{method: method, path: path, rateLimitGroup: rateLimitGroup, scope: scope})
+}
+
 // Apply base middleware to the handler and along with middleware passed.
-// If rateLimiterKey is not empty, enable rate limit.
+// If rateLimitGroup is not empty, enable rate limit for the group of routes.
 func (g *Gateway) applyMiddleware(
-	f fasthttp.RequestHandler, rateLimiterKey string, m ...middleware,
+	f fasthttp.RequestHandler, rateLimitGroup string, m ...middleware,
 ) fasthttp.RequestHandler {
 	var list []middleware
 
-	if len(rateLimiterKey) == 0 {
+	if len(rateLimitGroup) == 0 {
 		list = []middleware{
 			recoverer,
 			cors(),
@@ -155,7 +218,7 @@ func (g *Gateway) applyMiddleware(
 		// Rate limiter middleware should be after recoverer and before anything else
 		list = []middleware{
 			recoverer,
-			g.rateLimiter.limit(rateLimiterKey),
+			g.rateLimiter.limit(rateLimitGroup),
 			cors(),
 		}
 	}
@@ -284,6 +347,27 @@ func (g *Gateway) start(
 
 	logger := log.Node()
 
+	if err := g.loadWebhooks(); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load webhooks.")
+	}
+
+	if g.grpcPort != 0 {
+		ln, err := net.Listen("tcp4", ":"+strconv.Itoa(g.grpcPort))
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("Failed to listen to port %d.", g.grpcPort)
+		}
+
+		g.grpcServer = g.newGRPCServer()
+
+		go func() {
+			if err := g.grpcServer.Serve(ln); err != nil {
+				logger.Fatal().Err(err).Msg("Failed to start gRPC API server.")
+			}
+		}()
+
+		logger.Info().Int("port", g.grpcPort).Msg("Started gRPC API server.")
+	}
+
 	if ln2 != nil {
 		s := &fasthttp.Server{
 			Handler: g.router.Handler,
@@ -310,18 +394,32 @@ func (g *Gateway) start(
 }
 
 func (g *Gateway) Shutdown() {
+	g.stopWebhooks()
+
 	for _, s := range g.servers {
 		_ = s.Shutdown()
 	}
+
+	if g.grpcServer != nil {
+		g.grpcServer.Stop()
+	}
 }
 
 func (g *Gateway) sendTransaction(ctx *fasthttp.RequestCtx) {
 	req := &sendTransactionRequest{}
 
+	queryArgs := ctx.QueryArgs()
+
+	timeout, err := parseTxWait(queryArgs.Peek("wait"), queryArgs.Peek("timeout"))
+	if err != nil {
+		g.renderError(ctx, ErrBadRequest(err))
+		return
+	}
+
 	parser := g.parserPool.Get()
 	defer g.parserPool.Put(parser)
 
-	err := req.bind(parser, ctx.PostBody())
+	err = req.bind(parser, ctx.PostBody())
 
 	if err != nil {
 		g.renderError(ctx, ErrBadRequest(err))
@@ -341,9 +439,28 @@ func (g *Gateway) sendTransaction(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	g.ledger.AddTransaction(tx)
+	if timeout == 0 {
+		g.ledger.AddTransaction(tx)
+
+		g.render(ctx, &sendTransactionResponse{ledger: g.ledger, tx: &tx})
+
+		return
+	}
+
+	receipt, err := g.waitTransaction(tx.ID, timeout, func() {
+		g.ledger.AddTransaction(tx)
+	})
+	if err != nil {
+		g.renderError(ctx, ErrInternal(err))
+		return
+	}
 
-	g.render(ctx, &sendTransactionResponse{ledger: g.ledger, tx: &tx})
+	g.render(ctx, &sendTransactionResponse{ledger: g.ledger, tx: &tx, receipt: &receipt})
+
+	// The transaction was accepted, but is yet to be finalized.
+	if receipt.status == statusPending && ctx.Response.StatusCode() == http.StatusOK {
+		ctx.Response.SetStatusCode(http.StatusAccepted)
+	}
 }
 
 func (g *Gateway) ledgerStatus(ctx *fasthttp.RequestCtx) {
@@ -447,7 +564,7 @@ func (g *Gateway) getTransaction(ctx *fasthttp.RequestCtx) {
 
 	copy(id[:], slice)
 
-	tx := g.ledger.Transactions().Find(id)
+	tx := g.ledger.FindTransaction(id)
 
 	if tx == nil {
 		g.renderError(ctx, ErrNotFound(errors.Errorf("could not find transaction with ID %x", id)))
@@ -489,7 +606,12 @@ func (g *Gateway) getAccount(ctx *fasthttp.RequestCtx) {
 
 	copy(id[:], slice)
 
-	snapshot := g.ledger.Snapshot()
+	snapshot, errRes := g.snapshot(ctx)
+	if errRes != nil {
+		g.renderError(ctx, errRes)
+		return
+	}
+
 	balance, _ := wavelet.ReadAccountBalance(snapshot, id)
 	gasBalance, _ := wavelet.ReadAccountContractGasBalance(snapshot, id)
 	stake, _ := wavelet.ReadAccountStake(snapshot, id)
@@ -516,7 +638,13 @@ func (g *Gateway) getContractCode(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	code, available := wavelet.ReadAccountContractCode(g.ledger.Snapshot(), id)
+	snapshot, errRes := g.snapshot(ctx)
+	if errRes != nil {
+		g.renderError(ctx, errRes)
+		return
+	}
+
+	code, available := wavelet.ReadAccountContractCode(snapshot, id)
 
 	if len(code) == 0 || !available {
 		g.renderError(ctx, ErrNotFound(errors.Errorf("could not find contract with ID %x", id)))
@@ -557,7 +685,11 @@ func (g *Gateway) getContractPages(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
-	snapshot := g.ledger.Snapshot()
+	snapshot, errRes := g.snapshot(ctx)
+	if errRes != nil {
+		g.renderError(ctx, errRes)
+		return
+	}
 
 	numPages, available := wavelet.ReadAccountContractNumPages(snapshot, id)
 
@@ -583,6 +715,94 @@ func (g *Gateway) getContractPages(ctx *fasthttp.RequestCtx) {
 	_, _ = ctx.Write(page)
 }
 
+// snapshot returns the state of the ledger a request is to be served from. It is the latest
+// state, unless a block height is specified by the query parameter `height`.
+func (g *Gateway) snapshot(ctx *fasthttp.RequestCtx) (*avl.Tree, *errResponse) {
+	raw := string(ctx.QueryArgs().Peek("height"))
+	if len(raw) == 0 {
+		return g.ledger.Snapshot(), nil
+	}
+
+	height, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return nil, ErrBadRequest(errors.Wrap(err, "could not parse height"))
+	}
+
+	return g.snapshotAtHeight(height)
+}
+
+// snapshotOf returns the state of the ledger at either a block height, or a hex-encoded merkle
+// root of either the latest state or a state the ledger has archived.
+func (g *Gateway) snapshotOf(raw string) (*avl.Tree, *errResponse) {
+	if len(raw) == hex.EncodedLen(wavelet.SizeMerkleNodeID) {
+		var root wavelet.MerkleNodeID
+
+		if _, err := hex.Decode(root[:], []byte(raw)); err != nil {
+			return nil, ErrBadRequest(errors.Wrap(err, "merkle root must be presented as valid hex"))
+		}
+
+		snapshot, err := g.ledger.SnapshotWithRoot(root)
+		if err != nil {
+			return nil, ErrNotFound(err)
+		}
+
+		return snapshot, nil
+	}
+
+	height, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return nil, ErrBadRequest(errors.Wrap(err, "could not parse height or merkle root"))
+	}
+
+	return g.snapshotAtHeight(height)
+}
+
+func (g *Gateway) snapshotAtHeight(height uint64) (*avl.Tree, *errResponse) {
+	if latest := g.ledger.Blocks().Latest().Index; height > latest {
+		return nil, ErrBadRequest(errors.Errorf("height %d is above the latest block height %d", height, latest))
+	}
+
+	snapshot, err := g.ledger.SnapshotAt(height)
+	if err != nil {
+		return nil, ErrNotFound(err)
+	}
+
+	return snapshot, nil
+}
+
+func (g *Gateway) diffState(ctx *fasthttp.RequestCtx) {
+	rawFrom := string(ctx.QueryArgs().Peek("from"))
+	if len(rawFrom) == 0 {
+		g.renderError(ctx, ErrBadRequest(errors.New("a block height or merkle root to diff from must be specified")))
+		return
+	}
+
+	from, errRes := g.snapshotOf(rawFrom)
+	if errRes != nil {
+		g.renderError(ctx, errRes)
+		return
+	}
+
+	to := g.ledger.Snapshot()
+
+	if rawTo := string(ctx.QueryArgs().Peek("to")); len(rawTo) > 0 {
+		if to, errRes = g.snapshotOf(rawTo); errRes != nil {
+			g.renderError(ctx, errRes)
+			return
+		}
+	}
+
+	g.render(ctx, &stateDiffResponse{
+		from:     from.Checksum(),
+		to:       to.Checksum(),
+		accounts: wavelet.DiffState(from, to),
+	})
+}
+
+func (g *Gateway) peers(ctx *fasthttp.RequestCtx) {
+	g.render(ctx, &peersResponse{client: g.client, reputation: g.ledger.Reputation(), now: time.Now()})
+}
+
 func (g *Gateway) connect(ctx *fasthttp.RequestCtx) {
 	parser := g.parserPool.Get()
 	v, err := parser.ParseBytes(ctx.PostBody())
@@ -712,9 +932,19 @@ func (g *Gateway) notFound() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+// poll streams the events of a sink over a websocket, or as server-sent events should the client
+// accept them. Sockets to a nil sink subscribe to sinks over the socket.
 func (g *Gateway) poll(sink *sink) func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
-		if err := sink.serve(ctx); err != nil {
+		if sink != nil && acceptsEventStream(ctx) {
+			if err := sink.serveSSE(ctx, g.newClientQueue()); err != nil {
+				g.renderError(ctx, ErrBadRequest(errors.Wrap(err, "failed to init event stream")))
+			}
+
+			return
+		}
+
+		if err := sink.serve(ctx, g.sink, g.newClientQueue()); err != nil {
 			g.renderError(ctx, ErrBadRequest(errors.Wrap(err, "failed to init websocket session")))
 		}
 	}
@@ -729,16 +959,20 @@ func (g *Gateway) registerWebsocketSink(rawURL string) *sink {
 	values := u.Query()
 
 	filters := make(map[string]string)
+	keys := make([]string, 0, len(values))
 
 	for key := range values {
 		filters[key] = values.Get(key)
+		keys = append(keys, values.Get(key))
 	}
 
+	sort.Strings(keys)
+
 	sink := &sink{
-		ops:     make(chan func(map[*client]struct{})),
+		name:    u.Hostname(),
+		ops:     make(chan func(map[*subscription]struct{})),
 		filters: filters,
-		join:    make(chan *client),
-		leave:   make(chan *client),
+		keys:    keys,
 	}
 
 	go sink.run()
@@ -750,6 +984,26 @@ func (g *Gateway) registerWebsocketSink(rawURL string) *sink {
 	return sink
 }
 
+// newClientQueue creates the queue of events of a websocket client, bounded as configured.
+func (g *Gateway) newClientQueue() *clientQueue {
+	var metrics *wavelet.Metrics
+	if g.ledger != nil {
+		metrics = g.ledger.Metrics()
+	}
+
+	return newClientQueue(conf.GetAPIWebsocketPolicy(), conf.GetAPIWebsocketQueueSize(), metrics)
+}
+
+// sink looks up a websocket sink by the name of its module.
+func (g *Gateway) sink(name string) (*sink, bool) {
+	g.sinksLock.RLock()
+	defer g.sinksLock.RUnlock()
+
+	sink, exists := g.sinks[name]
+
+	return sink, exists
+}
+
 func (g *Gateway) Write(buf []byte) (n int, err error) {
 	var p fastjson.Parser
 
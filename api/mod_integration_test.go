@@ -53,6 +53,7 @@ import (
 	"github.com/perlin-network/wavelet/sys"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fastjson"
 )
@@ -277,6 +278,150 @@ func TestSendTransaction(t *testing.T) {
 	}
 }
 
+func TestSendTransactionWait(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	keys, err := skademlia.NewKeys(1, 1)
+	require.NoError(t, err)
+
+	genesis := fmt.Sprintf(`{"%x": {"balance": 1000}}`, keys.PublicKey())
+
+	gateway.ledger, err = wavelet.NewLedger(
+		store.NewInmem(), skademlia.NewClient(":0", keys), wavelet.WithGenesis(&genesis),
+	)
+	require.NoError(t, err)
+
+	payload, err := wavelet.Transfer{Recipient: keys.PublicKey(), Amount: 1}.Marshal()
+	require.NoError(t, err)
+
+	tx := newTransaction(keys, sys.TagTransfer, 1, 0, payload)
+	txID := hex.EncodeToString(tx.ID[:])
+
+	reqBody := fmt.Sprintf(
+		`{"sender":"%x","nonce":%d,"block":%d,"tag":%d,"payload":"%x","signature":"%x"}`,
+		tx.Sender, tx.Nonce, tx.Block, tx.Tag, tx.Payload, tx.Signature,
+	)
+
+	send := func(query string) *http.Response {
+		request := httptest.NewRequest("POST", "http://localhost/tx/send"+query, strings.NewReader(reqBody))
+
+		w, err := serve(gateway.router, request)
+		require.NoError(t, err)
+
+		return w
+	}
+
+	t.Run("bad timeout", func(t *testing.T) {
+		queries := []string{"?wait=accepted", "?timeout=1s", "?wait=finalized&timeout=1h", "?wait=finalized&timeout=x"}
+
+		for _, query := range queries {
+			assert.Equal(t, http.StatusBadRequest, send(query).StatusCode, query)
+		}
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		w := send("?wait=finalized&timeout=10ms")
+		assert.Equal(t, http.StatusAccepted, w.StatusCode)
+
+		body, err := ioutil.ReadAll(w.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"`+txID+`","status":"pending"}`, string(body))
+	})
+
+	t.Run("finalized", func(t *testing.T) {
+		done := make(chan *http.Response, 1)
+
+		go func() {
+			request := httptest.NewRequest(
+				"POST", "http://localhost/tx/send?wait=finalized&timeout=5s", strings.NewReader(reqBody),
+			)
+
+			w, err := serve(gateway.router, request)
+			assert.NoError(t, err)
+
+			done <- w
+		}()
+
+		events := []string{
+			`{"mod":"tx","event":"applied","tx_id":"` + strings.Repeat("00", 32) + `","height":3}`,
+			`{"mod":"tx","event":"rejected","tx_id":"` + txID + `","height":7,"error":"stake too low"}`,
+		}
+
+		// The transaction is only waited for once the request is handled, so its events are
+		// written until the request returns.
+		var w *http.Response
+
+	wait:
+		for {
+			select {
+			case w = <-done:
+				break wait
+			case <-time.After(10 * time.Millisecond):
+				for _, event := range events {
+					_, err := gateway.Write([]byte(event))
+					require.NoError(t, err)
+				}
+			}
+		}
+
+		require.NotNil(t, w)
+		assert.Equal(t, http.StatusOK, w.StatusCode)
+
+		body, err := ioutil.ReadAll(w.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"`+txID+`","status":"rejected","height":7,"error":"stake too low"}`, string(body))
+	})
+}
+
+func TestSendTransactionWaitFinalized(t *testing.T) {
+	testnet, err := wavelet.NewTestNetwork()
+	require.NoError(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode()
+	require.NoError(t, err)
+
+	_, err = testnet.AddNode()
+	require.NoError(t, err)
+
+	require.NoError(t, testnet.WaitUntilSync())
+
+	tx, err := testnet.Faucet().Pay(alice, 1000)
+	require.NoError(t, err)
+
+	require.NoError(t, alice.WaitUntilBalance(1000))
+
+	gateway := New()
+	gateway.setup()
+
+	gateway.ledger = testnet.Faucet().Ledger()
+
+	reqBody := fmt.Sprintf(
+		`{"sender":"%x","nonce":%d,"block":%d,"tag":%d,"payload":"%x","signature":"%x"}`,
+		tx.Sender, tx.Nonce, tx.Block, tx.Tag, tx.Payload, tx.Signature,
+	)
+
+	// Sending a transaction which was already finalized yields its receipt rather than waiting for
+	// the transaction until timing out as pending.
+	request := httptest.NewRequest(
+		"POST", "http://localhost/tx/send?wait=finalized&timeout=5s", strings.NewReader(reqBody),
+	)
+
+	w, err := serve(gateway.router, request)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, w.StatusCode)
+
+	receipt, ok := gateway.ledger.TransactionReceipt(tx.ID)
+	require.True(t, ok)
+
+	body, err := ioutil.ReadAll(w.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, fmt.Sprintf(`{"id":"%x","status":"applied","height":%d}`, tx.ID, receipt.Height), string(body))
+}
+
 func TestSendTransactionRandom(t *testing.T) {
 	gateway := New()
 	gateway.setup()
@@ -414,6 +559,101 @@ func TestGetAccount(t *testing.T) {
 			wantCode:     http.StatusOK,
 			wantResponse: &account{ledger: gateway.ledger, id: id},
 		},
+		{
+			name:     "height not a number",
+			url:      "/accounts/" + idHex + "?height=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "height above latest block",
+			url:      "/accounts/" + idHex + "?height=100",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:         "height of latest block",
+			url:          "/accounts/" + idHex + "?height=0",
+			wantCode:     http.StatusOK,
+			wantResponse: &account{ledger: gateway.ledger, id: id},
+		},
+	}
+
+	for _, tc := range tests { // nolint:dupl
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "http://localhost"+tc.url, nil)
+
+			w, err := serve(gateway.router, request)
+			if !assert.NoError(t, err) || !assert.NotNil(t, w) {
+				return
+			}
+
+			defer func() {
+				_ = w.Body.Close()
+			}()
+
+			response, err := ioutil.ReadAll(w.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.wantCode, w.StatusCode, "status code")
+
+			if tc.wantResponse != nil {
+				r, err := tc.wantResponse.marshalJSON(new(fastjson.ArenaPool).Get())
+				assert.Nil(t, err)
+				assert.Equal(t, string(r), string(bytes.TrimSpace(response)))
+			}
+		})
+	}
+}
+
+func TestDiffState(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	gateway.ledger = createLedger(t)
+
+	root := gateway.ledger.Snapshot().Checksum()
+	rootHex := hex.EncodeToString(root[:])
+
+	empty := &stateDiffResponse{from: root, to: root, accounts: []wavelet.AccountDiff{}}
+
+	tests := []struct {
+		name         string
+		url          string
+		wantCode     int
+		wantResponse marshalableJSON
+	}{
+		{
+			name:     "missing from",
+			url:      "/ledger/diff",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "from not a height nor a merkle root",
+			url:      "/ledger/diff?from=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "from above latest block",
+			url:      "/ledger/diff?from=100",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown merkle root",
+			url:      "/ledger/diff?from=" + strings.Repeat("ab", wavelet.SizeMerkleNodeID),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "height of latest block",
+			url:          "/ledger/diff?from=0",
+			wantCode:     http.StatusOK,
+			wantResponse: empty,
+		},
+		{
+			name:         "merkle roots",
+			url:          "/ledger/diff?from=" + rootHex + "&to=" + rootHex,
+			wantCode:     http.StatusOK,
+			wantResponse: empty,
+		},
 	}
 
 	for _, tc := range tests { // nolint:dupl
@@ -621,7 +861,7 @@ func TestGetLedger(t *testing.T) {
 	publicKey := keys.PublicKey()
 
 	expectedJSON := fmt.Sprintf(
-		`{"public_key":"%s","address":"127.0.0.1:%d","num_accounts":3,"preferred_votes":0,"block":{"merkle_root":"19be72d52438349e8fa2c4705f1cd954","height":0,"id":"2d301376b242d1dec15ac1d0e5b30c41e11a4ad743f79c59bec204b0e01b36bd","transactions":0},"preferred":null,"num_missing_tx":0,"num_tx":0,"num_tx_in_store":0,"num_accounts_in_store":3,"peers":null}`,
+		`{"public_key":"%s","address":"127.0.0.1:%d","num_accounts":3,"preferred_votes":0,"block":{"merkle_root":"19be72d52438349e8fa2c4705f1cd954","height":0,"id":"2d301376b242d1dec15ac1d0e5b30c41e11a4ad743f79c59bec204b0e01b36bd","transactions":0},"preferred":null,"num_missing_tx":0,"num_tx":0,"num_tx_in_store":0,"num_accounts_in_store":3,"sync":null,"peers":null}`,
 		hex.EncodeToString(publicKey[:]),
 		listener.Addr().(*net.TCPAddr).Port,
 	)
@@ -629,6 +869,79 @@ func TestGetLedger(t *testing.T) {
 	assert.NoError(t, compareJSON([]byte(expectedJSON), response))
 }
 
+func TestListPeers(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	gateway.ledger = createLedger(t)
+
+	keys, err := skademlia.NewKeys(1, 1)
+	assert.NoError(t, err)
+	gateway.keys = keys
+
+	gateway.client = skademlia.NewClient("127.0.0.1:0", keys,
+		skademlia.WithC1(sys.SKademliaC1),
+		skademlia.WithC2(sys.SKademliaC2),
+	)
+
+	good, bad := wavelet.AccountID{1}, wavelet.AccountID{2}
+
+	reputation := gateway.ledger.Reputation()
+	reputation.Record(good, wavelet.ReputationResponded)
+	reputation.Record(bad, wavelet.ReputationInvalidBlock)
+	reputation.Record(bad, wavelet.ReputationInvalidBlock)
+
+	request := httptest.NewRequest("GET", "http://localhost/node/peers", nil)
+
+	w, err := serve(gateway.router, request)
+	if !assert.NoError(t, err) || !assert.NotNil(t, w) {
+		return
+	}
+
+	defer func() {
+		_ = w.Body.Close()
+	}()
+
+	assert.Equal(t, http.StatusOK, w.StatusCode)
+
+	var response struct {
+		Peers []struct {
+			Address     *string `json:"address"`
+			PublicKey   string  `json:"public_key"`
+			Connected   bool    `json:"connected"`
+			Score       float64 `json:"score"`
+			Failures    uint64  `json:"failures"`
+			Banned      bool    `json:"banned"`
+			BannedUntil *string `json:"banned_until"`
+		} `json:"peers"`
+	}
+
+	if !assert.NoError(t, json.NewDecoder(w.Body).Decode(&response)) || !assert.Len(t, response.Peers, 2) {
+		return
+	}
+
+	for _, peer := range response.Peers {
+		assert.Nil(t, peer.Address)
+		assert.False(t, peer.Connected)
+	}
+
+	assert.Equal(t, hex.EncodeToString(good[:]), response.Peers[0].PublicKey)
+	assert.Equal(t, 1.0, response.Peers[0].Score)
+	assert.False(t, response.Peers[0].Banned)
+	assert.Nil(t, response.Peers[0].BannedUntil)
+
+	assert.Equal(t, hex.EncodeToString(bad[:]), response.Peers[1].PublicKey)
+	assert.Equal(t, wavelet.ReputationBanThreshold, response.Peers[1].Score)
+	assert.EqualValues(t, 2, response.Peers[1].Failures)
+	assert.True(t, response.Peers[1].Banned)
+
+	if assert.NotNil(t, response.Peers[1].BannedUntil) {
+		bannedUntil, err := time.Parse(time.RFC3339, *response.Peers[1].BannedUntil)
+		assert.NoError(t, err)
+		assert.True(t, bannedUntil.After(time.Now()))
+	}
+}
+
 func TestConnectDisconnectErrors(t *testing.T) {
 	gateway := New()
 	gateway.setup()
@@ -22,6 +22,7 @@
 package api
 
 import (
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -35,6 +36,7 @@ import (
 	"github.com/perlin-network/wavelet/store"
 	"github.com/perlin-network/wavelet/sys"
 	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
 	"github.com/valyala/fastjson"
 )
 
@@ -103,6 +105,85 @@ func TestPollLog(t *testing.T) {
 	})
 }
 
+func TestPollSubscribe(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	server := &fasthttp.Server{Handler: gateway.router.Handler}
+	go func() {
+		_ = server.Serve(ln)
+	}()
+
+	defer func() {
+		_ = server.Shutdown()
+	}()
+
+	write := func(id string) {
+		_, err := gateway.Write([]byte(`{"mod":"accounts","event":"balance_updated","account_id":"` + id + `"}`))
+		assert.NoError(t, err)
+	}
+
+	u := url.URL{Scheme: "ws", Host: ln.Addr().String(), Path: "/poll"}
+	c, cleanup := tryConnectWebsocket(t, u)
+
+	send := func(msg string) {
+		assert.NoError(t, c.WriteMessage(websocket.TextMessage, []byte(msg)))
+	}
+
+	send(`{"op":"subscribe","id":"a","topic":"accounts","filters":{"id":"bb"}}`)
+	assert.Equal(t, `{"op":"subscribed","id":"a","topic":"accounts","seq":0}`, readMessage(t, c))
+
+	write("aa")
+	write("bb")
+	assert.Equal(t,
+		`{"seq":2,"mod":"accounts","event":"balance_updated","account_id":"bb"}`,
+		readMessage(t, c),
+	)
+
+	send(`{"op":"subscribe","id":"b","topic":"ledger"}`)
+	assert.Equal(t, `{"op":"error","id":"","error":"unknown topic \"ledger\""}`, readMessage(t, c))
+
+	send(`{"op":"subscribe","id":"a","topic":"accounts"}`)
+	assert.Equal(t,
+		`{"op":"error","id":"","error":"subscription with id \"a\" already exists"}`,
+		readMessage(t, c),
+	)
+
+	send(`{"op":"unsubscribe","id":"a"}`)
+	assert.Equal(t, `{"op":"unsubscribed","id":"a"}`, readMessage(t, c))
+
+	cleanup()
+
+	// Events broadcasted while disconnected are replayed once the client resumes.
+	write("bb")
+	write("aa")
+	write("bb")
+
+	u = url.URL{Scheme: "ws", Host: ln.Addr().String(), Path: "/poll/accounts", RawQuery: "id=bb&since=2"}
+	c, cleanup = tryConnectWebsocket(t, u)
+	defer cleanup()
+
+	assert.Equal(t, `{"op":"subscribed","id":"","topic":"accounts","seq":5,"resumed":true}`, readMessage(t, c))
+	assert.Contains(t, readMessage(t, c), `{"seq":3,`)
+	assert.Contains(t, readMessage(t, c), `{"seq":5,`)
+}
+
+func readMessage(t *testing.T, c *websocket.Conn) string {
+	t.Helper()
+
+	_ = c.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	_, msg, err := c.ReadMessage()
+	assert.NoError(t, err)
+
+	return string(msg)
+}
+
 func tryConnectWebsocket(t *testing.T, url url.URL) (*websocket.Conn, func()) {
 	var conn *websocket.Conn
 	var resp *http.Response
@@ -24,6 +24,7 @@ import (
 	"encoding/hex"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/perlin-network/noise/edwards25519"
 	"github.com/perlin-network/noise/skademlia"
@@ -42,6 +43,8 @@ var (
 
 	_ marshalableJSON = (*ledgerStatusResponse)(nil)
 
+	_ marshalableJSON = (*peersResponse)(nil)
+
 	_ marshalableJSON = (*transaction)(nil)
 
 	_ marshalableJSON = (*account)(nil)
@@ -189,6 +192,9 @@ type sendTransactionResponse struct {
 	// Internal fields.
 	ledger *wavelet.Ledger
 	tx     *wavelet.Transaction
+
+	// receipt is set should the client have waited for the transaction to be finalized.
+	receipt *txReceipt
 }
 
 func (s *sendTransactionResponse) marshalJSON(arena *fastjson.Arena) ([]byte, error) {
@@ -200,6 +206,18 @@ func (s *sendTransactionResponse) marshalJSON(arena *fastjson.Arena) ([]byte, er
 
 	o.Set("id", arena.NewString(hex.EncodeToString(s.tx.ID[:])))
 
+	if s.receipt != nil {
+		o.Set("status", arena.NewString(s.receipt.status))
+
+		if s.receipt.status != statusPending {
+			o.Set("height", arena.NewNumberString(strconv.FormatUint(s.receipt.height, 10)))
+		}
+
+		if len(s.receipt.err) > 0 {
+			o.Set("error", arena.NewString(s.receipt.err))
+		}
+	}
+
 	return o.MarshalTo(nil), nil
 }
 
@@ -274,6 +292,26 @@ func (s *ledgerStatusResponse) marshalJSON(arena *fastjson.Arena) ([]byte, error
 	o.Set("num_accounts_in_store",
 		arena.NewNumberString(strconv.FormatUint(accountsLen, 10)))
 
+	if progress := s.ledger.SyncProgress(); progress.Syncing() {
+		syncObj := arena.NewObject()
+		syncObj.Set("height",
+			arena.NewNumberString(strconv.FormatUint(progress.Height, 10)))
+		syncObj.Set("chunks_done",
+			arena.NewNumberInt(progress.ChunksDone))
+		syncObj.Set("chunks_total",
+			arena.NewNumberInt(progress.ChunksTotal))
+		syncObj.Set("chunks_resumed",
+			arena.NewNumberInt(progress.ChunksResumed))
+		syncObj.Set("bytes_done",
+			arena.NewNumberString(strconv.FormatUint(progress.BytesDone, 10)))
+		syncObj.Set("eta_ms",
+			arena.NewNumberString(strconv.FormatInt(int64(progress.ETA(time.Now())/time.Millisecond), 10)))
+
+		o.Set("sync", syncObj)
+	} else {
+		o.Set("sync", arena.NewNull())
+	}
+
 	peers := s.client.ClosestPeerIDs()
 	if len(peers) > 0 {
 		peersArray := arena.NewArray()
@@ -296,6 +334,69 @@ func (s *ledgerStatusResponse) marshalJSON(arena *fastjson.Arena) ([]byte, error
 	return o.MarshalTo(nil), nil
 }
 
+type peersResponse struct {
+	// Internal fields.
+
+	client     *skademlia.Client
+	reputation *wavelet.PeerReputation
+	now        time.Time
+}
+
+func (s *peersResponse) marshalJSON(arena *fastjson.Arena) ([]byte, error) {
+	if s.client == nil {
+		return nil, errors.New("insufficient parameters were provided")
+	}
+
+	peersArray := arena.NewArray()
+
+	peer := func(address *fastjson.Value, score wavelet.PeerScore) *fastjson.Value {
+		o := arena.NewObject()
+
+		o.Set("address", address)
+		o.Set("public_key", arena.NewString(hex.EncodeToString(score.PublicKey[:])))
+		o.Set("score", arena.NewNumberFloat64(score.Score))
+		o.Set("failures", arena.NewNumberString(strconv.FormatUint(score.Failures, 10)))
+
+		if score.Banned(s.now) {
+			o.Set("banned", arena.NewTrue())
+			o.Set("banned_until", arena.NewString(score.BannedUntil.Format(time.RFC3339)))
+		} else {
+			o.Set("banned", arena.NewFalse())
+			o.Set("banned_until", arena.NewNull())
+		}
+
+		return o
+	}
+
+	connected := make(map[wavelet.AccountID]struct{})
+
+	for _, id := range s.client.ClosestPeerIDs() {
+		connected[id.PublicKey()] = struct{}{}
+
+		o := peer(arena.NewString(id.Address()), s.reputation.Score(id.PublicKey()))
+		o.Set("connected", arena.NewTrue())
+
+		peersArray.SetArrayItem(len(peersArray.GetArray()), o)
+	}
+
+	// Include peers we have scored but are no longer connected to, as they may be banned.
+	for _, score := range s.reputation.Scores() {
+		if _, exists := connected[score.PublicKey]; exists {
+			continue
+		}
+
+		o := peer(arena.NewNull(), score)
+		o.Set("connected", arena.NewFalse())
+
+		peersArray.SetArrayItem(len(peersArray.GetArray()), o)
+	}
+
+	o := arena.NewObject()
+	o.Set("peers", peersArray)
+
+	return o.MarshalTo(nil), nil
+}
+
 type transaction struct {
 	// Internal fields.
 	tx     *wavelet.Transaction
@@ -386,6 +487,73 @@ func (s *account) marshalJSON(arena *fastjson.Arena) ([]byte, error) {
 	return o.MarshalTo(nil), nil
 }
 
+type stateDiffResponse struct {
+	from     wavelet.MerkleNodeID
+	to       wavelet.MerkleNodeID
+	accounts []wavelet.AccountDiff
+}
+
+func (s *stateDiffResponse) marshalJSON(arena *fastjson.Arena) ([]byte, error) {
+	o := arena.NewObject()
+
+	o.Set("from", arena.NewString(hex.EncodeToString(s.from[:])))
+	o.Set("to", arena.NewString(hex.EncodeToString(s.to[:])))
+
+	accounts := arena.NewArray()
+
+	for i, diff := range s.accounts {
+		a := arena.NewObject()
+
+		a.Set("account_id", arena.NewString(hex.EncodeToString(diff.ID[:])))
+
+		setChange := func(key string, change *wavelet.Uint64Change) {
+			if change == nil {
+				return
+			}
+
+			c := arena.NewObject()
+			c.Set("before", arena.NewNumberString(strconv.FormatUint(change.Before, 10)))
+			c.Set("after", arena.NewNumberString(strconv.FormatUint(change.After, 10)))
+
+			a.Set(key, c)
+		}
+
+		setChange("balance", diff.Balance)
+		setChange("stake", diff.Stake)
+		setChange("reward", diff.Reward)
+		setChange("gas_balance", diff.GasBalance)
+		setChange("num_pages", diff.NumPages)
+
+		if diff.Code != nil {
+			c := arena.NewObject()
+			c.Set("before", arena.NewString(hex.EncodeToString(diff.Code.Before[:])))
+			c.Set("after", arena.NewString(hex.EncodeToString(diff.Code.After[:])))
+
+			a.Set("code", c)
+		}
+
+		if len(diff.Pages) > 0 {
+			pages := arena.NewArray()
+
+			for j, page := range diff.Pages {
+				pages.SetArrayItem(j, arena.NewNumberString(strconv.FormatUint(page, 10)))
+			}
+
+			a.Set("pages", pages)
+		}
+
+		if diff.Globals {
+			a.Set("globals", arena.NewTrue())
+		}
+
+		accounts.SetArrayItem(i, a)
+	}
+
+	o.Set("accounts", accounts)
+
+	return o.MarshalTo(nil), nil
+}
+
 type errResponse struct {
 	Err            error `json:"-"` // low-level runtime error
 	HTTPStatusCode int   `json:"-"` // http response status code
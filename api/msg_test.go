@@ -24,6 +24,7 @@ package api
 import (
 	"testing"
 
+	"github.com/perlin-network/wavelet"
 	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fastjson"
 )
@@ -108,3 +109,43 @@ func TestSendTransactionRequestMissingFields(t *testing.T) {
 	`
 	assert.Error(t, req.bind(&fastjson.Parser{}, []byte(missingSignature)))
 }
+
+func TestStateDiffResponse(t *testing.T) {
+	res := &stateDiffResponse{
+		from: wavelet.MerkleNodeID{1},
+		to:   wavelet.MerkleNodeID{2},
+		accounts: []wavelet.AccountDiff{
+			{
+				ID:      wavelet.AccountID{3},
+				Balance: &wavelet.Uint64Change{Before: 100, After: 90},
+				Pages:   []uint64{0, 2},
+				Globals: true,
+			},
+		},
+	}
+
+	buf, err := res.marshalJSON(new(fastjson.ArenaPool).Get())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	v, err := fastjson.ParseBytes(buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "01000000000000000000000000000000", string(v.GetStringBytes("from")))
+	assert.Equal(t, "02000000000000000000000000000000", string(v.GetStringBytes("to")))
+
+	accounts := v.GetArray("accounts")
+	if !assert.Len(t, accounts, 1) {
+		return
+	}
+
+	assert.Equal(t, uint64(100), accounts[0].GetUint64("balance", "before"))
+	assert.Equal(t, uint64(90), accounts[0].GetUint64("balance", "after"))
+	assert.False(t, accounts[0].Exists("stake"))
+	assert.False(t, accounts[0].Exists("code"))
+	assert.Len(t, accounts[0].GetArray("pages"), 2)
+	assert.True(t, accounts[0].GetBool("globals"))
+}
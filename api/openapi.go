@@ -0,0 +1,887 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fastjson"
+)
+
+// openAPIOperation documents a route of the HTTP API. Parameters and schemas are given as JSON.
+type openAPIOperation struct {
+	summary     string
+	params      []openAPIParam
+	requestBody string
+	responses   map[int]openAPIResponse
+}
+
+type openAPIParam struct {
+	name, in    string
+	required    bool
+	schema      string
+	description string
+}
+
+// openAPIResponse is a response of a route. Responses have a JSON schema, unless they are of
+// another content type.
+type openAPIResponse struct {
+	description string
+	schema      string
+	contentType string
+}
+
+var (
+	openAPIErrBadRequest = openAPIResponse{description: "The request is invalid.", schema: "Error"}
+	openAPIErrNotFound   = openAPIResponse{description: "The resource could not be found.", schema: "Error"}
+	openAPIErrInternal   = openAPIResponse{description: "The request could not be served.", schema: "Error"}
+
+	openAPIParamHeight = openAPIParam{
+		name: "height", in: "query", schema: `{"type":"integer","minimum":0}`,
+		description: "Height of the block the state is read at. Defaults to the latest block.",
+	}
+	openAPIParamSince = openAPIParam{
+		name: "since", in: "query", schema: `{"type":"integer","minimum":0}`,
+		description: "Resume after the event with the sequence number, replaying the buffered events after it.",
+	}
+	openAPIParamLastEventID = openAPIParam{
+		name: "Last-Event-ID", in: "header", schema: `{"type":"integer","minimum":0}`,
+		description: "Resume server-sent events after the event with the id. Takes precedence over since.",
+	}
+	openAPIParamAccountID = openAPIParam{
+		name: "id", in: "path", required: true, schema: `{"$ref":"#/components/schemas/Hex"}`,
+		description: "Hex-encoded ID of the account.",
+	}
+	openAPIParamContractID = openAPIParam{
+		name: "id", in: "path", required: true, schema: `{"$ref":"#/components/schemas/Hex"}`,
+		description: "Hex-encoded ID of the transaction that spawned the contract.",
+	}
+)
+
+var openAPIWebsocket = map[int]openAPIResponse{
+	http.StatusSwitchingProtocols: {description: "The connection is upgraded to a websocket streaming events."},
+	http.StatusBadRequest:         openAPIErrBadRequest,
+}
+
+// openAPIEventStream documents routes streaming events over a websocket, or as server-sent events
+// to clients which accept text/event-stream.
+var openAPIEventStream = map[int]openAPIResponse{
+	http.StatusSwitchingProtocols: {description: "The connection is upgraded to a websocket streaming events."},
+	http.StatusOK: {
+		description: "Events streamed as server-sent events, whose ids are their sequence numbers.",
+		contentType: "text/event-stream",
+	},
+	http.StatusBadRequest: openAPIErrBadRequest,
+}
+
+// openAPIOperations documents every route registered in Gateway.setup, keyed by its method and path.
+var openAPIOperations = map[string]openAPIOperation{
+	"GET /poll": {
+		summary: "Stream events of the topics subscribed to over a websocket. Sockets subscribe to topics, " +
+			"which are the modules streamed under /poll, by sending " +
+			`{"op":"subscribe","id":"a","topic":"tx","filters":{"sender":"..."},"since":42}, ` +
+			`and unsubscribe by sending {"op":"unsubscribe","id":"a"}. Every event carries the sequence ` +
+			"number of the event within its topic. Sockets too slow to keep up are sent " +
+			`{"op":"dropped","id":"","count":3} once events are dropped for them.`,
+		responses: openAPIWebsocket,
+	},
+	"GET /poll/network": {
+		summary:   "Stream network events over a websocket, or as server-sent events.",
+		params:    []openAPIParam{openAPIParamSince, openAPIParamLastEventID},
+		responses: openAPIEventStream,
+	},
+	"GET /poll/consensus": {
+		summary:   "Stream consensus events over a websocket, or as server-sent events.",
+		params:    []openAPIParam{openAPIParamSince, openAPIParamLastEventID},
+		responses: openAPIEventStream,
+	},
+	"GET /poll/accounts": {
+		summary: "Stream account events over a websocket, or as server-sent events.",
+		params: []openAPIParam{
+			{
+				name: "id", in: "query", schema: `{"$ref":"#/components/schemas/Hex"}`,
+				description: "Only stream events of the account.",
+			},
+			openAPIParamSince,
+			openAPIParamLastEventID,
+		},
+		responses: openAPIEventStream,
+	},
+	"GET /poll/contract": {
+		summary: "Stream contract events over a websocket, or as server-sent events.",
+		params: []openAPIParam{
+			{
+				name: "id", in: "query", schema: `{"$ref":"#/components/schemas/Hex"}`,
+				description: "Only stream events of the contract.",
+			},
+			openAPIParamSince,
+			openAPIParamLastEventID,
+		},
+		responses: openAPIEventStream,
+	},
+	"GET /poll/tx": {
+		summary: "Stream transaction events over a websocket, or as server-sent events.",
+		params: []openAPIParam{
+			{
+				name: "id", in: "query", schema: `{"$ref":"#/components/schemas/Hex"}`,
+				description: "Only stream events of the transaction.",
+			},
+			{
+				name: "sender", in: "query", schema: `{"$ref":"#/components/schemas/Hex"}`,
+				description: "Only stream events of transactions sent by the account.",
+			},
+			{
+				name: "tag", in: "query", schema: `{"type":"integer"}`,
+				description: "Only stream events of transactions with the tag.",
+			},
+			openAPIParamSince,
+			openAPIParamLastEventID,
+		},
+		responses: openAPIEventStream,
+	},
+	"GET /poll/metrics": {
+		summary:   "Stream metrics of the node over a websocket, or as server-sent events.",
+		params:    []openAPIParam{openAPIParamSince, openAPIParamLastEventID},
+		responses: openAPIEventStream,
+	},
+	"GET /debug/*p": {
+		summary: "Profile the node with pprof.",
+		params: []openAPIParam{
+			{name: "p", in: "path", required: true, schema: `{"type":"string"}`, description: "Path of the pprof profile."},
+		},
+		responses: map[int]openAPIResponse{
+			http.StatusOK: {description: "The profile.", contentType: "application/octet-stream"},
+		},
+	},
+	"GET /ledger": {
+		summary: "Get the status of the ledger.",
+		responses: map[int]openAPIResponse{
+			http.StatusOK: {description: "The status of the ledger.", schema: "LedgerStatus"},
+		},
+	},
+	"GET /ledger/diff": {
+		summary: "Get the accounts that changed between two states of the ledger.",
+		params: []openAPIParam{
+			{
+				name: "from", in: "query", required: true, schema: `{"type":"string"}`,
+				description: "Height of a block, or hex-encoded merkle root of a state, to diff from.",
+			},
+			{
+				name: "to", in: "query", schema: `{"type":"string"}`,
+				description: "Height of a block, or hex-encoded merkle root of a state, to diff to. Defaults to the latest state.",
+			},
+		},
+		responses: map[int]openAPIResponse{
+			http.StatusOK:         {description: "The accounts that changed.", schema: "StateDiff"},
+			http.StatusBadRequest: openAPIErrBadRequest,
+			http.StatusNotFound:   openAPIErrNotFound,
+		},
+	},
+	"GET /accounts/:id": {
+		summary: "Get an account.",
+		params:  []openAPIParam{openAPIParamAccountID, openAPIParamHeight},
+		responses: map[int]openAPIResponse{
+			http.StatusOK:         {description: "The account.", schema: "Account"},
+			http.StatusBadRequest: openAPIErrBadRequest,
+			http.StatusNotFound:   openAPIErrNotFound,
+		},
+	},
+	"GET /contract/:id/page/:index": {
+		summary: "Get a page of the memory of a contract.",
+		params: []openAPIParam{
+			openAPIParamContractID,
+			{
+				name: "index", in: "path", required: true, schema: `{"type":"integer","minimum":0}`,
+				description: "Index of the page.",
+			},
+			openAPIParamHeight,
+		},
+		responses: map[int]openAPIResponse{
+			http.StatusOK:         {description: "The page.", contentType: "application/octet-stream"},
+			http.StatusBadRequest: openAPIErrBadRequest,
+			http.StatusNotFound:   openAPIErrNotFound,
+		},
+	},
+	"GET /contract/:id/page": {
+		summary: "Get the first page of the memory of a contract.",
+		params:  []openAPIParam{openAPIParamContractID, openAPIParamHeight},
+		responses: map[int]openAPIResponse{
+			http.StatusOK:         {description: "The page.", contentType: "application/octet-stream"},
+			http.StatusBadRequest: openAPIErrBadRequest,
+			http.StatusNotFound:   openAPIErrNotFound,
+		},
+	},
+	"GET /contract/:id": {
+		summary: "Get the code of a contract.",
+		params:  []openAPIParam{openAPIParamContractID, openAPIParamHeight},
+		responses: map[int]openAPIResponse{
+			http.StatusOK:         {description: "The WebAssembly code of the contract.", contentType: "application/wasm"},
+			http.StatusBadRequest: openAPIErrBadRequest,
+			http.StatusNotFound:   openAPIErrNotFound,
+		},
+	},
+	"POST /tx/send": {
+		summary:     "Send a signed transaction, optionally waiting for it to be finalized.",
+		requestBody: "SendTransactionRequest",
+		params: []openAPIParam{
+			{
+				name: "wait", in: "query", schema: `{"type":"string","enum":["` + waitFinalized + `"]}`,
+				description: "Wait for the transaction to be applied or rejected in a finalized block. " +
+					"The outcome of a transaction which was already finalized is returned immediately.",
+			},
+			{
+				name: "timeout", in: "query", schema: `{"type":"string","example":"30s"}`,
+				description: "How long to wait for the transaction to be finalized, such as 30s. " +
+					"Defaults to " + defaultTxWaitTimeout.String() + ", and may be at most " +
+					maxTxWaitTimeout.String() + ".",
+			},
+		},
+		responses: map[int]openAPIResponse{
+			http.StatusOK: {
+				description: "The transaction was accepted, or applied or rejected in a finalized block if waited for.",
+				schema:      "SendTransactionResponse",
+			},
+			http.StatusAccepted: {
+				description: "The transaction was accepted, but was not finalized in time.",
+				schema:      "SendTransactionResponse",
+			},
+			http.StatusBadRequest: openAPIErrBadRequest,
+		},
+	},
+	"GET /tx/:id": {
+		summary: "Get a transaction.",
+		params: []openAPIParam{
+			{
+				name: "id", in: "path", required: true, schema: `{"$ref":"#/components/schemas/Hex"}`,
+				description: "Hex-encoded ID of the transaction.",
+			},
+		},
+		responses: map[int]openAPIResponse{
+			http.StatusOK:         {description: "The transaction.", schema: "Transaction"},
+			http.StatusBadRequest: openAPIErrBadRequest,
+			http.StatusNotFound:   openAPIErrNotFound,
+		},
+	},
+	"GET /tx": {
+		summary: "List transactions.",
+		params: []openAPIParam{
+			{
+				name: "sender", in: "query", schema: `{"$ref":"#/components/schemas/Hex"}`,
+				description: "Only list transactions sent by the account.",
+			},
+			{
+				name: "offset", in: "query", schema: `{"type":"integer","minimum":0}`,
+				description: "Number of transactions to skip.",
+			},
+			{
+				name: "limit", in: "query", schema: `{"type":"integer","minimum":0}`,
+				description: "Maximum number of transactions to list.",
+			},
+		},
+		responses: map[int]openAPIResponse{
+			http.StatusOK:         {description: "The transactions.", schema: "TransactionList"},
+			http.StatusBadRequest: openAPIErrBadRequest,
+		},
+	},
+	"POST /node/connect": {
+		summary:     "Connect to a peer.",
+		requestBody: "PeerAddress",
+		responses: map[int]openAPIResponse{
+			http.StatusOK:                  {description: "The node connected to the peer.", schema: "Message"},
+			http.StatusBadRequest:          openAPIErrBadRequest,
+			http.StatusInternalServerError: openAPIErrInternal,
+		},
+	},
+	"POST /node/disconnect": {
+		summary:     "Disconnect from a peer.",
+		requestBody: "PeerAddress",
+		responses: map[int]openAPIResponse{
+			http.StatusOK:                  {description: "The node disconnected from the peer.", schema: "Message"},
+			http.StatusBadRequest:          openAPIErrBadRequest,
+			http.StatusInternalServerError: openAPIErrInternal,
+		},
+	},
+	"POST /node/restart": {
+		summary:     "Restart the node.",
+		requestBody: "Restart",
+		responses: map[int]openAPIResponse{
+			http.StatusOK:                  {description: "The node restarted."},
+			http.StatusBadRequest:          openAPIErrBadRequest,
+			http.StatusInternalServerError: openAPIErrInternal,
+		},
+	},
+	"GET /node/peers": {
+		summary: "List the peers of the node, and the reputation of every peer it has scored.",
+		responses: map[int]openAPIResponse{
+			http.StatusOK: {description: "The peers.", schema: "Peers"},
+		},
+	},
+	"POST /webhooks": {
+		summary: "Register a webhook, to which the events of a topic which pass its filters are posted. Every " +
+			"event is signed in the " + HeaderWebhookSignature + " header, and is retried with backoff until " +
+			"it is delivered or kept as a dead letter. Events dropped for webhooks too slow to keep up with " +
+			"them are kept as dead letters as well.",
+		requestBody: "WebhookRequest",
+		responses: map[int]openAPIResponse{
+			http.StatusOK:                  {description: "The webhook, with its secret.", schema: "Webhook"},
+			http.StatusBadRequest:          openAPIErrBadRequest,
+			http.StatusInternalServerError: openAPIErrInternal,
+		},
+	},
+	"GET /webhooks": {
+		summary: "List the webhooks registered.",
+		responses: map[int]openAPIResponse{
+			http.StatusOK: {description: "The webhooks, without their secrets.", schema: "WebhookList"},
+		},
+	},
+	"GET /webhooks/dead": {
+		summary: "List the latest events which failed to be delivered to webhooks, or which were dropped for " +
+			"them. Dropped events were never attempted to be delivered.",
+		responses: map[int]openAPIResponse{
+			http.StatusOK: {description: "The events which failed to be delivered.", schema: "DeadLetterList"},
+		},
+	},
+	"DELETE /webhooks/:id": {
+		summary: "Delete a webhook.",
+		params: []openAPIParam{
+			{name: "id", in: "path", required: true, schema: `{"type":"string"}`, description: "ID of the webhook."},
+		},
+		responses: map[int]openAPIResponse{
+			http.StatusOK:                  {description: "The webhook was deleted.", schema: "Message"},
+			http.StatusNotFound:            openAPIErrNotFound,
+			http.StatusInternalServerError: openAPIErrInternal,
+		},
+	},
+	"POST /rpc": {
+		summary:     "Make JSON-RPC 2.0 calls, which may be batched, to the methods mirroring the routes above.",
+		requestBody: "RPCRequest",
+		responses: map[int]openAPIResponse{
+			http.StatusOK:        {description: "The responses to the calls.", schema: "RPCResponse"},
+			http.StatusNoContent: {description: "Every call was a notification."},
+		},
+	},
+	"GET /openapi.json": {
+		summary: "Get this document.",
+		responses: map[int]openAPIResponse{
+			http.StatusOK: {description: "The OpenAPI document of the API.", schema: "Object"},
+		},
+	},
+}
+
+// openAPISchemas are the schemas of the request and response bodies of the HTTP API, which are
+// built in msg.go.
+var openAPISchemas = map[string]string{
+	"Hex":    `{"type":"string","pattern":"^[0-9a-fA-F]*$"}`,
+	"Object": `{"type":"object","additionalProperties":true}`,
+	"Error": `{
+		"type":"object",
+		"required":["status"],
+		"properties":{
+			"status":{"type":"string","description":"Status text of the HTTP status code."},
+			"error":{"type":"string"}
+		}
+	}`,
+	"Message": `{
+		"type":"object",
+		"required":["msg"],
+		"properties":{"msg":{"type":"string"}}
+	}`,
+	"Block": `{
+		"type":"object",
+		"required":["merkle_root","height","id","transactions"],
+		"properties":{
+			"merkle_root":{"$ref":"#/components/schemas/Hex"},
+			"height":{"type":"integer"},
+			"id":{"$ref":"#/components/schemas/Hex"},
+			"transactions":{"type":"integer"}
+		}
+	}`,
+	"SyncProgress": `{
+		"type":"object",
+		"required":["height","chunks_done","chunks_total","chunks_resumed","bytes_done","eta_ms"],
+		"properties":{
+			"height":{"type":"integer"},
+			"chunks_done":{"type":"integer"},
+			"chunks_total":{"type":"integer"},
+			"chunks_resumed":{"type":"integer"},
+			"bytes_done":{"type":"integer"},
+			"eta_ms":{"type":"integer"}
+		}
+	}`,
+	"LedgerStatus": `{
+		"type":"object",
+		"required":[
+			"public_key","address","num_accounts","preferred_votes","block","preferred",
+			"num_missing_tx","num_tx","num_tx_in_store","num_accounts_in_store","sync","peers"
+		],
+		"properties":{
+			"public_key":{"$ref":"#/components/schemas/Hex"},
+			"address":{"type":"string"},
+			"num_accounts":{"type":"integer"},
+			"preferred_votes":{"type":"integer"},
+			"block":{"$ref":"#/components/schemas/Block"},
+			"preferred":{"allOf":[{"$ref":"#/components/schemas/Block"}],"nullable":true},
+			"num_missing_tx":{"type":"integer"},
+			"num_tx":{"type":"integer"},
+			"num_tx_in_store":{"type":"integer"},
+			"num_accounts_in_store":{"type":"integer"},
+			"sync":{"allOf":[{"$ref":"#/components/schemas/SyncProgress"}],"nullable":true},
+			"peers":{
+				"type":"array",
+				"nullable":true,
+				"items":{
+					"type":"object",
+					"required":["address","public_key"],
+					"properties":{
+						"address":{"type":"string"},
+						"public_key":{"$ref":"#/components/schemas/Hex"}
+					}
+				}
+			}
+		}
+	}`,
+	"Peers": `{
+		"type":"object",
+		"required":["peers"],
+		"properties":{
+			"peers":{
+				"type":"array",
+				"items":{
+					"type":"object",
+					"required":["address","public_key","score","failures","banned","banned_until","connected"],
+					"properties":{
+						"address":{"type":"string","nullable":true},
+						"public_key":{"$ref":"#/components/schemas/Hex"},
+						"score":{"type":"number"},
+						"failures":{"type":"integer"},
+						"banned":{"type":"boolean"},
+						"banned_until":{"type":"string","format":"date-time","nullable":true},
+						"connected":{"type":"boolean"}
+					}
+				}
+			}
+		}
+	}`,
+	"Account": `{
+		"type":"object",
+		"required":["public_key","balance","gas_balance","stake","reward","is_contract"],
+		"properties":{
+			"public_key":{"$ref":"#/components/schemas/Hex"},
+			"balance":{"type":"integer"},
+			"gas_balance":{"type":"integer"},
+			"stake":{"type":"integer"},
+			"reward":{"type":"integer"},
+			"is_contract":{"type":"boolean"},
+			"num_mem_pages":{"type":"integer","description":"Omitted if the account is not a contract."}
+		}
+	}`,
+	"Transaction": `{
+		"type":"object",
+		"required":["id","sender","status","nonce","height","tag","payload","signature"],
+		"properties":{
+			"id":{"$ref":"#/components/schemas/Hex"},
+			"sender":{"$ref":"#/components/schemas/Hex"},
+			"status":{"type":"string","enum":["` + statusApplied + `","` + statusReceived + `"]},
+			"nonce":{"type":"integer"},
+			"height":{"type":"integer"},
+			"tag":{"type":"integer"},
+			"payload":{"type":"string","format":"byte"},
+			"signature":{"$ref":"#/components/schemas/Hex"}
+		}
+	}`,
+	"TransactionList": `{
+		"type":"array",
+		"items":{"$ref":"#/components/schemas/Transaction"}
+	}`,
+	"SendTransactionRequest": `{
+		"type":"object",
+		"required":["sender","nonce","block","tag","payload","signature"],
+		"properties":{
+			"sender":{"$ref":"#/components/schemas/Hex"},
+			"nonce":{"type":"integer"},
+			"block":{"type":"integer"},
+			"tag":{"type":"integer"},
+			"payload":{"$ref":"#/components/schemas/Hex"},
+			"signature":{"$ref":"#/components/schemas/Hex"}
+		}
+	}`,
+	"SendTransactionResponse": `{
+		"type":"object",
+		"required":["id"],
+		"properties":{
+			"id":{"$ref":"#/components/schemas/Hex"},
+			"status":{"type":"string","enum":["` + statusApplied + `","` + statusRejected + `","` + statusPending + `"]},
+			"height":{"type":"integer","description":"Height of the finalized block."},
+			"error":{"type":"string","description":"Why the transaction was rejected."}
+		}
+	}`,
+	"Uint64Change": `{
+		"type":"object",
+		"required":["before","after"],
+		"properties":{"before":{"type":"integer"},"after":{"type":"integer"}}
+	}`,
+	"StateDiff": `{
+		"type":"object",
+		"required":["from","to","accounts"],
+		"properties":{
+			"from":{"$ref":"#/components/schemas/Hex"},
+			"to":{"$ref":"#/components/schemas/Hex"},
+			"accounts":{
+				"type":"array",
+				"items":{
+					"type":"object",
+					"required":["account_id"],
+					"properties":{
+						"account_id":{"$ref":"#/components/schemas/Hex"},
+						"balance":{"$ref":"#/components/schemas/Uint64Change"},
+						"stake":{"$ref":"#/components/schemas/Uint64Change"},
+						"reward":{"$ref":"#/components/schemas/Uint64Change"},
+						"gas_balance":{"$ref":"#/components/schemas/Uint64Change"},
+						"num_pages":{"$ref":"#/components/schemas/Uint64Change"},
+						"code":{
+							"type":"object",
+							"required":["before","after"],
+							"properties":{
+								"before":{"$ref":"#/components/schemas/Hex"},
+								"after":{"$ref":"#/components/schemas/Hex"}
+							}
+						},
+						"pages":{"type":"array","items":{"type":"integer"}},
+						"globals":{"type":"boolean"}
+					}
+				}
+			}
+		}
+	}`,
+	"PeerAddress": `{
+		"type":"object",
+		"required":["address"],
+		"properties":{"address":{"type":"string"}}
+	}`,
+	"WebhookRequest": `{
+		"type":"object",
+		"required":["url","topic"],
+		"properties":{
+			"url":{"type":"string","description":"Absolute http or https URL events are posted to."},
+			"topic":{"type":"string","description":"Module whose events are posted, as streamed under /poll."},
+			"filters":{
+				"type":"object",
+				"additionalProperties":{"type":"string"},
+				"description":"Filters of the events posted, keyed by the query parameters of the topic under /poll."
+			},
+			"event":{"type":"string","description":"Only post events of the type."},
+			"secret":{"type":"string","description":"Key of the signatures of events. Generated if missing."}
+		}
+	}`,
+	"Webhook": `{
+		"type":"object",
+		"required":["id","url","topic","filters"],
+		"properties":{
+			"id":{"type":"string"},
+			"url":{"type":"string"},
+			"topic":{"type":"string"},
+			"filters":{"type":"object","additionalProperties":{"type":"string"}},
+			"event":{"type":"string"},
+			"secret":{"type":"string"}
+		}
+	}`,
+	"WebhookList": `{
+		"type":"array",
+		"items":{"$ref":"#/components/schemas/Webhook"}
+	}`,
+	"DeadLetter": `{
+		"type":"object",
+		"required":["webhook_id","url","event","attempts","error","failed_at"],
+		"properties":{
+			"webhook_id":{"type":"string"},
+			"url":{"type":"string"},
+			"event":{"type":"object","additionalProperties":true},
+			"attempts":{"type":"integer"},
+			"error":{"type":"string"},
+			"failed_at":{"type":"string","format":"date-time"}
+		}
+	}`,
+	"DeadLetterList": `{
+		"type":"array",
+		"items":{"$ref":"#/components/schemas/DeadLetter"}
+	}`,
+	"Restart": `{
+		"type":"object",
+		"properties":{"hard":{"type":"boolean","description":"Delete the database of the node before restarting."}}
+	}`,
+	"RPCCall": `{
+		"type":"object",
+		"required":["jsonrpc","method"],
+		"properties":{
+			"jsonrpc":{"type":"string","enum":["2.0"]},
+			"method":{"type":"string"},
+			"params":{"type":"object","additionalProperties":true},
+			"id":{"nullable":true}
+		}
+	}`,
+	"RPCRequest": `{
+		"oneOf":[
+			{"$ref":"#/components/schemas/RPCCall"},
+			{"type":"array","items":{"$ref":"#/components/schemas/RPCCall"}}
+		]
+	}`,
+	"RPCResult": `{
+		"type":"object",
+		"required":["jsonrpc","id"],
+		"properties":{
+			"jsonrpc":{"type":"string","enum":["2.0"]},
+			"result":{"nullable":true},
+			"error":{
+				"type":"object",
+				"required":["code","message"],
+				"properties":{
+					"code":{"type":"integer"},
+					"message":{"type":"string"},
+					"data":{
+						"type":"object",
+						"required":["status"],
+						"properties":{"status":{"type":"integer"}}
+					}
+				}
+			},
+			"id":{"nullable":true}
+		}
+	}`,
+	"RPCResponse": `{
+		"oneOf":[
+			{"$ref":"#/components/schemas/RPCResult"},
+			{"type":"array","items":{"$ref":"#/components/schemas/RPCResult"}}
+		]
+	}`,
+}
+
+// openAPIPath converts the path of a route into an OpenAPI path template.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// openAPI renders the OpenAPI 3 document of the routes registered on the gateway.
+func (g *Gateway) openAPI(ctx *fasthttp.RequestCtx) {
+	arena := g.arenaPool.Get()
+	defer func() {
+		arena.Reset()
+		g.arenaPool.Put(arena)
+	}()
+
+	ctx.SetContentType("application/json")
+	ctx.Response.SetBody(g.openAPIDocument(arena).MarshalTo(nil))
+}
+
+func (g *Gateway) openAPIDocument(arena *fastjson.Arena) *fastjson.Value {
+	doc := arena.NewObject()
+	doc.Set("openapi", arena.NewString("3.0.3"))
+
+	info := arena.NewObject()
+	info.Set("title", arena.NewString("Wavelet HTTP API"))
+	info.Set("version", arena.NewString(sys.Version))
+	doc.Set("info", info)
+
+	paths := arena.NewObject()
+
+	for _, route := range g.routes {
+		path := openAPIPath(route.path)
+
+		item := paths.Get(path)
+		if item == nil {
+			item = arena.NewObject()
+			paths.Set(path, item)
+		}
+
+		item.Set(strings.ToLower(route.method), openAPIOperationOf(arena, route))
+	}
+
+	doc.Set("paths", paths)
+
+	schemas := arena.NewObject()
+
+	names := make([]string, 0, len(openAPISchemas))
+	for name := range openAPISchemas {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		schemas.Set(name, fastjson.MustParse(openAPISchemas[name]))
+	}
+
+	bearer := arena.NewObject()
+	bearer.Set("type", arena.NewString("http"))
+	bearer.Set("scheme", arena.NewString("bearer"))
+
+	securitySchemes := arena.NewObject()
+	securitySchemes.Set("bearer", bearer)
+
+	components := arena.NewObject()
+	components.Set("schemas", schemas)
+	components.Set("securitySchemes", securitySchemes)
+	doc.Set("components", components)
+
+	return doc
+}
+
+// openAPIOperationOf documents a route. Routes which are authorized may also respond with 401 and
+// 403, and routes which are rate limited may also respond with 429.
+func openAPIOperationOf(arena *fastjson.Arena, route route) *fastjson.Value {
+	op := openAPIOperations[route.method+" "+route.path]
+
+	o := arena.NewObject()
+
+	if len(op.summary) > 0 {
+		o.Set("summary", arena.NewString(op.summary))
+	}
+
+	if len(op.params) > 0 {
+		params := arena.NewArray()
+
+		for i, param := range op.params {
+			p := arena.NewObject()
+			p.Set("name", arena.NewString(param.name))
+			p.Set("in", arena.NewString(param.in))
+			p.Set("required", openAPIBool(arena, param.required))
+			p.Set("description", arena.NewString(param.description))
+			p.Set("schema", fastjson.MustParse(param.schema))
+
+			params.SetArrayItem(i, p)
+		}
+
+		o.Set("parameters", params)
+	}
+
+	if len(op.requestBody) > 0 {
+		body := arena.NewObject()
+		body.Set("required", arena.NewTrue())
+		body.Set("content", openAPIContent(arena, openAPIResponse{schema: op.requestBody}))
+
+		o.Set("requestBody", body)
+	}
+
+	responses := arena.NewObject()
+
+	codes := make([]int, 0, len(op.responses))
+	for code := range op.responses {
+		codes = append(codes, code)
+	}
+
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		responses.Set(strconv.Itoa(code), openAPIResponseOf(arena, op.responses[code]))
+	}
+
+	plain := func(description string) *fastjson.Value {
+		return openAPIResponseOf(arena, openAPIResponse{description: description, contentType: "text/plain"})
+	}
+
+	if len(route.scope) > 0 {
+		responses.Set(strconv.Itoa(http.StatusUnauthorized), plain("The request bears no API key or an unknown one."))
+		responses.Set(strconv.Itoa(http.StatusForbidden), plain("The API key of the request is not granted the scope."))
+
+		bearer := arena.NewObject()
+		bearer.Set("bearer", arena.NewArray())
+
+		security := arena.NewArray()
+		security.SetArrayItem(0, bearer)
+
+		if conf.IsAPIPublicScope(route.scope) {
+			security.SetArrayItem(1, arena.NewObject())
+		}
+
+		o.Set("security", security)
+		o.Set("x-scope", arena.NewString(route.scope))
+	}
+
+	if len(route.rateLimitGroup) > 0 {
+		responses.Set(strconv.Itoa(http.StatusTooManyRequests), plain("The client is rate limited."))
+
+		o.Set("x-rate-limit-group", arena.NewString(route.rateLimitGroup))
+	}
+
+	if len(op.responses) == 0 {
+		responses.Set("default", plain("Undocumented."))
+	}
+
+	o.Set("responses", responses)
+
+	return o
+}
+
+func openAPIResponseOf(arena *fastjson.Arena, res openAPIResponse) *fastjson.Value {
+	o := arena.NewObject()
+	o.Set("description", arena.NewString(res.description))
+
+	if len(res.schema) > 0 || len(res.contentType) > 0 {
+		o.Set("content", openAPIContent(arena, res))
+	}
+
+	return o
+}
+
+func openAPIContent(arena *fastjson.Arena, res openAPIResponse) *fastjson.Value {
+	media := arena.NewObject()
+	contentType := "application/json"
+
+	if len(res.contentType) > 0 {
+		contentType = res.contentType
+
+		schema := arena.NewObject()
+		schema.Set("type", arena.NewString("string"))
+
+		if contentType != "text/plain" {
+			schema.Set("format", arena.NewString("binary"))
+		}
+
+		media.Set("schema", schema)
+	} else {
+		media.Set("schema", fastjson.MustParse(`{"$ref":"#/components/schemas/`+res.schema+`"}`))
+	}
+
+	content := arena.NewObject()
+	content.Set(contentType, media)
+
+	return content
+}
+
+func openAPIBool(arena *fastjson.Arena, b bool) *fastjson.Value {
+	if b {
+		return arena.NewTrue()
+	}
+
+	return arena.NewFalse()
+}
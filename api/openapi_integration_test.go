@@ -0,0 +1,445 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/perlin-network/noise/skademlia"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getOpenAPI(t *testing.T, gateway *Gateway) map[string]interface{} {
+	t.Helper()
+
+	request, err := http.NewRequest("GET", "http://localhost/openapi.json", nil)
+	require.NoError(t, err)
+
+	w, err := serve(gateway.router, request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.StatusCode)
+
+	var doc map[string]interface{}
+
+	decoder := json.NewDecoder(w.Body)
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&doc))
+
+	return doc
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	routes := make(map[string]struct{})
+
+	for _, route := range gateway.routes {
+		key := route.method + " " + route.path
+		routes[key] = struct{}{}
+
+		op, documented := openAPIOperations[key]
+		if assert.True(t, documented, "route %s is not documented", key) {
+			assert.NotEmpty(t, op.summary, key)
+			assert.NotEmpty(t, op.responses, key)
+		}
+	}
+
+	for key := range openAPIOperations {
+		_, exists := routes[key]
+		assert.True(t, exists, "documented route %s is not registered", key)
+	}
+
+	doc := getOpenAPI(t, gateway)
+
+	// Every reference to a schema must resolve.
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				_, err := resolveRef(doc, ref)
+				assert.NoError(t, err)
+			}
+
+			for _, item := range v {
+				walk(item)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+
+	walk(doc)
+
+	// Every path parameter must be documented.
+	for path, item := range doc["paths"].(map[string]interface{}) {
+		for method, op := range item.(map[string]interface{}) {
+			params := make(map[string]struct{})
+
+			if list, ok := op.(map[string]interface{})["parameters"].([]interface{}); ok {
+				for _, param := range list {
+					param := param.(map[string]interface{})
+					if param["in"] == "path" {
+						params[param["name"].(string)] = struct{}{}
+					}
+				}
+			}
+
+			for _, segment := range strings.Split(path, "/") {
+				if strings.HasPrefix(segment, "{") {
+					_, documented := params[strings.Trim(segment, "{}")]
+					assert.True(t, documented, "path parameter %s of %s %s is not documented", segment, method, path)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPIHandlers(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	keys, err := skademlia.NewKeys(1, 1)
+	require.NoError(t, err)
+
+	gateway.ledger = createLedger(t)
+	gateway.client = skademlia.NewClient(":0", keys)
+	gateway.keys = keys
+	gateway.kv = store.NewInmem()
+
+	defer gateway.stopWebhooks()
+
+	tx := newTransaction(keys, sys.TagTransfer, 1, 0, []byte("payload"))
+	gateway.ledger.AddTransaction(tx)
+
+	publicKey := keys.PublicKey()
+	accountID := hex.EncodeToString(publicKey[:])
+	txID := hex.EncodeToString(tx.ID[:])
+	zeroID := strings.Repeat("00", 32)
+
+	sendTx := fmt.Sprintf(
+		`{"sender":"%x","nonce":%d,"block":%d,"tag":%d,"payload":"%x","signature":"%x"}`,
+		tx.Sender, tx.Nonce, tx.Block, tx.Tag, tx.Payload, tx.Signature,
+	)
+
+	requests := []struct {
+		method, url, body string
+	}{
+		{"GET", "/poll", ""},
+		{"GET", "/poll/network", ""},
+		{"GET", "/poll/network?since=-1", ""},
+		{"GET", "/poll/consensus", ""},
+		{"GET", "/poll/accounts?id=" + accountID, ""},
+		{"GET", "/poll/contract?id=" + zeroID, ""},
+		{"GET", "/poll/tx?sender=" + accountID, ""},
+		{"GET", "/poll/metrics", ""},
+		{"GET", "/debug/pprof/cmdline", ""},
+		{"GET", "/ledger", ""},
+		{"GET", "/ledger/diff", ""},
+		{"GET", "/ledger/diff?from=0", ""},
+		{"GET", "/ledger/diff?from=" + zeroID[:32], ""},
+		{"GET", "/accounts/" + accountID, ""},
+		{"GET", "/accounts/" + accountID + "?height=0", ""},
+		{"GET", "/accounts/" + accountID + "?height=5", ""},
+		{"GET", "/accounts/zz", ""},
+		{"GET", "/contract/" + zeroID, ""},
+		{"GET", "/contract/zz", ""},
+		{"GET", "/contract/" + zeroID + "/page", ""},
+		{"GET", "/contract/" + zeroID + "/page/0", ""},
+		{"POST", "/tx/send", sendTx},
+		{"POST", "/tx/send", "{}"},
+		{"POST", "/tx/send?wait=accepted", sendTx},
+		{"GET", "/tx/" + txID, ""},
+		{"GET", "/tx/" + zeroID, ""},
+		{"GET", "/tx", ""},
+		{"GET", "/tx?sender=zz", ""},
+		{"POST", "/node/connect", "{}"},
+		{"POST", "/node/disconnect", "{}"},
+		{"GET", "/node/peers", ""},
+		{"POST", "/webhooks", `{"url":"http://127.0.0.1:1/hook","topic":"accounts","filters":{"id":"` + accountID + `"}}`},
+		{"POST", "/webhooks", "{}"},
+		{"GET", "/webhooks", ""},
+		{"GET", "/webhooks/dead", ""},
+		{"DELETE", "/webhooks/" + zeroID, ""},
+		{"POST", "/rpc", `{"jsonrpc":"2.0","method":"getAccount","params":{"id":"` + accountID + `"},"id":1}`},
+		{"POST", "/rpc", `[{"jsonrpc":"2.0","method":"getTransaction","params":{"id":"` + zeroID + `"},"id":1}]`},
+		{"POST", "/rpc", `{"jsonrpc":"2.0","method":"ledgerStatus"}`},
+		{"GET", "/openapi.json", ""},
+	}
+
+	// Restarting the node is not exercised, as it would tear down the ledger.
+	exercised := map[string]struct{}{
+		"POST /node/restart": {},
+	}
+
+	doc := getOpenAPI(t, gateway)
+
+	for _, req := range requests {
+		req := req
+
+		t.Run(req.method+" "+req.url, func(t *testing.T) {
+			request, err := http.NewRequest(req.method, "http://localhost"+req.url, bytes.NewBufferString(req.body))
+			require.NoError(t, err)
+
+			w, err := serve(gateway.router, request)
+			require.NoError(t, err)
+
+			body, err := ioutil.ReadAll(w.Body)
+			require.NoError(t, err)
+
+			key, op := findOperation(t, gateway, doc, req.method, strings.Split(req.url, "?")[0])
+			exercised[key] = struct{}{}
+
+			responses := op["responses"].(map[string]interface{})
+
+			res, documented := responses[fmt.Sprint(w.StatusCode)].(map[string]interface{})
+			require.True(t, documented, "status %d is not documented: %s", w.StatusCode, body)
+
+			content, _ := res["content"].(map[string]interface{})
+			media, isJSON := content["application/json"].(map[string]interface{})
+
+			if !isJSON {
+				return
+			}
+
+			require.True(t, strings.HasPrefix(w.Header.Get("Content-Type"), "application/json"), string(body))
+
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+
+			var v interface{}
+			require.NoError(t, decoder.Decode(&v))
+
+			assert.NoError(t, validateSchema(doc, media["schema"].(map[string]interface{}), v, "$"), string(body))
+		})
+	}
+
+	var missing []string
+
+	for key := range openAPIOperations {
+		if _, ok := exercised[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	sort.Strings(missing)
+	assert.Empty(t, missing, "documented routes are not exercised")
+}
+
+// findOperation finds the route a request was routed to, and its operation in the OpenAPI document.
+func findOperation(
+	t *testing.T, gateway *Gateway, doc map[string]interface{}, method, path string,
+) (string, map[string]interface{}) {
+	t.Helper()
+
+	for _, route := range gateway.routes {
+		if route.method != method || !matchPath(route.path, path) {
+			continue
+		}
+
+		item := doc["paths"].(map[string]interface{})[openAPIPath(route.path)].(map[string]interface{})
+
+		return route.method + " " + route.path, item[strings.ToLower(method)].(map[string]interface{})
+	}
+
+	t.Fatalf("no route for %s %s", method, path)
+
+	return "", nil
+}
+
+func matchPath(pattern, path string) bool {
+	patterns := strings.Split(pattern, "/")
+	segments := strings.Split(path, "/")
+
+	for i, p := range patterns {
+		if strings.HasPrefix(p, "*") {
+			return i < len(segments)
+		}
+
+		if i >= len(segments) || (!strings.HasPrefix(p, ":") && p != segments[i]) {
+			return false
+		}
+	}
+
+	return len(patterns) == len(segments)
+}
+
+func resolveRef(doc map[string]interface{}, ref string) (map[string]interface{}, error) {
+	const prefix = "#/components/schemas/"
+
+	if !strings.HasPrefix(ref, prefix) {
+		return nil, errors.Errorf("unsupported reference %q", ref)
+	}
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	schema, ok := schemas[ref[len(prefix):]].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("unknown schema %q", ref)
+	}
+
+	return schema, nil
+}
+
+// validateSchema validates a value against the subset of JSON schema the OpenAPI document uses.
+// Objects may only have the properties their schema documents, unless additionalProperties is set.
+func validateSchema(doc, schema map[string]interface{}, v interface{}, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := resolveRef(doc, ref)
+		if err != nil {
+			return err
+		}
+
+		return validateSchema(doc, resolved, v, at)
+	}
+
+	if v == nil {
+		if schema["nullable"] == true || (schema["type"] == nil && schema["allOf"] == nil) {
+			return nil
+		}
+
+		return errors.Errorf("%s: must not be null", at)
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if err := validateSchema(doc, sub.(map[string]interface{}), v, at); err != nil {
+				return err
+			}
+		}
+	}
+
+	if one, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+
+		for _, sub := range one {
+			if validateSchema(doc, sub.(map[string]interface{}), v, at) == nil {
+				matches++
+			}
+		}
+
+		if matches != 1 {
+			return errors.Errorf("%s: must match exactly one schema, but matches %d", at, matches)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("%s: must be an object", at)
+		}
+
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, key := range required {
+				if _, exists := obj[key.(string)]; !exists {
+					return errors.Errorf("%s: missing property %q", at, key)
+				}
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+
+		for key, value := range obj {
+			property, documented := properties[key].(map[string]interface{})
+			if !documented {
+				if schema["additionalProperties"] == true {
+					continue
+				}
+
+				if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+					if err := validateSchema(doc, additional, value, at+"."+key); err != nil {
+						return err
+					}
+
+					continue
+				}
+
+				return errors.Errorf("%s: undocumented property %q", at, key)
+			}
+
+			if err := validateSchema(doc, property, value, at+"."+key); err != nil {
+				return err
+			}
+		}
+	case "array":
+		list, ok := v.([]interface{})
+		if !ok {
+			return errors.Errorf("%s: must be an array", at)
+		}
+
+		items, _ := schema["items"].(map[string]interface{})
+
+		for i, item := range list {
+			if err := validateSchema(doc, items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return errors.Errorf("%s: must be a string", at)
+		}
+
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			found := false
+
+			for _, e := range enum {
+				found = found || e == s
+			}
+
+			if !found {
+				return errors.Errorf("%s: %q is not one of %v", at, s, enum)
+			}
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok || strings.ContainsAny(n.String(), ".eE") {
+			return errors.Errorf("%s: must be an integer", at)
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return errors.Errorf("%s: must be a number", at)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return errors.Errorf("%s: must be a boolean", at)
+		}
+	}
+
+	return nil
+}
@@ -21,14 +21,32 @@ package api
 
 import (
 	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/rcrowley/go-metrics"
 	"github.com/valyala/fasthttp"
 	"golang.org/x/time/rate"
 )
 
+// Groups of routes which are rate limited together. The rate limit of each group is configured
+// through conf.WithAPIRateLimit.
+const (
+	rateLimitPoll     = "poll"
+	rateLimitDebug    = "debug"
+	rateLimitLedger   = "ledger"
+	rateLimitAccounts = "accounts"
+	rateLimitContract = "contract"
+	rateLimitTx       = "tx"
+	rateLimitTxSend   = "tx.send"
+	rateLimitNode     = "node"
+	rateLimitWebhooks = "webhooks"
+)
+
 type limiter struct {
 	key      string
 	limiter  *rate.Limiter
@@ -36,27 +54,29 @@ type limiter struct {
 }
 
 type rateLimiter struct {
-	// Rate per second
-	max float64
-
 	// Determine how long (since lastSeen) should the limiter be kept in the map.
 	expirationTTL time.Duration
 
+	// Rate of requests rejected for exceeding their rate limit.
+	throttled metrics.Meter
+
 	limiters map[string]*limiter
 	sync.RWMutex
 }
 
-func newRateLimiter(maxPerSec float64) *rateLimiter {
+func newRateLimiter() *rateLimiter {
 	return &rateLimiter{
-		max:           maxPerSec,
-		expirationTTL: 1 * time.Second,
+		expirationTTL: 10 * time.Minute,
+		throttled:     metrics.GetOrRegisterMeter("api.throttled", metrics.DefaultRegistry),
 		limiters:      make(map[string]*limiter),
 	}
 }
 
 // Return the rate limiter for the key if it
 // already exists. Otherwise, create a new one.
-func (r *rateLimiter) getLimiter(key string) *limiter {
+// The limit and burst of an existing limiter are
+// updated should they have been reconfigured.
+func (r *rateLimiter) getLimiter(key string, limit rate.Limit, burst int) *limiter {
 	r.Lock()
 	defer r.Unlock()
 
@@ -65,10 +85,18 @@ func (r *rateLimiter) getLimiter(key string) *limiter {
 		// Update the last seen time for the limiter.
 		v.lastSeen = time.Now().UnixNano()
 
+		if v.limiter.Limit() != limit {
+			v.limiter.SetLimit(limit)
+		}
+
+		if v.limiter.Burst() != burst {
+			v.limiter.SetBurst(burst)
+		}
+
 		return v
 	}
 
-	l := rate.NewLimiter(rate.Limit(r.max), int(math.Max(1, r.max)))
+	l := rate.NewLimiter(limit, burst)
 	v = &limiter{key, l, time.Now().UnixNano()}
 
 	r.limiters[key] = v
@@ -112,21 +140,91 @@ func (r *rateLimiter) cleanup(interval time.Duration) func() {
 	return stop
 }
 
-// Apply rate limiting by key and IP
-func (r *rateLimiter) limit(key string) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+// rateLimitClient returns the key identifying the client making a request, and the multiplier of
+// the rate limits of its tier. Clients bearing a token of a known tier or an API key are identified
+// by their token, and all other clients are identified by their IP address. The tier of a token
+// takes precedence over the multiplier of its API key.
+func rateLimitClient(token string, ip net.IP) (string, float64) {
+	if len(token) > 0 {
+		if multiplier, ok := conf.GetAPIRateLimitTier(token); ok {
+			return "token:" + token, multiplier
+		}
+
+		if key, ok := apiKey(token); ok {
+			if key.RateLimitMultiplier > 0 {
+				return "token:" + token, key.RateLimitMultiplier
+			}
+
+			return "token:" + token, 1
+		}
+	}
+
+	return "ip:" + ip.String(), 1
+}
+
+// reserve takes a request off the quota of a client for a group of routes. Should the client
+// have exhausted its quota, no request is taken off and the delay until the client may make a
+// request is returned instead. A nil limiter is returned should the group not be rate limited.
+func (r *rateLimiter) reserve(group string, token string, ip net.IP, now time.Time) (*rate.Limiter, time.Duration) {
+	cfg := conf.GetAPIRateLimit(group)
+	if cfg.PerSecond <= 0 {
+		return nil, 0
+	}
+
+	client, multiplier := rateLimitClient(token, ip)
+
+	limit := rate.Limit(cfg.PerSecond * multiplier)
+	burst := int(math.Max(1, float64(cfg.Burst)*multiplier))
+
+	l := r.getLimiter(group+" "+client, limit, burst)
+
+	reservation := l.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		r.throttled.Mark(1)
+
+		return l.limiter, delay
+	}
+
+	return l.limiter, 0
+}
+
+// Apply rate limiting to a group of routes per client. The remaining
+// quota of the client is reported through X-RateLimit-* headers.
+func (r *rateLimiter) limit(group string) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		fn := func(ctx *fasthttp.RequestCtx) {
-			addr := ctx.RemoteAddr().String()
+			now := time.Now()
 
-			l := r.getLimiter(key + addr)
+			l, delay := r.reserve(group, oAuth2(ctx), ctx.RemoteIP(), now)
+			if l == nil {
+				next(ctx)
+				return
+			}
 
-			if !l.limiter.Allow() {
+			if delay > 0 {
 				ctx.Error(http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				ctx.Response.Header.Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				setRateLimitHeaders(ctx, l, now)
+
 				return
 			}
 
+			setRateLimitHeaders(ctx, l, now)
+
 			next(ctx)
 		}
 		return fasthttp.RequestHandler(fn)
 	}
 }
+
+// setRateLimitHeaders reports the burst of a limiter, the number of requests which may be made
+// immediately, and the number of seconds until the limiter is replenished in full.
+func setRateLimitHeaders(ctx *fasthttp.RequestCtx, l *rate.Limiter, now time.Time) {
+	tokens := math.Max(0, l.TokensAt(now))
+	reset := (float64(l.Burst()) - tokens) / float64(l.Limit())
+
+	ctx.Response.Header.Set("X-RateLimit-Limit", strconv.Itoa(l.Burst()))
+	ctx.Response.Header.Set("X-RateLimit-Remaining", strconv.Itoa(int(tokens)))
+	ctx.Response.Header.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(reset))))
+}
@@ -22,20 +22,23 @@
 package api
 
 import (
+	"net"
 	"testing"
 	"time"
 
+	"github.com/perlin-network/wavelet/conf"
 	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
 )
 
 func TestRateLimit(t *testing.T) {
-	rl := newRateLimiter(2)
+	rl := newRateLimiter()
 	rl.expirationTTL = 30 * time.Millisecond
 
 	// check existing limited should be returned
 
-	l := rl.getLimiter("key1")
-	assert.Equal(t, rl.getLimiter("key1"), l)
+	l := rl.getLimiter("key1", 2, 2)
+	assert.Equal(t, rl.getLimiter("key1", 2, 2), l)
 
 	// check lastSeen should be updated
 
@@ -43,7 +46,7 @@ func TestRateLimit(t *testing.T) {
 
 	time.Sleep(10 * time.Millisecond)
 
-	l = rl.getLimiter("key1")
+	l = rl.getLimiter("key1", 2, 2)
 	assert.True(t, l.lastSeen > now)
 
 	// check cleanup
@@ -62,3 +65,78 @@ func TestRateLimit(t *testing.T) {
 	assert.Nil(t, rl.limiters["key1"])
 	rl.RUnlock()
 }
+
+func TestRateLimitPerClient(t *testing.T) {
+	defer conf.Reset()
+
+	conf.Update(
+		conf.WithAPIRateLimit("test", conf.APIRateLimit{PerSecond: 0.001, Burst: 2}),
+		conf.WithAPIRateLimitTier("premium", 2),
+		conf.WithAPIKeys(
+			conf.APIKey{Name: "explorer", Key: "explorer", RateLimitMultiplier: 3},
+			conf.APIKey{Name: "operator", Key: "operator"},
+		),
+	)
+
+	rl := newRateLimiter()
+
+	handler := rl.limit("test")(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	request := func(ip string, token string) *fasthttp.RequestCtx {
+		var req fasthttp.Request
+		if len(token) > 0 {
+			req.Header.Set("Authorization", authPrefix+token)
+		}
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Init(&req, &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}, nil)
+
+		handler(ctx)
+
+		return ctx
+	}
+
+	throttled := rl.throttled.Count()
+
+	ctx := request("10.0.0.1", "")
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "2", string(ctx.Response.Header.Peek("X-RateLimit-Limit")))
+	assert.Equal(t, "1", string(ctx.Response.Header.Peek("X-RateLimit-Remaining")))
+
+	assert.Equal(t, fasthttp.StatusOK, request("10.0.0.1", "").Response.StatusCode())
+
+	ctx = request("10.0.0.1", "")
+	assert.Equal(t, fasthttp.StatusTooManyRequests, ctx.Response.StatusCode())
+	assert.NotEmpty(t, ctx.Response.Header.Peek("Retry-After"))
+	assert.Equal(t, "0", string(ctx.Response.Header.Peek("X-RateLimit-Remaining")))
+	assert.Equal(t, throttled+1, rl.throttled.Count())
+
+	// Unknown tokens do not identify a client, such that the client is still limited by its IP.
+	assert.Equal(t, fasthttp.StatusTooManyRequests, request("10.0.0.1", "unknown").Response.StatusCode())
+
+	// Other clients are not affected by the client which has exhausted its limit.
+	assert.Equal(t, fasthttp.StatusOK, request("10.0.0.2", "").Response.StatusCode())
+
+	// Clients bearing a token of a tier are limited by their token, at the limit of their tier.
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, fasthttp.StatusOK, request("10.0.0.1", "premium").Response.StatusCode())
+	}
+
+	assert.Equal(t, fasthttp.StatusTooManyRequests, request("10.0.0.3", "premium").Response.StatusCode())
+
+	// Clients bearing an API key are limited by their key, at the limit multiplied by the key.
+	for i := 0; i < 6; i++ {
+		assert.Equal(t, fasthttp.StatusOK, request("10.0.0.1", "explorer").Response.StatusCode())
+	}
+
+	assert.Equal(t, fasthttp.StatusTooManyRequests, request("10.0.0.3", "explorer").Response.StatusCode())
+
+	// API keys without a multiplier are still limited by their key rather than by IP address.
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, fasthttp.StatusOK, request("10.0.0.1", "operator").Response.StatusCode())
+	}
+
+	assert.Equal(t, fasthttp.StatusTooManyRequests, request("10.0.0.3", "operator").Response.StatusCode())
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fastjson"
+)
+
+// acceptsEventStream returns whether or not a request is for events to be streamed as
+// server-sent events rather than over a websocket.
+func acceptsEventStream(ctx *fasthttp.RequestCtx) bool {
+	if websocket.FastHTTPIsWebSocketUpgrade(ctx) {
+		return false
+	}
+
+	return bytes.Contains(ctx.Request.Header.Peek(fasthttp.HeaderAccept), []byte("text/event-stream"))
+}
+
+// serveSSE streams the events of the sink which pass the filters given as query parameters as
+// server-sent events, queueing them to queue until they are written. Every event is sent with
+// its sequence number as its id, so that clients may resume after the event whose id is given
+// as the Last-Event-ID header, or the since query parameter.
+func (s *sink) serveSSE(ctx *fasthttp.RequestCtx, queue *clientQueue) error {
+	since, err := parseSince(ctx.QueryArgs().Peek("since"))
+	if err != nil {
+		return err
+	}
+
+	if lastEventID := ctx.Request.Header.Peek("Last-Event-ID"); len(lastEventID) > 0 {
+		if since, err = parseSince(lastEventID); err != nil {
+			return errors.New("Last-Event-ID must be a sequence number")
+		}
+	}
+
+	values := ctx.QueryArgs()
+
+	sub := &subscription{
+		client: newClient(nil, nil, queue),
+		sink:   s,
+		filters: s.clientFilters(func(queryKey string) string {
+			return string(values.Peek(queryKey))
+		}),
+	}
+
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "no-cache")
+	ctx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	// Streams are closed once the server shuts down.
+	done := ctx.Done()
+
+	s.join(sub, since, false)
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer func() {
+			s.leave(sub)
+			queue.discard()
+		}()
+
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+
+		// Headers are only sent once the first write is flushed.
+		if _, err := w.WriteString(": connected\n\n"); err != nil || w.Flush() != nil {
+			return
+		}
+
+		for {
+			select {
+			case <-queue.ready:
+				for {
+					msg, ok := queue.pop()
+					if !ok {
+						if err := queue.err(); err != nil {
+							writeSSEError(w, err)
+						}
+
+						return
+					}
+
+					if msg == nil {
+						break
+					}
+
+					if len(msg) == 0 {
+						continue
+					}
+
+					writeSSE(w, msg)
+
+					if err := w.Flush(); err != nil {
+						return
+					}
+				}
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil || w.Flush() != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeSSE writes a message as a server-sent event. Events are sent with their sequence number
+// as their id, and notices to the client are sent as events named after their op.
+func writeSSE(w *bufio.Writer, msg []byte) {
+	if seq, ok := eventSeq(msg); ok {
+		_, _ = w.WriteString("id: ")
+		_, _ = w.WriteString(strconv.FormatUint(seq, 10))
+		_ = w.WriteByte('\n')
+	} else if op := fastjson.GetString(msg, "op"); len(op) > 0 {
+		_, _ = w.WriteString("event: ")
+		_, _ = w.WriteString(op)
+		_ = w.WriteByte('\n')
+	}
+
+	_, _ = w.WriteString("data: ")
+	_, _ = w.Write(bytes.TrimSpace(msg))
+	_, _ = w.WriteString("\n\n")
+}
+
+func writeSSEError(w *bufio.Writer, err error) {
+	writeSSE(w, wsReply("error", "", func(arena *fastjson.Arena, o *fastjson.Value) {
+		o.Set("error", arena.NewString(err.Error()))
+	}))
+
+	_ = w.Flush()
+}
+
+// eventSeq returns the sequence number an event was prefixed with by withSeq.
+func eventSeq(buf []byte) (uint64, bool) {
+	prefix := []byte(`{"seq":`)
+
+	if !bytes.HasPrefix(buf, prefix) {
+		return 0, false
+	}
+
+	buf = buf[len(prefix):]
+
+	end := bytes.IndexAny(buf, ",}")
+	if end < 0 {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseUint(string(buf[:end]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return seq, true
+}
+
+// parseSince parses the sequence number of the event a client resumes after. It returns -1 if
+// raw is empty.
+func parseSince(raw []byte) (int64, error) {
+	if len(raw) == 0 {
+		return -1, nil
+	}
+
+	seq, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || seq < 0 {
+		return -1, errors.New("since must be a sequence number")
+	}
+
+	return seq, nil
+}
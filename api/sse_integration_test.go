@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package api
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestPollSSE(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	server := &fasthttp.Server{Handler: gateway.router.Handler}
+	go func() {
+		_ = server.Serve(ln)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	connect := func(query string, lastEventID string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, "http://"+ln.Addr().String()+"/poll/accounts?"+query, nil)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		req.Header.Set("Accept", "text/event-stream")
+
+		if len(lastEventID) > 0 {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		res, err := client.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		return res
+	}
+
+	write := func(id string) {
+		_, err := gateway.Write([]byte(`{"mod":"accounts","event":"balance_updated","account_id":"` + id + `"}`))
+		assert.NoError(t, err)
+	}
+
+	res := connect("id=bb", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(res.Body)
+
+	write("aa")
+	write("bb")
+
+	assert.Equal(t,
+		"id: 2\ndata: {\"seq\":2,\"mod\":\"accounts\",\"event\":\"balance_updated\",\"account_id\":\"bb\"}",
+		readSSE(t, r),
+	)
+
+	_ = res.Body.Close()
+
+	// Events broadcasted while disconnected are replayed once the client resumes.
+	write("bb")
+	write("aa")
+	write("bb")
+
+	res = connect("id=bb&since=0", "2")
+	defer res.Body.Close()
+
+	r = bufio.NewReader(res.Body)
+
+	assert.True(t, strings.HasPrefix(readSSE(t, r), "id: 3\n"))
+	assert.True(t, strings.HasPrefix(readSSE(t, r), "id: 5\n"))
+
+	bad := connect("", "abc")
+	assert.Equal(t, http.StatusBadRequest, bad.StatusCode)
+	_ = bad.Body.Close()
+
+	// Streams still open are closed once the server shuts down.
+	assert.NoError(t, server.Shutdown())
+}
+
+// readSSE reads the next server-sent event from r, skipping comments.
+func readSSE(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+
+	var lines []string
+
+	for {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return ""
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+
+		if len(line) == 0 {
+			if len(lines) > 0 {
+				return strings.Join(lines, "\n")
+			}
+
+			continue
+		}
+
+		if !strings.HasPrefix(line, ":") {
+			lines = append(lines, line)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSeq(t *testing.T) {
+	seq, ok := eventSeq(withSeq([]byte(`{"mod":"tx"}`), 42))
+	assert.True(t, ok)
+	assert.EqualValues(t, 42, seq)
+
+	seq, ok = eventSeq(withSeq([]byte(`{}`), 7))
+	assert.True(t, ok)
+	assert.EqualValues(t, 7, seq)
+
+	_, ok = eventSeq([]byte(`{"op":"dropped","id":"","count":1}`))
+	assert.False(t, ok)
+}
+
+func TestWriteSSE(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := bufio.NewWriter(&buf)
+	writeSSE(w, withSeq([]byte(`{"mod":"tx"}`), 3))
+	writeSSE(w, []byte(`{"op":"dropped","id":"","count":1}`))
+	assert.NoError(t, w.Flush())
+
+	assert.Equal(t,
+		"id: 3\ndata: {\"seq\":3,\"mod\":\"tx\"}\n\n"+
+			"event: dropped\ndata: {\"op\":\"dropped\",\"id\":\"\",\"count\":1}\n\n",
+		buf.String(),
+	)
+}
+
+func TestParseSince(t *testing.T) {
+	since, err := parseSince(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, -1, since)
+
+	since, err = parseSince([]byte("12"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 12, since)
+
+	_, err = parseSince([]byte("-1"))
+	assert.Error(t, err)
+
+	_, err = parseSince([]byte("abc"))
+	assert.Error(t, err)
+}
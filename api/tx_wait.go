@@ -0,0 +1,180 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package api
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/perlin-network/wavelet"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/log"
+	"github.com/pkg/errors"
+	"github.com/valyala/fastjson"
+)
+
+const (
+	waitFinalized = "finalized"
+
+	// defaultTxWaitTimeout is how long sending a transaction waits for it to be finalized, should
+	// no timeout be given.
+	defaultTxWaitTimeout = 30 * time.Second
+
+	// maxTxWaitTimeout keeps waiting for a transaction within the timeout of requests.
+	maxTxWaitTimeout = 50 * time.Second
+
+	// txWaitQueueSize is the number of events of a transaction queued while waiting for it.
+	txWaitQueueSize = 16
+)
+
+// txReceipt is the outcome of a transaction sent by a client waiting for it to be finalized.
+type txReceipt struct {
+	status string
+	height uint64
+	err    string
+}
+
+// parseTxWait parses the wait and timeout query parameters of a request to send a transaction.
+// It returns a zero timeout should the request not wait for the transaction to be finalized.
+func parseTxWait(wait, timeout []byte) (time.Duration, error) {
+	if len(wait) == 0 {
+		if len(timeout) > 0 {
+			return 0, errors.New("timeout may only be given when waiting")
+		}
+
+		return 0, nil
+	}
+
+	if string(wait) != waitFinalized {
+		return 0, errors.Errorf("wait must be %q", waitFinalized)
+	}
+
+	if len(timeout) == 0 {
+		return defaultTxWaitTimeout, nil
+	}
+
+	d, err := time.ParseDuration(string(timeout))
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid timeout")
+	}
+
+	if d <= 0 || d > maxTxWaitTimeout {
+		return 0, errors.Errorf("timeout must be positive and at most %s", maxTxWaitTimeout)
+	}
+
+	return d, nil
+}
+
+// parseTxReceipt returns the receipt of a transaction from an event of the tx sink, if the event
+// is of the transaction being applied or rejected in a finalized block.
+func parseTxReceipt(parser *fastjson.Parser, buf []byte) (txReceipt, bool) {
+	v, err := parser.ParseBytes(buf)
+	if err != nil {
+		return txReceipt{}, false
+	}
+
+	switch event := string(v.GetStringBytes(log.KeyEvent)); event {
+	case statusApplied, statusRejected:
+		return txReceipt{
+			status: event,
+			height: v.GetUint64("height"),
+			err:    string(v.GetStringBytes("error")),
+		}, true
+	}
+
+	return txReceipt{}, false
+}
+
+// newTxReceipt returns the receipt of a transaction from its outcome recorded by the ledger.
+func newTxReceipt(receipt wavelet.TransactionReceipt) txReceipt {
+	if receipt.Err != nil {
+		return txReceipt{status: statusRejected, height: receipt.Height, err: receipt.Err.Error()}
+	}
+
+	return txReceipt{status: statusApplied, height: receipt.Height}
+}
+
+// waitTransaction calls add, and waits until the transaction with the given ID is applied or
+// rejected in a finalized block. The events of the transaction are subscribed to before add is
+// called, so that its outcome may not be missed. Should the transaction already have been
+// finalized, add is not called and its receipt is returned immediately. Should the transaction
+// not be finalized within timeout, its receipt is pending.
+func (g *Gateway) waitTransaction(id wavelet.TransactionID, timeout time.Duration, add func()) (txReceipt, error) {
+	sink, exists := g.sink(log.ModuleTX)
+	if !exists {
+		return txReceipt{}, errors.New("transaction events are not available")
+	}
+
+	queue := newClientQueue(conf.WebsocketDropOldest, txWaitQueueSize, nil)
+
+	sub := &subscription{
+		client: newClient(nil, nil, queue),
+		sink:   sink,
+		filters: sink.clientFilters(func(queryKey string) string {
+			if queryKey == "id" {
+				return hex.EncodeToString(id[:])
+			}
+
+			return ""
+		}),
+	}
+
+	sink.join(sub, -1, false)
+
+	defer func() {
+		sink.leave(sub)
+		queue.discard()
+	}()
+
+	// The ledger is only checked once subscribed, as the outcome of a transaction is recorded
+	// before it is announced. Either its receipt is found, or its announcement is received.
+	if receipt, ok := g.ledger.TransactionReceipt(id); ok {
+		return newTxReceipt(receipt), nil
+	}
+
+	add()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var parser fastjson.Parser
+
+	for {
+		select {
+		case <-timer.C:
+			return txReceipt{status: statusPending}, nil
+		case <-queue.ready:
+			for {
+				msg, ok := queue.pop()
+				if !ok {
+					return txReceipt{status: statusPending}, queue.err()
+				}
+
+				if msg == nil {
+					break
+				}
+
+				if receipt, ok := parseTxReceipt(&parser, msg); ok {
+					return receipt, nil
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fastjson"
+)
+
+func TestParseTxWait(t *testing.T) {
+	timeout, err := parseTxWait(nil, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, timeout)
+
+	timeout, err = parseTxWait([]byte("finalized"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTxWaitTimeout, timeout)
+
+	timeout, err = parseTxWait([]byte("finalized"), []byte("1500ms"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, timeout)
+
+	for _, query := range [][2]string{
+		{"", "1s"},
+		{"accepted", ""},
+		{"finalized", "soon"},
+		{"finalized", "0s"},
+		{"finalized", "-1s"},
+		{"finalized", "1h"},
+	} {
+		_, err := parseTxWait([]byte(query[0]), []byte(query[1]))
+		assert.Error(t, err, query)
+	}
+}
+
+func TestParseTxReceipt(t *testing.T) {
+	var parser fastjson.Parser
+
+	receipt, ok := parseTxReceipt(&parser, []byte(`{"seq":1,"mod":"tx","event":"applied","height":4}`))
+	assert.True(t, ok)
+	assert.Equal(t, txReceipt{status: statusApplied, height: 4}, receipt)
+
+	receipt, ok = parseTxReceipt(&parser, []byte(`{"seq":2,"event":"rejected","height":5,"error":"no funds"}`))
+	assert.True(t, ok)
+	assert.Equal(t, txReceipt{status: statusRejected, height: 5, err: "no funds"}, receipt)
+
+	_, ok = parseTxReceipt(&parser, []byte(`{"op":"dropped","id":"","count":1}`))
+	assert.False(t, ok)
+
+	_, ok = parseTxReceipt(&parser, []byte(`{"seq":3,"event":"failed"}`))
+	assert.False(t, ok)
+}
@@ -0,0 +1,589 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/internal/backoff"
+	"github.com/perlin-network/wavelet/log"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fastjson"
+)
+
+const (
+	// webhookQueueSize is the number of events which may be queued to be delivered to a webhook.
+	// The oldest events are dropped for webhooks whose queue is full, and kept as dead letters.
+	webhookQueueSize = 1024
+
+	// webhookTimeout is how long a webhook is given to respond to the delivery of an event.
+	webhookTimeout = 10 * time.Second
+
+	// maxDeadLetters is the number of the latest events which failed to be delivered that are
+	// kept to be inspected.
+	maxDeadLetters = 1000
+
+	// HeaderWebhookID holds the ID of the webhook an event is delivered to.
+	HeaderWebhookID = "X-Wavelet-Webhook"
+
+	// HeaderWebhookSignature holds the hex-encoded HMAC-SHA256 of the body of an event delivered
+	// to a webhook, keyed by the secret of the webhook, prefixed with "sha256=".
+	HeaderWebhookSignature = "X-Wavelet-Signature"
+)
+
+// keyWebhooks prefixes the webhooks registered, which are stored alongside the ledger.
+var keyWebhooks = []byte("webhooks:")
+
+// errWebhookBehind is kept with events dropped for webhooks too slow to keep up with them.
+var errWebhookBehind = errors.New("dropped from the queue of the webhook for falling behind")
+
+// webhook delivers the events of a sink which pass its filters to a URL.
+type webhook struct {
+	id     string
+	url    string
+	topic  string
+	event  string
+	secret string
+
+	// filters are keyed by the query parameters of the sink, as with /poll.
+	filters map[string]string
+
+	sub  *subscription
+	done chan struct{}
+}
+
+// deadLetter is an event which failed to be delivered to a webhook.
+type deadLetter struct {
+	webhookID string
+	url       string
+	event     []byte
+	attempts  int
+	err       string
+	failedAt  time.Time
+}
+
+// webhooks holds the webhooks registered with the gateway, and the events which failed to be
+// delivered to them.
+type webhooks struct {
+	sync.Mutex
+
+	hooks map[string]*webhook
+	dead  []deadLetter
+
+	client *fasthttp.Client
+
+	// Delivery of an event is retried with retry, until it fails maxAttempts times.
+	retry       backoff.Backoff
+	maxAttempts int
+}
+
+func newWebhooks() *webhooks {
+	return &webhooks{
+		hooks:       make(map[string]*webhook),
+		client:      &fasthttp.Client{},
+		retry:       backoff.Backoff{Min: time.Second, Max: 5 * time.Minute, Factor: 2, Jitter: true},
+		maxAttempts: 8,
+	}
+}
+
+// parseWebhook parses a webhook registered over the API, or stored alongside the ledger.
+func parseWebhook(v *fastjson.Value) (*webhook, error) {
+	hook := &webhook{
+		id:      string(v.GetStringBytes("id")),
+		url:     string(v.GetStringBytes("url")),
+		topic:   string(v.GetStringBytes("topic")),
+		event:   string(v.GetStringBytes("event")),
+		secret:  string(v.GetStringBytes("secret")),
+		filters: make(map[string]string),
+	}
+
+	u, err := url.Parse(hook.url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return nil, errors.Errorf("url %q must be an absolute http or https url", hook.url)
+	}
+
+	if len(hook.topic) == 0 {
+		return nil, errors.New("missing topic")
+	}
+
+	if filters := v.Get("filters"); filters != nil {
+		obj, err := filters.Object()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid filters")
+		}
+
+		obj.Visit(func(key []byte, v *fastjson.Value) {
+			if v.Type() == fastjson.TypeString {
+				hook.filters[string(key)] = string(v.GetStringBytes())
+			} else {
+				hook.filters[string(key)] = string(v.MarshalTo(nil))
+			}
+		})
+	}
+
+	return hook, nil
+}
+
+func (h *webhook) getObject(arena *fastjson.Arena, withSecret bool) *fastjson.Value {
+	o := arena.NewObject()
+
+	o.Set("id", arena.NewString(h.id))
+	o.Set("url", arena.NewString(h.url))
+	o.Set("topic", arena.NewString(h.topic))
+
+	filters := arena.NewObject()
+	for key, value := range h.filters {
+		filters.Set(key, arena.NewString(value))
+	}
+
+	o.Set("filters", filters)
+
+	if len(h.event) > 0 {
+		o.Set("event", arena.NewString(h.event))
+	}
+
+	if withSecret {
+		o.Set("secret", arena.NewString(h.secret))
+	}
+
+	return o
+}
+
+// signWebhook returns the hex-encoded HMAC-SHA256 of body keyed by secret.
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// addWebhook subscribes a webhook to its sink, and starts delivering events to it. A webhook
+// without an ID or secret has them generated.
+func (g *Gateway) addWebhook(hook *webhook) error {
+	sink, exists := g.sink(hook.topic)
+	if !exists {
+		return errors.Errorf("unknown topic %q", hook.topic)
+	}
+
+	for key := range hook.filters {
+		if _, exists := sink.filters[key]; !exists {
+			return errors.Errorf("unknown filter %q for topic %q", key, hook.topic)
+		}
+	}
+
+	if len(hook.id) == 0 {
+		id, err := randomHex(16)
+		if err != nil {
+			return err
+		}
+
+		hook.id = id
+	}
+
+	if len(hook.secret) == 0 {
+		secret, err := randomHex(32)
+		if err != nil {
+			return err
+		}
+
+		hook.secret = secret
+	}
+
+	filters := sink.clientFilters(func(queryKey string) string {
+		return hook.filters[queryKey]
+	})
+
+	if len(hook.event) > 0 {
+		filters[log.KeyEvent] = hook.event
+	}
+
+	// Events dropped for a webhook which falls behind are kept as dead letters, rather than have
+	// it be posted a notice of how many were as though it were an event.
+	queue := newClientQueue(conf.WebsocketDropOldest, webhookQueueSize, nil)
+	queue.onDrop = func(event []byte) {
+		g.webhooks.bury(hook, event, 0, errWebhookBehind)
+	}
+
+	hook.sub = &subscription{
+		id:      hook.id,
+		client:  newClient(nil, nil, queue),
+		sink:    sink,
+		filters: filters,
+	}
+	hook.done = make(chan struct{})
+
+	g.webhooks.Lock()
+	if _, exists := g.webhooks.hooks[hook.id]; exists {
+		g.webhooks.Unlock()
+		return errors.Errorf("webhook %s already exists", hook.id)
+	}
+
+	g.webhooks.hooks[hook.id] = hook
+	g.webhooks.Unlock()
+
+	sink.join(hook.sub, -1, false)
+
+	go g.webhooks.deliver(hook)
+
+	return nil
+}
+
+// removeWebhook stops delivering events to a webhook, returning false if it does not exist.
+func (g *Gateway) removeWebhook(id string) bool {
+	g.webhooks.Lock()
+	hook, exists := g.webhooks.hooks[id]
+	delete(g.webhooks.hooks, id)
+	g.webhooks.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	hook.stop()
+
+	return true
+}
+
+func (h *webhook) stop() {
+	h.sub.sink.leave(h.sub)
+	h.sub.client.queue.discard()
+	close(h.done)
+}
+
+// stopWebhooks stops delivering events to every webhook.
+func (g *Gateway) stopWebhooks() {
+	g.webhooks.Lock()
+	hooks := g.webhooks.hooks
+	g.webhooks.hooks = make(map[string]*webhook)
+	g.webhooks.Unlock()
+
+	for _, hook := range hooks {
+		hook.stop()
+	}
+}
+
+// loadWebhooks starts delivering events to the webhooks stored alongside the ledger.
+func (g *Gateway) loadWebhooks() error {
+	if g.kv == nil {
+		return nil
+	}
+
+	var parser fastjson.Parser
+
+	it := store.NewPrefixIterator(g.kv, keyWebhooks)
+	defer it.Release()
+
+	for it.Next() {
+		v, err := parser.ParseBytes(it.Value())
+		if err != nil {
+			return errors.Wrapf(err, "invalid webhook %s", it.Key()[len(keyWebhooks):])
+		}
+
+		hook, err := parseWebhook(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid webhook %s", it.Key()[len(keyWebhooks):])
+		}
+
+		if err := g.addWebhook(hook); err != nil {
+			return err
+		}
+	}
+
+	return it.Error()
+}
+
+func (g *Gateway) storeWebhook(hook *webhook) error {
+	if g.kv == nil {
+		return nil
+	}
+
+	var arena fastjson.Arena
+
+	return g.kv.Put(webhookKey(hook.id), hook.getObject(&arena, true).MarshalTo(nil))
+}
+
+func (g *Gateway) deleteStoredWebhook(id string) error {
+	if g.kv == nil {
+		return nil
+	}
+
+	return g.kv.Delete(webhookKey(id))
+}
+
+func webhookKey(id string) []byte {
+	return append(append(make([]byte, 0, len(keyWebhooks)+len(id)), keyWebhooks...), id...)
+}
+
+// deliver posts every event queued to a webhook, one at a time, until the webhook is removed.
+func (w *webhooks) deliver(hook *webhook) {
+	queue := hook.sub.client.queue
+
+	for {
+		select {
+		case <-hook.done:
+			return
+		case <-queue.ready:
+			for {
+				msg, ok := queue.pop()
+				if !ok {
+					return
+				}
+
+				if msg == nil {
+					break
+				}
+
+				if len(msg) == 0 {
+					continue
+				}
+
+				w.post(hook, msg)
+			}
+		}
+	}
+}
+
+// post delivers an event to a webhook, retrying with backoff should it fail. Events which fail to
+// be delivered too many times are kept as dead letters.
+func (w *webhooks) post(hook *webhook, event []byte) {
+	retry := w.retry.Copy()
+
+	var err error
+
+	attempts := 0
+
+	for {
+		attempts++
+
+		if err = w.send(hook, event); err == nil {
+			return
+		}
+
+		if attempts >= w.maxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(retry.Duration()):
+		case <-hook.done:
+			return
+		}
+	}
+
+	logger := log.Node()
+	logger.Warn().
+		Err(err).
+		Str("webhook_id", hook.id).
+		Int("attempts", attempts).
+		Msg("Failed to deliver event to webhook.")
+
+	w.bury(hook, event, attempts, err)
+}
+
+// bury keeps an event which failed to be delivered to a webhook as a dead letter, evicting the
+// oldest dead letter should there be too many.
+func (w *webhooks) bury(hook *webhook, event []byte, attempts int, err error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if len(w.dead) == maxDeadLetters {
+		copy(w.dead, w.dead[1:])
+		w.dead = w.dead[:len(w.dead)-1]
+	}
+
+	w.dead = append(w.dead, deadLetter{
+		webhookID: hook.id,
+		url:       hook.url,
+		event:     event,
+		attempts:  attempts,
+		err:       err.Error(),
+		failedAt:  time.Now(),
+	})
+}
+
+func (w *webhooks) send(hook *webhook, event []byte) error {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI(hook.url)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	req.Header.Set(HeaderWebhookID, hook.id)
+	req.Header.Set(HeaderWebhookSignature, "sha256="+signWebhook(hook.secret, event))
+	req.SetBody(event)
+
+	res := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(res)
+
+	if err := w.client.DoTimeout(req, res, webhookTimeout); err != nil {
+		return err
+	}
+
+	if code := res.StatusCode(); code < 200 || code >= 300 {
+		return errors.Errorf("webhook responded with status %d", code)
+	}
+
+	return nil
+}
+
+func (g *Gateway) registerWebhook(ctx *fasthttp.RequestCtx) {
+	parser := g.parserPool.Get()
+	defer g.parserPool.Put(parser)
+
+	v, err := parser.ParseBytes(ctx.PostBody())
+	if err != nil {
+		g.renderError(ctx, ErrBadRequest(errors.Wrap(err, "error parsing request body")))
+		return
+	}
+
+	hook, err := parseWebhook(v)
+	if err != nil {
+		g.renderError(ctx, ErrBadRequest(err))
+		return
+	}
+
+	// Webhooks are always given a new ID.
+	hook.id = ""
+
+	if err := g.addWebhook(hook); err != nil {
+		g.renderError(ctx, ErrBadRequest(err))
+		return
+	}
+
+	if err := g.storeWebhook(hook); err != nil {
+		g.removeWebhook(hook.id)
+		g.renderError(ctx, ErrInternal(errors.Wrap(err, "error storing webhook")))
+
+		return
+	}
+
+	g.render(ctx, &webhookResponse{hook: hook})
+}
+
+func (g *Gateway) listWebhooks(ctx *fasthttp.RequestCtx) {
+	g.webhooks.Lock()
+	hooks := make(webhookList, 0, len(g.webhooks.hooks))
+
+	for _, hook := range g.webhooks.hooks {
+		hooks = append(hooks, hook)
+	}
+	g.webhooks.Unlock()
+
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].id < hooks[j].id
+	})
+
+	g.render(ctx, hooks)
+}
+
+func (g *Gateway) deleteWebhook(ctx *fasthttp.RequestCtx) {
+	id, ok := ctx.UserValue("id").(string)
+	if !ok {
+		g.renderError(ctx, ErrBadRequest(errors.New("id must be a string")))
+		return
+	}
+
+	if !g.removeWebhook(id) {
+		g.renderError(ctx, ErrNotFound(errors.Errorf("could not find webhook %s", id)))
+		return
+	}
+
+	if err := g.deleteStoredWebhook(id); err != nil {
+		g.renderError(ctx, ErrInternal(errors.Wrap(err, "error deleting webhook")))
+		return
+	}
+
+	g.render(ctx, &msgResponse{msg: fmt.Sprintf("Successfully deleted webhook %s", id)})
+}
+
+func (g *Gateway) listDeadLetters(ctx *fasthttp.RequestCtx) {
+	g.webhooks.Lock()
+	dead := make(deadLetterList, len(g.webhooks.dead))
+	copy(dead, g.webhooks.dead)
+	g.webhooks.Unlock()
+
+	g.render(ctx, dead)
+}
+
+type webhookResponse struct {
+	hook *webhook
+}
+
+func (s *webhookResponse) marshalJSON(arena *fastjson.Arena) ([]byte, error) {
+	return s.hook.getObject(arena, true).MarshalTo(nil), nil
+}
+
+type webhookList []*webhook
+
+func (s webhookList) marshalJSON(arena *fastjson.Arena) ([]byte, error) {
+	list := arena.NewArray()
+
+	for i, hook := range s {
+		list.SetArrayItem(i, hook.getObject(arena, false))
+	}
+
+	return list.MarshalTo(nil), nil
+}
+
+type deadLetterList []deadLetter
+
+func (s deadLetterList) marshalJSON(arena *fastjson.Arena) ([]byte, error) {
+	list := arena.NewArray()
+
+	for i, dead := range s {
+		event, err := fastjson.ParseBytes(dead.event)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid event")
+		}
+
+		o := arena.NewObject()
+		o.Set("webhook_id", arena.NewString(dead.webhookID))
+		o.Set("url", arena.NewString(dead.url))
+		o.Set("event", event)
+		o.Set("attempts", arena.NewNumberString(strconv.Itoa(dead.attempts)))
+		o.Set("error", arena.NewString(dead.err))
+		o.Set("failed_at", arena.NewString(dead.failedAt.UTC().Format(time.RFC3339)))
+
+		list.SetArrayItem(i, o)
+	}
+
+	return list.MarshalTo(nil), nil
+}
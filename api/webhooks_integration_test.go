@@ -0,0 +1,190 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/internal/backoff"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fastjson"
+)
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func TestWebhooks(t *testing.T) {
+	received := make(chan receivedWebhook, 16)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- receivedWebhook{header: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	defer conf.Reset()
+	conf.Update(conf.WithSecret("secret"))
+
+	kv := store.NewInmem()
+
+	newGateway := func() *Gateway {
+		gateway := New()
+		gateway.setup()
+		gateway.kv = kv
+		gateway.webhooks.retry = backoff.Backoff{Min: 10 * time.Millisecond, Max: 20 * time.Millisecond}
+		gateway.webhooks.maxAttempts = 3
+
+		require.NoError(t, gateway.loadWebhooks())
+
+		return gateway
+	}
+
+	gateway := newGateway()
+	defer gateway.stopWebhooks()
+
+	request := func(method, path, body string) (int, *fastjson.Value) {
+		req, err := http.NewRequest(method, "http://localhost"+path, bytes.NewBufferString(body))
+		require.NoError(t, err)
+
+		req.Header.Set("Authorization", "Bearer secret")
+
+		w, err := serve(gateway.router, req)
+		require.NoError(t, err)
+
+		res, err := ioutil.ReadAll(w.Body)
+		require.NoError(t, err)
+
+		v, err := fastjson.ParseBytes(res)
+		require.NoError(t, err)
+
+		return w.StatusCode, v
+	}
+
+	write := func(event, id string) {
+		_, err := gateway.Write([]byte(`{"mod":"accounts","event":"` + event + `","account_id":"` + id + `"}`))
+		require.NoError(t, err)
+	}
+
+	code, _ := request("POST", "/webhooks", `{"url":"ftp://example.com","topic":"accounts"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = request("POST", "/webhooks", `{"url":"`+server.URL+`","topic":"ledger"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = request("POST", "/webhooks", `{"url":"`+server.URL+`","topic":"accounts","filters":{"sender":"bb"}}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, hook := request("POST", "/webhooks",
+		`{"url":"`+server.URL+`/ok","topic":"accounts","filters":{"id":"bb"},"event":"balance_updated","secret":"s3cret"}`,
+	)
+	require.Equal(t, http.StatusOK, code)
+
+	id := string(hook.GetStringBytes("id"))
+	assert.NotEmpty(t, id)
+	assert.Equal(t, "s3cret", string(hook.GetStringBytes("secret")))
+
+	// Only events passing the filters of the webhook are posted.
+	write("balance_updated", "aa")
+	write("stake_updated", "bb")
+	write("balance_updated", "bb")
+
+	select {
+	case r := <-received:
+		assert.Equal(t, `{"seq":3,"mod":"accounts","event":"balance_updated","account_id":"bb"}`, string(r.body))
+		assert.Equal(t, id, r.header.Get(HeaderWebhookID))
+
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		_, _ = mac.Write(r.body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.header.Get(HeaderWebhookSignature))
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for webhook")
+	}
+
+	// Events which fail to be delivered are retried, and then kept as dead letters.
+	code, failing := request("POST", "/webhooks", `{"url":"`+server.URL+`/fail","topic":"accounts"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, failing.GetStringBytes("secret"))
+
+	write("balance_updated", "cc")
+
+	assert.Eventually(t, func() bool {
+		_, dead := request("GET", "/webhooks/dead", "")
+		return len(dead.GetArray()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, dead := request("GET", "/webhooks/dead", "")
+	letter := dead.GetArray()[0]
+	assert.Equal(t, string(failing.GetStringBytes("id")), string(letter.GetStringBytes("webhook_id")))
+	assert.Equal(t, server.URL+"/fail", string(letter.GetStringBytes("url")))
+	assert.Equal(t, 3, letter.GetInt("attempts"))
+	assert.Equal(t, "cc", string(letter.GetStringBytes("event", "account_id")))
+	assert.Contains(t, string(letter.GetStringBytes("error")), "500")
+
+	// Webhooks are listed without their secrets.
+	_, list := request("GET", "/webhooks", "")
+	require.Len(t, list.GetArray(), 2)
+
+	for _, hook := range list.GetArray() {
+		assert.Nil(t, hook.Get("secret"))
+	}
+
+	// Webhooks are stored alongside the ledger.
+	restarted := newGateway()
+	assert.Len(t, restarted.webhooks.hooks, 2)
+	restarted.stopWebhooks()
+
+	code, _ = request("DELETE", "/webhooks/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = request("DELETE", "/webhooks/"+id, "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	write("balance_updated", "bb")
+
+	select {
+	case r := <-received:
+		t.Fatalf("deleted webhook was posted %s", r.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	restarted = newGateway()
+	assert.Len(t, restarted.webhooks.hooks, 1)
+	restarted.stopWebhooks()
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fastjson"
+)
+
+func TestParseWebhook(t *testing.T) {
+	parse := func(s string) (*webhook, error) {
+		return parseWebhook(fastjson.MustParse(s))
+	}
+
+	hook, err := parse(
+		`{"url":"https://example.com/hook","topic":"tx","filters":{"sender":"aa","tag":1},"event":"applied"}`,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook", hook.url)
+	assert.Equal(t, "tx", hook.topic)
+	assert.Equal(t, "applied", hook.event)
+	assert.Equal(t, map[string]string{"sender": "aa", "tag": "1"}, hook.filters)
+
+	_, err = parse(`{"url":"example.com","topic":"tx"}`)
+	assert.Error(t, err)
+
+	_, err = parse(`{"url":"https://example.com"}`)
+	assert.Error(t, err)
+
+	_, err = parse(`{"url":"https://example.com","topic":"tx","filters":[]}`)
+	assert.Error(t, err)
+}
+
+func TestSignWebhook(t *testing.T) {
+	// HMAC-SHA256 test vector from RFC 4231.
+	assert.Equal(t,
+		"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		signWebhook("Jefe", []byte("what do ya want for nothing?")),
+	)
+}
+
+func TestWebhookDroppedEvents(t *testing.T) {
+	gateway := New()
+	gateway.setup()
+
+	// Nothing listens on the webhook, so that events pile up in its queue.
+	hook := &webhook{url: "http://127.0.0.1:1/hook", topic: "accounts", filters: map[string]string{}}
+	require.NoError(t, gateway.addWebhook(hook))
+
+	defer gateway.stopWebhooks()
+
+	queue := hook.sub.client.queue
+
+	for i := 0; i < webhookQueueSize+10; i++ {
+		assert.True(t, queue.pushEvent([]byte(`{"mod":"accounts","event":"balance_updated"}`), "a"))
+	}
+
+	gateway.webhooks.Lock()
+	dead := make([]deadLetter, len(gateway.webhooks.dead))
+	copy(dead, gateway.webhooks.dead)
+	gateway.webhooks.Unlock()
+
+	// The event being delivered may already have left the queue.
+	assert.True(t, len(dead) >= 9 && len(dead) <= 10, "got %d dead letters", len(dead))
+
+	for _, letter := range dead {
+		assert.Equal(t, hook.id, letter.webhookID)
+		assert.Equal(t, 0, letter.attempts)
+		assert.Equal(t, errWebhookBehind.Error(), letter.err)
+	}
+
+	// Webhooks are never posted a notice of the events dropped for them.
+	for {
+		msg, ok := queue.pop()
+		if !ok || msg == nil {
+			break
+		}
+
+		assert.NotContains(t, string(msg), `"op":"dropped"`)
+	}
+}
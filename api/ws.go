@@ -20,10 +20,14 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"strconv"
 	"time"
 
 	"github.com/fasthttp/websocket"
+	"github.com/perlin-network/wavelet/log"
+	"github.com/pkg/errors"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fastjson"
 )
@@ -34,6 +38,13 @@ const (
 	pingPeriod         = (pongWait * 9) / 10
 	maxMessageSize     = 512
 	maxPaginationLimit = 5000
+
+	// maxSubscriptions is the maximum number of subscriptions a client may make over a socket.
+	maxSubscriptions = 16
+
+	// sinkReplaySize is the number of the latest events of a sink which are buffered to be
+	// replayed to clients resuming.
+	sinkReplaySize = 256
 )
 
 var upgrader = websocket.FastHTTPUpgrader{
@@ -45,12 +56,45 @@ var upgrader = websocket.FastHTTPUpgrader{
 }
 
 type client struct {
-	sink *sink
 	conn *websocket.Conn
 
+	// sinks looks up the sinks which may be subscribed to over the socket by topic.
+	sinks func(topic string) (*sink, bool)
+
+	queue *clientQueue
+
+	// Only accessed by readWorker.
+	subs   map[string]*subscription
+	parser fastjson.Parser
+}
+
+func newClient(conn *websocket.Conn, sinks func(topic string) (*sink, bool), queue *clientQueue) *client {
+	return &client{
+		conn:  conn,
+		sinks: sinks,
+		queue: queue,
+		subs:  make(map[string]*subscription),
+	}
+}
+
+// subscription is a set of filters a client subscribed to the events of a sink with.
+type subscription struct {
+	id      string
+	client  *client
+	sink    *sink
 	filters map[string]string
-	queue   chan []byte
-	done    chan struct{}
+}
+
+func (sub *subscription) matches(v *fastjson.Value) bool {
+	for key, condition := range sub.filters {
+		val := v.Get(key)
+
+		if val == nil || !fastjsonEquals(val, condition) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (c *client) readWorker() {
@@ -63,45 +107,180 @@ func (c *client) readWorker() {
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, msg, err := c.conn.ReadMessage()
 		if err != nil {
 			break
 		}
+
+		if err := c.handle(msg); err != nil {
+			c.reply(wsReply("error", "", func(arena *fastjson.Arena, o *fastjson.Value) {
+				o.Set("error", arena.NewString(err.Error()))
+			}))
+		}
+	}
+
+	// Once every subscription has left its sink, nothing else may be queued to the client.
+	for _, sub := range c.subs {
+		sub.sink.leave(sub)
 	}
 
-	c.sink.leave <- c
+	c.queue.close()
 	_ = c.conn.Close()
 }
 
-func (c *client) writeWorker() {
-	defer close(c.done)
+// handle handles a message sent by the client, which subscribes to or unsubscribes from events.
+//
+//	{"op": "subscribe", "id": "a", "topic": "tx", "filters": {"sender": "..."}, "since": 42}
+//	{"op": "unsubscribe", "id": "a"}
+func (c *client) handle(msg []byte) error {
+	v, err := c.parser.ParseBytes(msg)
+	if err != nil {
+		return errors.Wrap(err, "invalid json")
+	}
+
+	id := string(v.GetStringBytes("id"))
+
+	switch op := string(v.GetStringBytes("op")); op {
+	case "subscribe":
+		return c.subscribe(id, v)
+	case "unsubscribe":
+		sub, exists := c.subs[id]
+		if !exists {
+			return errors.Errorf("no subscription with id %q", id)
+		}
+
+		sub.sink.leave(sub)
+		delete(c.subs, id)
+
+		c.reply(wsReply("unsubscribed", id, nil))
+
+		return nil
+	default:
+		return errors.Errorf("unknown op %q", op)
+	}
+}
+
+func (c *client) subscribe(id string, v *fastjson.Value) error {
+	if _, exists := c.subs[id]; exists {
+		return errors.Errorf("subscription with id %q already exists", id)
+	}
+
+	if len(c.subs) >= maxSubscriptions {
+		return errors.Errorf("at most %d subscriptions may be made over a socket", maxSubscriptions)
+	}
+
+	topic := string(v.GetStringBytes("topic"))
+
+	sink, exists := c.sinks(topic)
+	if !exists {
+		return errors.Errorf("unknown topic %q", topic)
+	}
 
+	query := make(map[string]string)
+
+	if filters := v.Get("filters"); filters != nil {
+		obj, err := filters.Object()
+		if err != nil {
+			return errors.Wrap(err, "invalid filters")
+		}
+
+		obj.Visit(func(key []byte, v *fastjson.Value) {
+			if v.Type() == fastjson.TypeString {
+				query[string(key)] = string(v.GetStringBytes())
+			} else {
+				query[string(key)] = string(v.MarshalTo(nil))
+			}
+		})
+	}
+
+	for key := range query {
+		if _, exists := sink.filters[key]; !exists {
+			return errors.Errorf("unknown filter %q for topic %q", key, topic)
+		}
+	}
+
+	since := int64(-1)
+
+	if v.Exists("since") {
+		seq, err := v.Get("since").Int64()
+		if err != nil || seq < 0 {
+			return errors.New("since must be a sequence number")
+		}
+
+		since = seq
+	}
+
+	sub := &subscription{
+		id:     id,
+		client: c,
+		sink:   sink,
+		filters: sink.clientFilters(func(queryKey string) string {
+			return query[queryKey]
+		}),
+	}
+
+	c.subs[id] = sub
+
+	sink.join(sub, since, true)
+
+	return nil
+}
+
+// reply queues a message to the client, unless the client has stopped writing.
+func (c *client) reply(msg []byte) {
+	c.queue.pushReply(msg)
+}
+
+// wsReply builds a message replying to the client.
+func wsReply(op, id string, fields func(arena *fastjson.Arena, o *fastjson.Value)) []byte {
+	var arena fastjson.Arena
+
+	o := arena.NewObject()
+	o.Set("op", arena.NewString(op))
+	o.Set("id", arena.NewString(id))
+
+	if fields != nil {
+		fields(&arena, o)
+	}
+
+	return o.MarshalTo(nil)
+}
+
+func (c *client) writeWorker() {
 	ticker := time.NewTicker(pingPeriod)
 
 	defer func() {
 		ticker.Stop()
+		c.queue.discard()
 		_ = c.conn.Close()
 	}()
 
 	for {
 		select {
-		case msg, ok := <-c.queue:
-			if !ok {
-				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				c.queue = nil
-
-				return
-			}
-
-			if len(msg) == 0 {
-				continue
-			}
-
-			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-
-			err := c.conn.WriteMessage(websocket.TextMessage, msg)
-			if err != nil {
-				return
+		case <-c.queue.ready:
+			for {
+				msg, ok := c.queue.pop()
+				if !ok {
+					_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+					_ = c.conn.WriteMessage(websocket.CloseMessage, c.queue.closeMessage())
+
+					return
+				}
+
+				if msg == nil {
+					break
+				}
+
+				if len(msg) == 0 {
+					continue
+				}
+
+				_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+				err := c.conn.WriteMessage(websocket.TextMessage, msg)
+				if err != nil {
+					return
+				}
 			}
 		case <-ticker.C:
 			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
@@ -113,90 +292,278 @@ func (c *client) writeWorker() {
 	}
 }
 
-func (s *sink) serve(ctx *fasthttp.RequestCtx) error {
-	values := ctx.QueryArgs()
-
+// clientFilters maps the values of the query parameters a client is to filter events by to the
+// keys of the events they filter.
+func (s *sink) clientFilters(query func(queryKey string) string) map[string]string {
 	filters := make(map[string]string)
 	for queryKey, key := range s.filters {
-		if queryValue := values.Peek(queryKey); len(queryValue) > 0 {
-			filters[key] = string(queryValue)
+		if queryValue := query(queryKey); len(queryValue) > 0 {
+			filters[key] = queryValue
 		}
 	}
 
+	return filters
+}
+
+// serve upgrades a request to a websocket whose events are queued to queue. If the sink is not
+// nil, the socket starts out subscribed to the sink with the filters, and the sequence number to
+// resume after, given as query parameters.
+func (s *sink) serve(ctx *fasthttp.RequestCtx, sinks func(topic string) (*sink, bool), queue *clientQueue) error {
+	values := ctx.QueryArgs()
+
+	since, err := parseSince(values.Peek("since"))
+	if err != nil {
+		return err
+	}
+
 	return upgrader.Upgrade(ctx, func(conn *websocket.Conn) {
-		client := &client{
-			filters: filters,
-			sink:    s,
-			conn:    conn,
-			queue:   make(chan []byte, 256),
-			done:    make(chan struct{}),
-		}
+		client := newClient(conn, sinks, queue)
+
+		if s != nil {
+			sub := &subscription{
+				client: client,
+				sink:   s,
+				filters: s.clientFilters(func(queryKey string) string {
+					return string(values.Peek(queryKey))
+				}),
+			}
 
-		s.join <- client
+			client.subs[sub.id] = sub
+
+			// Clients which do not resume are not told of the subscription, as they may not
+			// speak the subscription protocol.
+			s.join(sub, since, since >= 0)
+		}
 
 		go client.readWorker()
 		client.writeWorker()
 	})
 }
 
+// subscribe sends every event broadcasted to the sink which passes the filters given, until ctx
+// is done or an event fails to be sent. Events are queued to queue until they are sent. It
+// returns errSlowClient should the subscriber be disconnected for being too slow.
+func (s *sink) subscribe(
+	ctx context.Context, filters map[string]string, queue *clientQueue, send func(buf []byte) error,
+) error {
+	sub := &subscription{
+		client:  newClient(nil, nil, queue),
+		sink:    s,
+		filters: filters,
+	}
+
+	s.join(sub, -1, false)
+
+	defer func() {
+		s.leave(sub)
+		queue.discard()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-queue.ready:
+			for {
+				msg, ok := queue.pop()
+				if !ok {
+					return queue.err()
+				}
+
+				if msg == nil {
+					break
+				}
+
+				if len(msg) == 0 {
+					continue
+				}
+
+				if err := send(msg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
 type broadcastItem struct {
+	seq   uint64
 	buf   []byte
+	key   string
 	value *fastjson.Value
 }
 
+// sink broadcasts events of a module to the clients subscribed to it. Every event is numbered in
+// sequence, and the latest events are buffered so that clients may resume after reconnecting.
 type sink struct {
-	ops     chan func(map[*client]struct{})
+	name    string
+	ops     chan func(map[*subscription]struct{})
 	filters map[string]string
 
-	join, leave chan *client
+	// keys are the keys of the events filtered by, in order.
+	keys []string
+
+	// Only accessed by run.
+	seq    uint64
+	replay []broadcastItem
 }
 
 func (s *sink) run() {
-	clients := make(map[*client]struct{})
+	subs := make(map[*subscription]struct{})
 
-	for {
-		select {
-		case client := <-s.join:
-			clients[client] = struct{}{}
-		case client := <-s.leave:
-			if _, ok := clients[client]; ok {
-				close(client.queue)
-				<-client.done
-
-				delete(clients, client)
+	for op := range s.ops {
+		op(subs)
+	}
+}
+
+// join subscribes sub to the sink. If since is not negative, the buffered events after the
+// sequence number since are replayed to the subscription. If ack is set, the client is told of
+// the subscription before any event is sent to it.
+func (s *sink) join(sub *subscription, since int64, ack bool) {
+	done := make(chan struct{})
+
+	s.ops <- func(subs map[*subscription]struct{}) {
+		defer close(done)
+
+		subs[sub] = struct{}{}
+
+		// Every event after since may only be replayed if the oldest buffered event directly follows it.
+		resumed := since >= 0 && uint64(since) <= s.seq &&
+			(len(s.replay) == 0 || s.replay[0].seq <= uint64(since)+1)
+
+		if ack {
+			reply := wsReply("subscribed", sub.id, func(arena *fastjson.Arena, o *fastjson.Value) {
+				o.Set("topic", arena.NewString(s.name))
+				o.Set("seq", arena.NewNumberString(strconv.FormatUint(s.seq, 10)))
+
+				if since >= 0 {
+					o.Set("resumed", fastjsonBool(arena, resumed))
+				}
+			})
+
+			sub.client.queue.pushReply(reply)
+		}
+
+		if since < 0 {
+			return
+		}
+
+		for _, item := range s.replay {
+			if item.seq > uint64(since) {
+				s.send(sub, item)
 			}
-		case op := <-s.ops:
-			op(clients)
 		}
 	}
+
+	<-done
 }
 
-func (s *sink) doSend(clients map[*client]struct{}, buf []byte, bufVal *fastjson.Value) {
-SENDING:
-	for c := range clients {
-		for key, condition := range c.filters {
-			val := bufVal.Get(key)
+// leave unsubscribes sub from the sink. No event is sent to the subscription once leave returns.
+func (s *sink) leave(sub *subscription) {
+	done := make(chan struct{})
 
-			if val == nil {
-				continue SENDING
-			}
+	s.ops <- func(subs map[*subscription]struct{}) {
+		delete(subs, sub)
+		close(done)
+	}
+
+	<-done
+}
+
+func (s *sink) doSend(subs map[*subscription]struct{}, item broadcastItem) {
+	// A client may have several subscriptions matching an event, but is sent the event once.
+	var sent map[*client]struct{}
 
-			if !fastjsonEquals(val, condition) {
-				continue SENDING
+	if len(subs) > 1 {
+		sent = make(map[*client]struct{}, len(subs))
+	}
+
+	for sub := range subs {
+		if sent != nil {
+			if _, ok := sent[sub.client]; ok {
+				continue
 			}
 		}
 
-		select {
-		case c.queue <- buf:
-		default:
+		if s.send(sub, item) && sent != nil {
+			sent[sub.client] = struct{}{}
 		}
 	}
 }
 
+// send queues an event to a subscription if it passes the filters of the subscription. A client
+// too slow to keep up has events dropped from its queue, rather than stall the sink.
+func (s *sink) send(sub *subscription, item broadcastItem) bool {
+	if !sub.matches(item.value) {
+		return false
+	}
+
+	sub.client.queue.pushEvent(item.buf, item.key)
+
+	return true
+}
+
 func (s *sink) broadcast(item broadcastItem) {
-	s.ops <- func(clients map[*client]struct{}) {
-		s.doSend(clients, item.buf, item.value)
+	s.ops <- func(subs map[*subscription]struct{}) {
+		s.seq++
+
+		item.seq = s.seq
+		item.buf = withSeq(item.buf, item.seq)
+		item.key = s.eventKey(item.value)
+
+		if len(s.replay) == sinkReplaySize {
+			copy(s.replay, s.replay[1:])
+			s.replay = s.replay[:len(s.replay)-1]
+		}
+
+		s.replay = append(s.replay, item)
+
+		s.doSend(subs, item)
+	}
+}
+
+// eventKey identifies the kind of an event, and the values of the keys it may be filtered by, so
+// that a queued event may be coalesced with a newer event of the same kind about the same
+// account, contract or transaction.
+func (s *sink) eventKey(v *fastjson.Value) string {
+	key := make([]byte, 0, 64)
+	key = append(key, v.GetStringBytes(log.KeyEvent)...)
+
+	for _, k := range s.keys {
+		key = append(key, 0)
+
+		if val := v.Get(k); val != nil {
+			key = val.MarshalTo(key)
+		}
+	}
+
+	return string(key)
+}
+
+// withSeq adds the sequence number of an event as the first field of the event.
+func withSeq(buf []byte, seq uint64) []byte {
+	rest := bytes.TrimSpace(buf)
+	if len(rest) > 0 && rest[0] == '{' {
+		rest = bytes.TrimSpace(rest[1:])
+	}
+
+	out := make([]byte, 0, len(rest)+32)
+	out = append(out, `{"seq":`...)
+	out = strconv.AppendUint(out, seq, 10)
+
+	if len(rest) > 0 && rest[0] != '}' {
+		out = append(out, ',')
 	}
+
+	return append(out, rest...)
+}
+
+func fastjsonBool(arena *fastjson.Arena, b bool) *fastjson.Value {
+	if b {
+		return arena.NewTrue()
+	}
+
+	return arena.NewFalse()
 }
 
 func fastjsonEquals(v *fastjson.Value, filter string) bool {
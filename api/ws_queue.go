@@ -0,0 +1,270 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package api
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/fasthttp/websocket"
+	"github.com/perlin-network/wavelet"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/pkg/errors"
+	"github.com/valyala/fastjson"
+)
+
+var errSlowClient = errors.New("client is too slow to keep up with events")
+
+// clientQueue is a bounded queue of the messages to be written to a client. Once the queue is
+// full, events are dropped, coalesced or have the client disconnected according to a policy, so
+// that a slow client may neither stall broadcasts nor grow its queue without bound. Replies to
+// the client are never dropped.
+type clientQueue struct {
+	sync.Mutex
+
+	policy  string
+	size    int
+	metrics *wavelet.Metrics
+
+	msgs   []queuedMessage
+	events int
+
+	// dropped is the number of events dropped since the client was last told of it.
+	dropped uint64
+
+	// onDrop, should it be set, is handed every event dropped from the queue, in place of the
+	// client being told how many were. It is called with the queue locked.
+	onDrop func(buf []byte)
+
+	closed     bool
+	overflowed bool
+
+	// ready is signalled whenever a message is queued, or the queue is closed.
+	ready chan struct{}
+}
+
+type queuedMessage struct {
+	buf []byte
+
+	// key identifies the kind of an event, and the account, contract or transaction it is
+	// about. It is empty for replies.
+	key   string
+	event bool
+}
+
+// newClientQueue creates a queue of at most size events, which are handled according to
+// policy once the queue is full. The metrics may be nil.
+func newClientQueue(policy string, size int, metrics *wavelet.Metrics) *clientQueue {
+	return &clientQueue{
+		policy:  policy,
+		size:    size,
+		metrics: metrics,
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// pushEvent queues an event whose kind is identified by key. It returns false if the queue is
+// closed, or the client was disconnected for being too slow.
+func (q *clientQueue) pushEvent(buf []byte, key string) bool {
+	return q.push(queuedMessage{buf: buf, key: key, event: true})
+}
+
+// pushReply queues a reply to the client. It returns false if the queue is closed.
+func (q *clientQueue) pushReply(buf []byte) bool {
+	return q.push(queuedMessage{buf: buf})
+}
+
+func (q *clientQueue) push(msg queuedMessage) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.closed {
+		return false
+	}
+
+	if msg.event && q.events >= q.size {
+		switch q.policy {
+		case conf.WebsocketDisconnect:
+			q.overflowed = true
+			q.discardLocked()
+
+			if q.metrics != nil {
+				q.metrics.MarkWebsocketDisconnected()
+			}
+
+			q.signal()
+
+			return false
+		case conf.WebsocketCoalesce:
+			if !q.removeLocked(func(queued queuedMessage) bool { return queued.key == msg.key }) {
+				q.removeLocked(func(queued queuedMessage) bool { return true })
+			}
+		default:
+			q.removeLocked(func(queued queuedMessage) bool { return true })
+		}
+
+		// Should the queue hold no events at all, the event itself is dropped.
+		if q.events >= q.size {
+			q.dropLocked(msg)
+			return true
+		}
+	}
+
+	q.msgs = append(q.msgs, msg)
+
+	if msg.event {
+		q.events++
+
+		if q.metrics != nil {
+			q.metrics.UpdateWebsocketQueued(1)
+		}
+	}
+
+	q.signal()
+
+	return true
+}
+
+// removeLocked drops the oldest queued event which passes the predicate.
+func (q *clientQueue) removeLocked(predicate func(queued queuedMessage) bool) bool {
+	for i, queued := range q.msgs {
+		if !queued.event || !predicate(queued) {
+			continue
+		}
+
+		q.msgs = append(q.msgs[:i], q.msgs[i+1:]...)
+		q.events--
+
+		if q.metrics != nil {
+			q.metrics.UpdateWebsocketQueued(-1)
+		}
+
+		q.dropLocked(queued)
+
+		return true
+	}
+
+	return false
+}
+
+// dropLocked accounts for an event being dropped from the queue.
+func (q *clientQueue) dropLocked(msg queuedMessage) {
+	if q.metrics != nil {
+		q.metrics.MarkWebsocketDropped(1)
+	}
+
+	if q.onDrop != nil {
+		q.onDrop(msg.buf)
+		return
+	}
+
+	q.dropped++
+}
+
+func (q *clientQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop dequeues the next message to be written to the client. Should events have been dropped,
+// the client is first sent a notice of how many were. It returns a nil message if the queue is
+// empty, and false once the queue is closed and empty.
+func (q *clientQueue) pop() ([]byte, bool) {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.dropped > 0 {
+		dropped := q.dropped
+		q.dropped = 0
+
+		return wsReply("dropped", "", func(arena *fastjson.Arena, o *fastjson.Value) {
+			o.Set("count", arena.NewNumberString(strconv.FormatUint(dropped, 10)))
+		}), true
+	}
+
+	if len(q.msgs) == 0 {
+		return nil, !q.closed
+	}
+
+	msg := q.msgs[0]
+
+	q.msgs[0] = queuedMessage{}
+	q.msgs = q.msgs[1:]
+
+	if msg.event {
+		q.events--
+
+		if q.metrics != nil {
+			q.metrics.UpdateWebsocketQueued(-1)
+		}
+	}
+
+	return msg.buf, true
+}
+
+// close closes the queue once the messages already queued are written.
+func (q *clientQueue) close() {
+	q.Lock()
+	q.closed = true
+	q.Unlock()
+
+	q.signal()
+}
+
+// discard closes the queue, dropping every message queued.
+func (q *clientQueue) discard() {
+	q.Lock()
+	q.discardLocked()
+	q.Unlock()
+}
+
+func (q *clientQueue) discardLocked() {
+	if q.metrics != nil && q.events > 0 {
+		q.metrics.UpdateWebsocketQueued(-int64(q.events))
+	}
+
+	q.msgs = nil
+	q.events = 0
+	q.dropped = 0
+	q.closed = true
+}
+
+// err returns errSlowClient if the client was disconnected for being too slow.
+func (q *clientQueue) err() error {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.overflowed {
+		return errSlowClient
+	}
+
+	return nil
+}
+
+// closeMessage returns the message the socket of a client is closed with once its queue is.
+func (q *clientQueue) closeMessage() []byte {
+	if err := q.err(); err != nil {
+		return websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error())
+	}
+
+	return []byte{}
+}
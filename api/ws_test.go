@@ -22,9 +22,14 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
+	"github.com/perlin-network/wavelet"
+	"github.com/perlin-network/wavelet/conf"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/valyala/fastjson"
 )
 
@@ -47,3 +52,211 @@ func TestSinkEqual(t *testing.T) {
 	assert.True(t, fastjsonEquals(v.Get("obj"), `{"key":"value"}`))
 	assert.True(t, fastjsonEquals(v.Get("arr"), `[1,"str"]`))
 }
+
+func TestWithSeq(t *testing.T) {
+	assert.Equal(t, `{"seq":1,"mod":"tx"}`, string(withSeq([]byte(`{"mod":"tx"}`), 1)))
+	assert.Equal(t, `{"seq":2,"mod":"tx"}`, string(withSeq([]byte(" {\"mod\":\"tx\"}\n"), 2)))
+	assert.Equal(t, `{"seq":3}`, string(withSeq([]byte(`{}`), 3)))
+}
+
+func TestSinkReplay(t *testing.T) {
+	g := New()
+	s := g.registerWebsocketSink("ws://accounts/?id=account_id")
+
+	write := func(id string) {
+		_, err := g.Write([]byte(`{"mod":"accounts","account_id":"` + id + `"}`))
+		require.NoError(t, err)
+	}
+
+	join := func(since int64, filters map[string]string) *subscription {
+		queue := newClientQueue(conf.WebsocketDropOldest, 256, nil)
+		sub := &subscription{id: "a", client: newClient(nil, nil, queue), sink: s, filters: filters}
+		s.join(sub, since, true)
+
+		return sub
+	}
+
+	next := func(sub *subscription) string {
+		msg, _ := sub.client.queue.pop()
+		return string(msg)
+	}
+
+	live := join(-1, map[string]string{"account_id": "bb"})
+
+	write("aa")
+	write("bb")
+
+	assert.Equal(t, `{"op":"subscribed","id":"a","topic":"accounts","seq":0}`, next(live))
+	assert.Equal(t, `{"seq":2,"mod":"accounts","account_id":"bb"}`, next(live))
+	assert.Equal(t, "", next(live))
+
+	s.leave(live)
+	write("bb")
+	assert.Equal(t, "", next(live))
+
+	// Resume after the first event.
+	resumed := join(1, nil)
+	assert.Equal(t, `{"op":"subscribed","id":"a","topic":"accounts","seq":3,"resumed":true}`, next(resumed))
+	assert.Equal(t, `{"seq":2,"mod":"accounts","account_id":"bb"}`, next(resumed))
+	assert.Equal(t, `{"seq":3,"mod":"accounts","account_id":"bb"}`, next(resumed))
+	assert.Equal(t, "", next(resumed))
+
+	// Resuming after events which are no longer buffered replays what is left, but is reported.
+	for i := 0; i < sinkReplaySize; i++ {
+		write(fmt.Sprint(i))
+	}
+
+	gap := join(1, map[string]string{"account_id": "0"})
+	assert.Equal(t,
+		fmt.Sprintf(`{"op":"subscribed","id":"a","topic":"accounts","seq":%d,"resumed":false}`, 3+sinkReplaySize),
+		next(gap),
+	)
+	assert.Equal(t, `{"seq":4,"mod":"accounts","account_id":"0"}`, next(gap))
+
+	// Resuming after a sequence number the sink has not reached is reported.
+	future := join(1000, nil)
+	assert.Contains(t, next(future), `"resumed":false`)
+}
+
+func TestClientQueue(t *testing.T) {
+	pop := func(q *clientQueue) string {
+		msg, _ := q.pop()
+		return string(msg)
+	}
+
+	t.Run("drop oldest", func(t *testing.T) {
+		q := newClientQueue(conf.WebsocketDropOldest, 2, nil)
+
+		assert.True(t, q.pushEvent([]byte("1"), "a"))
+		assert.True(t, q.pushReply([]byte("reply")))
+		assert.True(t, q.pushEvent([]byte("2"), "a"))
+		assert.True(t, q.pushEvent([]byte("3"), "b"))
+		assert.True(t, q.pushEvent([]byte("4"), "b"))
+
+		assert.Equal(t, `{"op":"dropped","id":"","count":2}`, pop(q))
+		assert.Equal(t, "reply", pop(q))
+		assert.Equal(t, "3", pop(q))
+		assert.Equal(t, "4", pop(q))
+		assert.Equal(t, "", pop(q))
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		q := newClientQueue(conf.WebsocketCoalesce, 3, nil)
+
+		assert.True(t, q.pushEvent([]byte("a1"), "a"))
+		assert.True(t, q.pushEvent([]byte("b1"), "b"))
+		assert.True(t, q.pushEvent([]byte("c1"), "c"))
+		assert.True(t, q.pushEvent([]byte("b2"), "b"))
+
+		// Without a queued event of the same kind, the oldest event is dropped.
+		assert.True(t, q.pushEvent([]byte("d1"), "d"))
+
+		assert.Equal(t, `{"op":"dropped","id":"","count":2}`, pop(q))
+		assert.Equal(t, "c1", pop(q))
+		assert.Equal(t, "b2", pop(q))
+		assert.Equal(t, "d1", pop(q))
+		assert.Equal(t, "", pop(q))
+	})
+
+	t.Run("drop handler", func(t *testing.T) {
+		q := newClientQueue(conf.WebsocketDropOldest, 2, nil)
+
+		var dropped []string
+
+		q.onDrop = func(buf []byte) {
+			dropped = append(dropped, string(buf))
+		}
+
+		assert.True(t, q.pushEvent([]byte("1"), "a"))
+		assert.True(t, q.pushEvent([]byte("2"), "a"))
+		assert.True(t, q.pushEvent([]byte("3"), "a"))
+
+		// Dropped events are handed to the handler, rather than announced to the client.
+		assert.Equal(t, []string{"1"}, dropped)
+		assert.Equal(t, "2", pop(q))
+		assert.Equal(t, "3", pop(q))
+		assert.Equal(t, "", pop(q))
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		q := newClientQueue(conf.WebsocketDisconnect, 1, nil)
+
+		assert.True(t, q.pushEvent([]byte("1"), "a"))
+		assert.False(t, q.pushEvent([]byte("2"), "a"))
+		assert.False(t, q.pushReply([]byte("reply")))
+
+		msg, ok := q.pop()
+		assert.Nil(t, msg)
+		assert.False(t, ok)
+		assert.Equal(t, errSlowClient, q.err())
+	})
+
+	t.Run("close", func(t *testing.T) {
+		q := newClientQueue(conf.WebsocketDropOldest, 1, nil)
+
+		assert.True(t, q.pushEvent([]byte("1"), "a"))
+		q.close()
+		assert.False(t, q.pushEvent([]byte("2"), "a"))
+
+		msg, ok := q.pop()
+		assert.Equal(t, "1", string(msg))
+		assert.True(t, ok)
+
+		msg, ok = q.pop()
+		assert.Nil(t, msg)
+		assert.False(t, ok)
+		assert.NoError(t, q.err())
+	})
+}
+
+func TestClientQueueMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	metrics := wavelet.NewMetrics(ctx)
+	defer metrics.Stop()
+
+	q := newClientQueue(conf.WebsocketDropOldest, 2, metrics)
+
+	for i := 0; i < 5; i++ {
+		q.pushEvent([]byte(fmt.Sprint(i)), "")
+	}
+
+	assert.EqualValues(t, 2, metrics.WebsocketQueued())
+	assert.EqualValues(t, 3, metrics.WebsocketDropped())
+
+	q.pop()
+	q.pop()
+	assert.EqualValues(t, 1, metrics.WebsocketQueued())
+
+	q.discard()
+	assert.EqualValues(t, 0, metrics.WebsocketQueued())
+
+	q = newClientQueue(conf.WebsocketDisconnect, 1, metrics)
+	q.pushEvent([]byte("1"), "")
+	q.pushEvent([]byte("2"), "")
+
+	assert.EqualValues(t, 0, metrics.WebsocketQueued())
+	assert.EqualValues(t, 1, metrics.WebsocketDisconnected())
+}
+
+func TestSinkEventKey(t *testing.T) {
+	g := New()
+	s := g.registerWebsocketSink("ws://tx/?id=tx_id&sender=sender_id")
+
+	var p fastjson.Parser
+
+	key := func(event string) string {
+		v, err := p.Parse(event)
+		require.NoError(t, err)
+
+		return s.eventKey(v)
+	}
+
+	assert.Equal(t,
+		key(`{"event":"applied","tx_id":"aa","sender_id":"bb","seq":1}`),
+		key(`{"event":"applied","sender_id":"bb","tx_id":"aa","seq":2}`),
+	)
+	assert.NotEqual(t, key(`{"event":"applied","tx_id":"aa"}`), key(`{"event":"rejected","tx_id":"aa"}`))
+	assert.NotEqual(t, key(`{"event":"applied","tx_id":"aa"}`), key(`{"event":"applied","tx_id":"ab"}`))
+}
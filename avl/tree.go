@@ -36,6 +36,8 @@ import (
 var NodeKeyPrefix = []byte("@1:")
 var GCAliveMarkPrefix = []byte("@2:")
 var OldRootsPrefix = []byte("@3:")
+var ViewRootsPrefix = []byte("@4:")
+var RecordedRootsPrefix = []byte("@5:")
 var RootKey = []byte(".root")
 var NextOldRootIndexKey = []byte(".next_old_root")
 var DiffsKeyPrefix = []byte("diffs:")
@@ -53,6 +55,8 @@ type Tree struct {
 	cache *nodeLRU
 
 	viewID uint64
+
+	archive bool
 }
 
 func New(kv store.KV) *Tree {
@@ -85,6 +89,13 @@ func (t *Tree) WithMaxWriteBatchSize(maxWriteBatchSize int) *Tree {
 	return t
 }
 
+// WithArchive makes the tree record the root of every view it commits, such that the
+// state of the tree at any committed view may later be loaded through SnapshotAt.
+func (t *Tree) WithArchive(archive bool) *Tree {
+	t.archive = archive
+	return t
+}
+
 func (t *Tree) Insert(key, value []byte) {
 	if t.root == nil {
 		t.root = newLeafNode(t, key, value)
@@ -113,7 +124,61 @@ func (t *Tree) Delete(k []byte) bool {
 }
 
 func (t *Tree) Snapshot() *Tree {
-	return &Tree{kv: t.kv, cache: t.cache, maxWriteBatchSize: t.maxWriteBatchSize, root: t.root}
+	return &Tree{kv: t.kv, cache: t.cache, maxWriteBatchSize: t.maxWriteBatchSize, root: t.root, archive: t.archive}
+}
+
+// SnapshotAt returns a snapshot of the tree as it was when the view viewID was committed.
+// Only views committed while archiving was enabled may be loaded.
+func (t *Tree) SnapshotAt(viewID uint64) (*Tree, error) {
+	id, ok := t.getViewRoot(viewID)
+	if !ok {
+		return nil, errors.Errorf("avl: no root was archived for view %d", viewID)
+	}
+
+	root, err := t.loadNode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tree{
+		kv:                t.kv,
+		cache:             t.cache,
+		maxWriteBatchSize: t.maxWriteBatchSize,
+		root:              root,
+		viewID:            viewID,
+		archive:           t.archive,
+	}, nil
+}
+
+// SnapshotWithRoot returns a snapshot of the tree whose root has the given merkle root. Besides
+// the current root, only roots an archiving tree was committed with may be loaded, as the nodes
+// of older roots may otherwise be garbage collected while the snapshot is still in use.
+func (t *Tree) SnapshotWithRoot(id [MerkleHashSize]byte) (*Tree, error) {
+	if t.root != nil && t.root.id == id {
+		return t.Snapshot(), nil
+	}
+
+	if !t.archive {
+		return nil, errors.Errorf("avl: %x is not the current root of the tree, and roots are not archived", id)
+	}
+
+	if _, err := t.kv.Get(append(RecordedRootsPrefix, id[:]...)); err != nil {
+		return nil, errors.Errorf("avl: %x was never committed as the root of the tree", id)
+	}
+
+	root, err := t.loadNode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tree{
+		kv:                t.kv,
+		cache:             t.cache,
+		maxWriteBatchSize: t.maxWriteBatchSize,
+		root:              root,
+		viewID:            root.viewID,
+		archive:           t.archive,
+	}, nil
 }
 
 func (t *Tree) Revert(snapshot *Tree) {
@@ -229,6 +294,16 @@ func (t *Tree) Commit() error {
 		}
 	}
 
+	if t.archive {
+		if err := t.setViewRoot(t.viewID, t.root.id[:]); err != nil {
+			return errors.Wrap(err, "failed to archive root")
+		}
+
+		if err := t.kv.Put(append(RecordedRootsPrefix, t.root.id[:]...), []byte{1}); err != nil {
+			return errors.Wrap(err, "failed to record root")
+		}
+	}
+
 	return t.kv.Put(RootKey, t.root.id[:])
 }
 
@@ -279,6 +354,31 @@ func (t *Tree) deleteOldRoot(idx uint64) {
 	_ = t.kv.Delete(append(OldRootsPrefix, buf[:]...))
 }
 
+func (t *Tree) getViewRoot(viewID uint64) ([MerkleHashSize]byte, bool) {
+	var buf [8]byte
+
+	binary.BigEndian.PutUint64(buf[:], viewID)
+
+	out, err := t.kv.Get(append(ViewRootsPrefix, buf[:]...))
+	if err != nil || len(out) != MerkleHashSize {
+		return [MerkleHashSize]byte{}, false
+	}
+
+	var ret [MerkleHashSize]byte
+
+	copy(ret[:], out)
+
+	return ret, true
+}
+
+func (t *Tree) setViewRoot(viewID uint64, value []byte) error {
+	var buf [8]byte
+
+	binary.BigEndian.PutUint64(buf[:], viewID)
+
+	return t.kv.Put(append(ViewRootsPrefix, buf[:]...), value)
+}
+
 func (t *Tree) Checksum() [MerkleHashSize]byte {
 	if t.root == nil {
 		return [MerkleHashSize]byte{}
@@ -287,6 +387,58 @@ func (t *Tree) Checksum() [MerkleHashSize]byte {
 	return t.root.id
 }
 
+// Verify walks the tree committed to kv from its root, and checks that every node of the tree is
+// stored and hashes to the ID it is stored under. As the ID of a node is a hash over the IDs of
+// its children, this verifies the merkle root of the tree against its contents. It returns the
+// merkle root of the tree, which is zero should no tree have been committed to kv.
+func Verify(kv store.KV) ([MerkleHashSize]byte, error) {
+	var root [MerkleHashSize]byte
+
+	buf, err := kv.Get(RootKey)
+	if err != nil {
+		if errors.Cause(err) == store.ErrNotFound {
+			return root, nil
+		}
+
+		return root, errors.Wrap(err, "avl: failed to load root")
+	}
+
+	if len(buf) != MerkleHashSize {
+		return root, errors.Errorf("avl: root is %d bytes long, but should be %d", len(buf), MerkleHashSize)
+	}
+
+	copy(root[:], buf)
+
+	// Nodes are loaded by their IDs rather than cached, such that only the IDs of the nodes
+	// left to be visited are kept in memory.
+	stack := [][MerkleHashSize]byte{root}
+
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		buf, err := kv.Get(append(NodeKeyPrefix, id[:]...))
+		if err != nil || len(buf) == 0 {
+			return root, errors.Errorf("avl: could not find node %x", id)
+		}
+
+		n, err := deserialize(bytes.NewReader(buf))
+		if err != nil {
+			return root, errors.Wrapf(err, "avl: failed to decode node %x", id)
+		}
+
+		if n.id != id {
+			return root, errors.Errorf("avl: node %x hashes to %x", id, n.id)
+		}
+
+		if n.kind != NodeLeafValue {
+			stack = append(stack, n.right, n.left)
+		}
+	}
+
+	return root, nil
+}
+
 func (t *Tree) loadNode(id [MerkleHashSize]byte) (*node, error) {
 	if n, ok := t.cache.Load(id); ok {
 		return n, nil
@@ -370,12 +522,22 @@ func (t *Tree) deleteNodeAndMetadata(id [MerkleHashSize]byte) {
 	t.cache.Remove(id)
 	_ = t.kv.Delete(append(NodeKeyPrefix, id[:]...))
 	_ = t.kv.Delete(append(GCAliveMarkPrefix, id[:]...))
+	_ = t.kv.Delete(append(RecordedRootsPrefix, id[:]...))
 }
 
 func (t *Tree) SetViewID(viewID uint64) {
 	t.viewID = viewID
 }
 
+// RootViewID returns the view under which the root of the tree was last modified.
+func (t *Tree) RootViewID() uint64 {
+	if t.root == nil {
+		return 0
+	}
+
+	return t.root.viewID
+}
+
 func (t *Tree) iterateDiff(prevViewID uint64, callback func(n *node) bool) {
 	var stack queue.Queue
 
@@ -444,6 +606,82 @@ func (t *Tree) IterateLeafDiff(prevViewID uint64, callback func(key, value []byt
 	})
 }
 
+// IterateLeafChanges calls callback with the key of every leaf of either tree which is not shared
+// by the other tree, such that every key that was inserted, updated or deleted between both trees
+// is visited at least once. Subtrees shared by both trees are skipped by their merkle IDs, which
+// makes it cheap to compare trees that are not far apart.
+func IterateLeafChanges(a, b *Tree, callback func(key []byte) bool) {
+	since := a.RootViewID()
+	if view := b.RootViewID(); view < since {
+		since = view
+	}
+
+	stopped := false
+
+	walk := func(t *Tree, skip func(n *node) bool, leaf func(n *node) bool) {
+		if t.root == nil || stopped {
+			return
+		}
+
+		var stack queue.Queue
+
+		stack.PushBack(t.root)
+
+		for stack.Len() > 0 {
+			current := stack.PopBack().(*node)
+
+			if skip(current) {
+				continue
+			}
+
+			if current.kind == NodeLeafValue {
+				if !leaf(current) {
+					stopped = true
+					return
+				}
+
+				continue
+			}
+
+			stack.PushBack(t.mustLoadRight(current))
+			stack.PushBack(t.mustLoadLeft(current))
+		}
+	}
+
+	// Collect the nodes of b modified after both trees last had a common view, alongside the
+	// roots of the subtrees of b left untouched since.
+	inB := make(map[[MerkleHashSize]byte]struct{})
+
+	walk(b, func(n *node) bool {
+		inB[n.id] = struct{}{}
+		return n.viewID <= since
+	}, func(*node) bool {
+		return true
+	})
+
+	// Visit all leaves of a outside of subtrees shared with b. Should a and b have diverged before
+	// their common view, more of a is visited, though no changes are missed.
+	inA := make(map[[MerkleHashSize]byte]struct{})
+
+	walk(a, func(n *node) bool {
+		inA[n.id] = struct{}{}
+		_, shared := inB[n.id]
+
+		return shared
+	}, func(n *node) bool {
+		return callback(n.key)
+	})
+
+	// Visit all leaves of b outside of subtrees shared with a. Any subtree of b shared with a
+	// is rooted at a node visited while walking a.
+	walk(b, func(n *node) bool {
+		_, shared := inA[n.id]
+		return shared
+	}, func(n *node) bool {
+		return callback(n.key)
+	})
+}
+
 func (t *Tree) ApplyDiffWithUpdateNotifier(diff io.Reader, updateNotifier func(key, value []byte)) error {
 	// Deserialize header
 	var nodeCount uint64
@@ -160,6 +160,157 @@ func TestTree_Snapshot(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestTree_SnapshotAt(t *testing.T) {
+	kv, cleanup, err := store.NewTestKV("level", "db")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	defer cleanup()
+
+	tree := New(kv).WithArchive(true)
+
+	for i := uint64(0); i < 3; i++ {
+		tree.SetViewID(i)
+		tree.Insert([]byte("k"), []byte{byte(i)})
+		assert.NoError(t, tree.Commit())
+	}
+
+	for i := uint64(0); i < 3; i++ {
+		ss, err := tree.SnapshotAt(i)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		v, ok := ss.Lookup([]byte("k"))
+		assert.True(t, ok)
+		assert.EqualValues(t, []byte{byte(i)}, v)
+	}
+
+	_, err = tree.SnapshotAt(3)
+	assert.Error(t, err)
+
+	// Roots are not recorded for views committed without archiving.
+	tree.WithArchive(false)
+	tree.SetViewID(3)
+	tree.Insert([]byte("k"), []byte{3})
+	assert.NoError(t, tree.Commit())
+
+	_, err = tree.SnapshotAt(3)
+	assert.Error(t, err)
+}
+
+func TestTree_SnapshotWithRoot(t *testing.T) {
+	kv, cleanup, err := store.NewTestKV("level", "db")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	defer cleanup()
+
+	tree := New(kv)
+
+	for i := 0; i < 8; i++ {
+		tree.SetViewID(uint64(i))
+		tree.Insert([]byte{byte(i)}, []byte{byte(i)})
+	}
+
+	assert.NoError(t, tree.Commit())
+
+	old := tree.Checksum()
+
+	tree.Insert([]byte("k"), []byte("v"))
+	assert.NoError(t, tree.Commit())
+
+	latest := tree.Checksum()
+
+	ss, err := tree.SnapshotWithRoot(latest)
+	if assert.NoError(t, err) {
+		assert.Equal(t, latest, ss.Checksum())
+	}
+
+	// Older roots are not recorded, as their nodes may be garbage collected.
+	_, err = tree.SnapshotWithRoot(old)
+	assert.Error(t, err)
+
+	_, err = kv.Get(append(RecordedRootsPrefix, latest[:]...))
+	assert.Error(t, err)
+
+	tree.WithArchive(true)
+
+	tree.Insert([]byte("k"), []byte("w"))
+	assert.NoError(t, tree.Commit())
+
+	archived := tree.Checksum()
+
+	tree.Insert([]byte("k"), []byte("x"))
+	assert.NoError(t, tree.Commit())
+
+	ss, err = tree.SnapshotWithRoot(archived)
+	if assert.NoError(t, err) {
+		assert.Equal(t, archived, ss.Checksum())
+
+		value, exists := ss.Lookup([]byte("k"))
+		assert.True(t, exists)
+		assert.Equal(t, []byte("w"), value)
+	}
+
+	// Nodes within the tree are stored, but were never committed as its root.
+	_, err = tree.SnapshotWithRoot(tree.root.left)
+	assert.Error(t, err)
+
+	// Neither are roots that were never committed.
+	tree.Insert([]byte("k"), []byte("y"))
+	uncommitted := tree.Checksum()
+	tree.Insert([]byte("k"), []byte("z"))
+
+	_, err = tree.SnapshotWithRoot(uncommitted)
+	assert.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	kv := store.NewInmem()
+
+	root, err := Verify(kv)
+	if assert.NoError(t, err) {
+		assert.Equal(t, [MerkleHashSize]byte{}, root, "no tree was committed")
+	}
+
+	tree := New(kv)
+
+	for i := 0; i < 64; i++ {
+		tree.Insert([]byte{byte(i)}, []byte{byte(i)})
+	}
+
+	assert.NoError(t, tree.Commit())
+
+	root, err = Verify(kv)
+	if assert.NoError(t, err) {
+		assert.Equal(t, tree.Checksum(), root)
+	}
+
+	key := append(NodeKeyPrefix, tree.root.right[:]...)
+
+	buf, err := kv.Get(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Flip the last byte of the node, which encodes its size.
+	corrupt := append([]byte{}, buf...)
+	corrupt[len(corrupt)-1]++
+
+	assert.NoError(t, kv.Put(key, corrupt))
+
+	_, err = Verify(kv)
+	assert.Error(t, err)
+
+	assert.NoError(t, kv.Delete(key))
+
+	_, err = Verify(kv)
+	assert.Error(t, err)
+}
+
 func TestTree_Diff_Randomized(t *testing.T) {
 	kv, cleanup, err := store.NewTestKV("level", "db")
 	if !assert.NoError(t, err) {
@@ -30,7 +30,7 @@ import (
 )
 
 func runAccountsBenchmark() {
-	dbs := []string{"badger", "level"}
+	dbs := []string{"badger", "level", "bbolt"}
 
 	sizes := []int{
 		1, 2, 3, 4, 5, 6, 7, 8, 9, 10,
@@ -30,7 +30,7 @@ import (
 )
 
 func runTreeBenchmark() {
-	dbs := []string{"badger", "level"}
+	dbs := []string{"badger", "level", "bbolt"}
 
 	sizes := []int{
 		1, 3, 12, 46, 168, 607, 2188, 7886, 28418, 102399, // 1KB to 100MB
@@ -16,6 +16,8 @@ type Blocks struct {
 	latest uint32
 	oldest uint32
 	limit  uint8
+
+	archive bool
 }
 
 func NewBlocks(store store.KV, limit uint8) (*Blocks, error) {
@@ -37,6 +39,14 @@ func NewBlocks(store store.KV, limit uint8) (*Blocks, error) {
 	return r, nil
 }
 
+// WithArchive makes blocks persist every finalized block by its height, such that blocks which
+// have been evicted from the ring of recent blocks may still be retrieved through GetByIndex.
+func (b *Blocks) WithArchive(archive bool) *Blocks {
+	b.archive = archive
+
+	return b
+}
+
 func (b *Blocks) Oldest() *Block {
 	b.RLock()
 	block := b.buffer[b.oldest]
@@ -80,6 +90,9 @@ func (b *Blocks) Save(block *Block) (*Block, error) {
 	}
 
 	err := StoreBlock(b.store, *block, b.latest, b.oldest, uint8(len(b.buffer)))
+	if err == nil && b.archive {
+		err = StoreArchivedBlock(b.store, *block)
+	}
 
 	b.Unlock()
 
@@ -98,6 +111,12 @@ func (b *Blocks) GetByIndex(ix uint64) (*Block, error) {
 	}
 	b.RUnlock()
 
+	if block == nil && b.archive {
+		if archived, err := LoadArchivedBlock(b.store, ix); err == nil {
+			block = archived
+		}
+	}
+
 	if block == nil {
 		return nil, fmt.Errorf("no block found for index - %d", ix)
 	}
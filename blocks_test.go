@@ -100,3 +100,51 @@ func TestBlocksCircular(t *testing.T) {
 		assert.Equal(t, *blocks[i], *newBlocks[i])
 	}
 }
+
+func TestBlocksArchive(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewInmem()
+
+	b, _ := NewBlocks(storage, 10)
+	b.WithArchive(true)
+
+	var merkle MerkleNodeID
+	for i := 0; i < 15; i++ {
+		_, err := rand.Read(merkle[:])
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		tb := NewBlock(uint64(i+1), merkle, []TransactionID{}...)
+
+		_, err = b.Save(&tb)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	assert.Equal(t, uint64(6), b.Oldest().Index)
+
+	// Blocks evicted from the ring are still available from the archive.
+	for i := uint64(1); i <= 15; i++ {
+		block, err := b.GetByIndex(i)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, i, block.Index)
+	}
+
+	_, err := b.GetByIndex(16)
+	assert.Error(t, err)
+
+	// Blocks evicted from the ring are not available without archiving.
+	newB, err := NewBlocks(storage, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = newB.GetByIndex(1)
+	assert.Error(t, err)
+}
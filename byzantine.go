@@ -0,0 +1,263 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit integration
+
+package wavelet
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/pkg/errors"
+)
+
+// ByzantineBehavior is a set of ways in which a ByzantineProtocol misbehaves when responding
+// to its peers.
+type ByzantineBehavior uint8
+
+const (
+	// ByzantineRandomBlocks answers queries with randomly generated blocks.
+	ByzantineRandomBlocks ByzantineBehavior = 1 << iota
+
+	// ByzantineConflictingBlocks answers queries with blocks containing the same transactions
+	// as the honest answer, but with a different Merkle root for every query.
+	ByzantineConflictingBlocks
+
+	// ByzantineWithholdTransactions answers requests to pull or sync transactions with none.
+	ByzantineWithholdTransactions
+
+	// ByzantineCorruptSync serves corrupted chunks of state to peers that are syncing.
+	ByzantineCorruptSync
+
+	// ByzantineInvalidGossip relays every transaction gossiped to it to all of its peers
+	// with a corrupted signature.
+	ByzantineInvalidGossip
+)
+
+// ByzantineProtocol wraps around the Protocol of a ledger, and misbehaves in responding to
+// peers based on a set of behaviors. Requests that are not affected by any of its behaviors
+// are handled honestly.
+type ByzantineProtocol struct {
+	*Protocol
+
+	behavior ByzantineBehavior
+
+	rngLock sync.Mutex
+	rng     *rand.Rand
+
+	queries uint64
+}
+
+func NewByzantineProtocol(protocol *Protocol, behavior ByzantineBehavior, seed int64) *ByzantineProtocol {
+	return &ByzantineProtocol{
+		Protocol: protocol,
+		behavior: behavior,
+		rng:      rand.New(rand.NewSource(seed)), // nolint:gosec
+	}
+}
+
+func (p *ByzantineProtocol) Behavior() ByzantineBehavior {
+	return p.behavior
+}
+
+func (p *ByzantineProtocol) Has(behavior ByzantineBehavior) bool {
+	return p.behavior&behavior != 0
+}
+
+// Queries returns the number of queries that peers have made to the node.
+func (p *ByzantineProtocol) Queries() uint64 {
+	return atomic.LoadUint64(&p.queries)
+}
+
+func (p *ByzantineProtocol) random(buf []byte) {
+	p.rngLock.Lock()
+	_, _ = p.rng.Read(buf)
+	p.rngLock.Unlock()
+}
+
+func (p *ByzantineProtocol) randomBlock(index uint64) Block {
+	var merkle MerkleNodeID
+	p.random(merkle[:])
+
+	ids := make([]TransactionID, 1+int(merkle[0]%4))
+	for i := range ids {
+		p.random(ids[i][:])
+	}
+
+	return NewBlock(index, merkle, ids...)
+}
+
+func (p *ByzantineProtocol) Gossip(ctx context.Context, req *GossipRequest) (*empty.Empty, error) {
+	res, err := p.Protocol.Gossip(ctx, req)
+	if err != nil || !p.Has(ByzantineInvalidGossip) {
+		return res, err
+	}
+
+	for _, buf := range req.Transactions {
+		tx, err := UnmarshalTransaction(bytes.NewReader(buf))
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			_ = p.GossipWithInvalidSignature(tx)
+		}()
+	}
+
+	return res, nil
+}
+
+func (p *ByzantineProtocol) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	atomic.AddUint64(&p.queries, 1)
+
+	if p.Has(ByzantineRandomBlocks) {
+		block := p.randomBlock(req.BlockIndex)
+		return &QueryResponse{Block: block.Marshal()}, nil
+	}
+
+	if !p.Has(ByzantineConflictingBlocks) {
+		return p.Protocol.Query(ctx, req)
+	}
+
+	// Always respond with a block, as a cached block would otherwise be reused.
+	res, err := p.Protocol.Query(ctx, &QueryRequest{BlockIndex: req.BlockIndex})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Block) == 0 {
+		block := p.randomBlock(req.BlockIndex)
+		return &QueryResponse{Block: block.Marshal()}, nil
+	}
+
+	honest, err := UnmarshalBlock(bytes.NewReader(res.Block))
+	if err != nil {
+		return nil, err
+	}
+
+	var merkle MerkleNodeID
+	p.random(merkle[:])
+
+	block := NewBlock(honest.Index, merkle, honest.Transactions...)
+
+	return &QueryResponse{Block: block.Marshal()}, nil
+}
+
+func (p *ByzantineProtocol) PullTransactions(
+	ctx context.Context, req *TransactionPullRequest,
+) (*TransactionPullResponse, error) {
+	if p.Has(ByzantineWithholdTransactions) {
+		return &TransactionPullResponse{}, nil
+	}
+
+	return p.Protocol.PullTransactions(ctx, req)
+}
+
+func (p *ByzantineProtocol) SyncTransactions(stream Wavelet_SyncTransactionsServer) error {
+	if p.Has(ByzantineWithholdTransactions) {
+		return p.Protocol.SyncTransactions(withheldTransactionsStream{stream})
+	}
+
+	return p.Protocol.SyncTransactions(stream)
+}
+
+func (p *ByzantineProtocol) Sync(stream Wavelet_SyncServer) error {
+	if p.Has(ByzantineCorruptSync) {
+		return p.Protocol.Sync(&corruptSyncStream{Wavelet_SyncServer: stream, protocol: p})
+	}
+
+	return p.Protocol.Sync(stream)
+}
+
+// GossipWithInvalidSignature gossips a transaction to all peers with its signature
+// corrupted.
+func (p *ByzantineProtocol) GossipWithInvalidSignature(tx Transaction) error {
+	tx.Signature[0] ^= 0xFF
+
+	req := &GossipRequest{Transactions: [][]byte{tx.Marshal()}}
+
+	for _, peer := range p.ledger.client.ClosestPeers() {
+		ctx, cancel := context.WithTimeout(context.Background(), conf.GetGossipTimeout())
+
+		_, err := NewWaveletClient(peer.Conn()).Gossip(ctx, req)
+		cancel()
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to gossip to %s", peer.ID().Address())
+		}
+	}
+
+	return nil
+}
+
+// withheldTransactionsStream tells peers syncing transactions that there are none to
+// sync.
+type withheldTransactionsStream struct {
+	Wavelet_SyncTransactionsServer
+}
+
+func (s withheldTransactionsStream) Send(res *TransactionsSyncResponse) error {
+	switch res.Data.(type) {
+	case *TransactionsSyncResponse_TransactionsNum:
+		res = &TransactionsSyncResponse{
+			Data: &TransactionsSyncResponse_TransactionsNum{TransactionsNum: 0},
+		}
+	case *TransactionsSyncResponse_Transactions:
+		res = &TransactionsSyncResponse{
+			Data: &TransactionsSyncResponse_Transactions{Transactions: &TransactionsSyncPart{}},
+		}
+	}
+
+	return s.Wavelet_SyncTransactionsServer.Send(res)
+}
+
+// corruptSyncStream serves an honest header of the latest state to peers that are syncing,
+// such that its chunks are downloaded, but with random bytes within every chunk flipped.
+type corruptSyncStream struct {
+	Wavelet_SyncServer
+
+	protocol *ByzantineProtocol
+}
+
+func (s *corruptSyncStream) Send(res *SyncResponse) error {
+	chunk := res.GetChunk()
+	if len(chunk) == 0 {
+		return s.Wavelet_SyncServer.Send(res)
+	}
+
+	corrupted := make([]byte, len(chunk))
+	copy(corrupted, chunk)
+
+	noise := make([]byte, len(corrupted))
+	s.protocol.random(noise)
+
+	for i := range corrupted {
+		corrupted[i] ^= noise[i] | 1
+	}
+
+	return s.Wavelet_SyncServer.Send(&SyncResponse{Data: &SyncResponse_Chunk{Chunk: corrupted}})
+}
+
+var _ WaveletServer = (*ByzantineProtocol)(nil)
@@ -0,0 +1,278 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package wavelet
+
+import (
+	"testing"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+// newByzantineTestNetwork spawns a simulated network of the given number of honest nodes,
+// and a node which misbehaves in the given ways.
+func newByzantineTestNetwork(
+	t *testing.T, seed int64, numHonest int, behavior ByzantineBehavior,
+) (*TestNetwork, []*TestLedger, *TestLedger) {
+	testnet, err := NewTestNetwork(WithSimNetwork(NewSimNetwork(seed)))
+	FailTest(t, err)
+
+	honest := []*TestLedger{testnet.Faucet()}
+
+	for i := 1; i < numHonest; i++ {
+		node, err := testnet.AddNode()
+		FailTest(t, err)
+
+		honest = append(honest, node)
+	}
+
+	mallory, err := testnet.AddNode(WithByzantine(behavior))
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	return testnet, honest, mallory
+}
+
+// assertSameChain asserts that all given nodes have finalized the same blocks.
+func assertSameChain(t *testing.T, nodes []*TestLedger) {
+	t.Helper()
+
+	height := nodes[0].BlockIndex()
+	for _, node := range nodes[1:] {
+		if node.BlockIndex() < height {
+			height = node.BlockIndex()
+		}
+	}
+
+	for i := uint64(1); i <= height; i++ {
+		expected, err := nodes[0].Ledger().Blocks().GetByIndex(i)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		for _, node := range nodes[1:] {
+			block, err := node.Ledger().Blocks().GetByIndex(i)
+			if assert.NoError(t, err) {
+				assert.Equal(t, expected.ID, block.ID, "block %d differs on %x", i, node.PublicKey())
+			}
+		}
+	}
+}
+
+// broadcast hands a transaction to all given nodes. Transactions are only gossiped to a
+// few peers at a time, so a node may otherwise never receive a transaction should all the
+// peers it was gossiped to have received it already.
+func broadcast(tx Transaction, nodes []*TestLedger) {
+	for _, node := range nodes {
+		if !node.Ledger().Transactions().Has(tx.ID) {
+			node.Ledger().AddTransaction(tx)
+		}
+	}
+}
+
+// TestByzantine_Query has a node answer queries with bogus blocks. Honest nodes sample peers
+// uniformly, such that the Byzantine node is queried, and must still finalize the same blocks.
+// Enough peers are queried at a time for the vote of the Byzantine node to never be decisive.
+func TestByzantine_Query(t *testing.T) {
+	defer conf.Update(conf.WithSnowballK(conf.GetSnowballK()))
+	conf.Update(conf.WithSnowballK(6))
+
+	behaviors := map[string]ByzantineBehavior{
+		"random":      ByzantineRandomBlocks,
+		"conflicting": ByzantineConflictingBlocks,
+	}
+
+	for name, behavior := range behaviors {
+		behavior := behavior
+
+		t.Run(name, func(t *testing.T) {
+			testnet, honest, mallory := newByzantineTestNetwork(t, 1, 7, behavior)
+			defer testnet.Cleanup()
+
+			faucet, alice := honest[0], honest[1]
+
+			for i := uint64(1); i <= 3; i++ {
+				tx, err := faucet.Pay(alice, 1000)
+				FailTest(t, err)
+
+				broadcast(tx, honest)
+
+				FailTest(t, alice.WaitUntilBalance(1000*i))
+			}
+
+			for _, node := range honest {
+				FailTest(t, node.WaitUntilBlock(faucet.BlockIndex()))
+
+				assert.EqualValues(t, 3000, node.BalanceOfAccount(alice))
+			}
+
+			assertSameChain(t, honest)
+
+			assert.True(t, mallory.Byzantine().Queries() > 0, "the byzantine node was never queried")
+		})
+	}
+}
+
+// TestByzantine_Reputation has a node answer queries with blocks containing the correct
+// transactions but a bogus merkle root. Honest nodes sample peers uniformly, and must ban
+// the Byzantine node for its invalid votes to keep finalizing blocks.
+func TestByzantine_Reputation(t *testing.T) {
+	testnet, honest, mallory := newByzantineTestNetwork(t, 5, 3, ByzantineConflictingBlocks)
+	defer testnet.Cleanup()
+
+	faucet, alice := honest[0], honest[1]
+	nodes := append(honest, mallory)
+
+	for i := uint64(1); i <= 3; i++ {
+		tx, err := faucet.Pay(alice, 1000)
+		FailTest(t, err)
+
+		broadcast(tx, nodes)
+
+		FailTest(t, alice.WaitUntilBalance(1000*i))
+	}
+
+	for _, node := range honest {
+		FailTest(t, node.WaitUntilBlock(faucet.BlockIndex()))
+	}
+
+	assertSameChain(t, honest)
+
+	banned := false
+
+	for _, node := range honest {
+		score := node.Ledger().Reputation().Score(mallory.PublicKey())
+		assert.True(t, score.Score < 0, "%x should have penalized the byzantine node", node.PublicKey())
+
+		banned = banned || node.Ledger().Reputation().Banned(mallory.PublicKey())
+
+		for _, peer := range honest {
+			if peer != node {
+				assert.False(t, node.Ledger().Reputation().Banned(peer.PublicKey()))
+			}
+		}
+	}
+
+	assert.True(t, banned, "no honest node banned the byzantine node")
+}
+
+func TestByzantine_WithholdTransactions(t *testing.T) {
+	testnet, honest, mallory := newByzantineTestNetwork(t, 2, 3, ByzantineWithholdTransactions)
+	defer testnet.Cleanup()
+
+	faucet, alice := honest[0], honest[1]
+
+	// Have the transaction originate from the Byzantine node, such that the only way for
+	// peers to get it is through gossip rather than by pulling it from the node.
+
+	_, err := faucet.Pay(mallory, 1000000)
+	FailTest(t, err)
+
+	FailTest(t, mallory.WaitUntilBalance(1000000))
+
+	_, err = mallory.Pay(alice, 1000)
+	FailTest(t, err)
+
+	for _, node := range honest {
+		FailTest(t, waitFor(func() bool { return node.BalanceOfAccount(alice) == 1000 }))
+	}
+
+	assertSameChain(t, append(honest, mallory))
+}
+
+func TestByzantine_InvalidGossip(t *testing.T) {
+	testnet, honest, mallory := newByzantineTestNetwork(t, 3, 3, ByzantineInvalidGossip)
+	defer testnet.Cleanup()
+
+	faucet, alice, bob := honest[0], honest[1], honest[2]
+
+	tx, err := faucet.Pay(alice, 1000)
+	FailTest(t, err)
+
+	broadcast(tx, honest)
+
+	FailTest(t, alice.WaitUntilBalance(1000))
+
+	// Have the Byzantine node replay the payment under a corrupted signature.
+	FailTest(t, mallory.Byzantine().GossipWithInvalidSignature(tx))
+
+	tx, err = faucet.Pay(bob, 1000)
+	FailTest(t, err)
+
+	broadcast(tx, honest)
+
+	FailTest(t, bob.WaitUntilBalance(1000))
+
+	for _, node := range honest {
+		FailTest(t, node.WaitUntilBlock(faucet.BlockIndex()))
+
+		assert.EqualValues(t, 1000, node.BalanceOfAccount(alice))
+		assert.EqualValues(t, 1000, node.BalanceOfAccount(bob))
+	}
+
+	assertSameChain(t, honest)
+}
+
+func TestByzantine_CorruptSync(t *testing.T) {
+	testnet, err := NewTestNetwork(WithSimNetwork(NewSimNetwork(4)), WithoutReplay())
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	faucet := testnet.Faucet()
+
+	for i := 0; i < 2; i++ {
+		_, err := testnet.AddNode()
+		FailTest(t, err)
+	}
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	for i := 0; i < int(conf.GetSyncIfBlockIndicesDifferBy())+1; i++ {
+		_, err := faucet.PlaceStake(1)
+		FailTest(t, err)
+
+		FailTest(t, faucet.WaitUntilBlock(uint64(i+1)))
+	}
+
+	mallory, err := testnet.AddNode(WithByzantine(ByzantineCorruptSync))
+	FailTest(t, err)
+
+	FailTest(t, mallory.WaitUntilBlock(faucet.BlockIndex()))
+
+	// A node joining the network now has to sync, and may download chunks of the
+	// latest state from the Byzantine node.
+
+	charlie, err := testnet.AddNode()
+	FailTest(t, err)
+
+	FailTest(t, charlie.WaitUntilBlock(faucet.BlockIndex()))
+
+	latest := charlie.Ledger().Blocks().Latest()
+
+	expected, err := faucet.Ledger().Blocks().GetByIndex(latest.Index)
+	FailTest(t, err)
+
+	assert.Equal(t, expected.ID, latest.ID)
+	assert.EqualValues(t, faucet.Stake(), charlie.StakeOfAccount(faucet))
+}
@@ -57,6 +57,16 @@ func (cli *CLI) status(ctx *cli.Context) {
 		peers = append(peers, fmt.Sprintf("%s[%x]", p.Address, p.PublicKey))
 	}
 
+	if l.Sync != nil {
+		cli.logger.Info().
+			Uint64("block_height", l.Sync.Height).
+			Int("chunks_done", l.Sync.ChunksDone).
+			Int("chunks_total", l.Sync.ChunksTotal).
+			Uint64("bytes_done", l.Sync.BytesDone).
+			Dur("eta", l.Sync.ETA()).
+			Msg("Your node is downloading the latest state from its peers.")
+	}
+
 	cli.logger.Info().
 		Uint64("block_height", l.Block.Index).
 		Hex("block_id", l.Block.ID[:]).
@@ -233,6 +243,67 @@ func (cli *CLI) find(ctx *cli.Context) {
 	}
 }
 
+func (cli *CLI) diff(ctx *cli.Context) {
+	cmd := ctx.Args()
+
+	if len(cmd) < 1 || len(cmd) > 2 {
+		cli.logger.Error().Msg("Invalid usage: diff <from height | merkle root> [to height | merkle root]")
+		return
+	}
+
+	diff, err := cli.client.StateDiff(cmd.Get(0), cmd.Get(1))
+	if err != nil {
+		cli.logger.Error().Err(err).
+			Msg("Failed to diff states")
+		return
+	}
+
+	for _, account := range diff.Accounts {
+		event := cli.logger.Info()
+
+		changes := []struct {
+			name   string
+			change *wavelet.Uint64Change
+		}{
+			{"balance", account.Balance},
+			{"stake", account.Stake},
+			{"reward", account.Reward},
+			{"gas_balance", account.GasBalance},
+			{"num_pages", account.NumPages},
+		}
+
+		for _, c := range changes {
+			if c.change != nil {
+				event = event.
+					Uint64(c.name+"_before", c.change.Before).
+					Uint64(c.name+"_after", c.change.After)
+			}
+		}
+
+		if account.Code != nil {
+			event = event.
+				Hex("code_before", account.Code.Before[:]).
+				Hex("code_after", account.Code.After[:])
+		}
+
+		if len(account.Pages) > 0 {
+			event = event.Interface("pages", account.Pages)
+		}
+
+		if account.Globals {
+			event = event.Bool("globals", true)
+		}
+
+		event.Msgf("Account: %x", account.ID)
+	}
+
+	cli.logger.Info().
+		Hex("from", diff.From[:]).
+		Hex("to", diff.To[:]).
+		Int("num_accounts", len(diff.Accounts)).
+		Msg("Diffed states.")
+}
+
 func (cli *CLI) spawn(ctx *cli.Context) {
 	cmd := ctx.Args()
 
@@ -455,6 +526,9 @@ func (cli *CLI) updateParameters(ctx *cli.Context) {
 		conf.WithCheckOutOfSyncTimeout(ctx.Duration("check.out.of.sync.timeout")),
 		conf.WithSyncChunkSize(ctx.Int("sync.chunk.size")),
 		conf.WithSyncIfBlockIndicesDifferBy(ctx.Uint64("sync.if.block.indices.differ.by")),
+		conf.WithSyncDownloadAttempts(ctx.Int("sync.download.attempts")),
+		conf.WithSyncChunkConcurrency(ctx.Int("sync.chunk.concurrency")),
+		conf.WithSyncReplayLimit(ctx.Uint64("sync.replay.limit")),
 		conf.WithPruningLimit(uint8(ctx.Uint64("pruning.limit"))),
 		conf.WithSecret(ctx.String("api.secret")),
 		conf.WithTXSyncChunkSize(ctx.Uint64("tx.sync.chunk.size")),
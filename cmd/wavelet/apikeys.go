@@ -0,0 +1,83 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/pkg/errors"
+)
+
+// reloadAPIKeysOnSignal reloads API keys from a file whenever SIGHUP is received, until the
+// returned function is called. Should the file fail to be read, the keys loaded previously are
+// kept in place.
+func reloadAPIKeysOnSignal(path string) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+				keys, err := conf.ReadAPIKeys(path)
+				if err != nil {
+					logger.Error().Err(err).Msg("Failed to reload API keys.")
+					continue
+				}
+
+				conf.Update(conf.WithAPIKeys(keys...))
+
+				logger.Info().Int("num_keys", len(keys)).Str("path", path).Msg("Reloaded API keys.")
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// publicScopes returns the non-empty scopes given, such that passing an empty scope allows for
+// no scopes to be public.
+func publicScopes(scopes []string) ([]string, error) {
+	public := make([]string, 0, len(scopes))
+
+	for _, scope := range scopes {
+		if len(scope) == 0 {
+			continue
+		}
+
+		if !conf.IsScope(scope) {
+			return nil, errors.Errorf("unknown api scope %q; expected one of %v", scope, conf.Scopes)
+		}
+
+		public = append(public, scope)
+	}
+
+	return public, nil
+}
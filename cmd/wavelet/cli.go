@@ -136,6 +136,12 @@ func NewCLI(client *wctl.Client, opts ...CLIOption) (*CLI, error) {
 			Action:      a(c.find),
 			Description: "search for any wallet/smart contract/transaction",
 		},
+		{
+			Name:        "diff",
+			Aliases:     []string{"df"},
+			Action:      a(c.diff),
+			Description: "print changes made to accounts between two block heights or merkle roots",
+		},
 		{
 			Name:        "spawn",
 			Aliases:     []string{"s"},
@@ -267,6 +273,21 @@ func NewCLI(client *wctl.Client, opts ...CLIOption) (*CLI, error) {
 					Value: conf.GetSyncIfBlockIndicesDifferBy(),
 					Usage: "difference in blocks between nodes which initiates state syncing",
 				},
+				cli.IntFlag{
+					Name:  "sync.download.attempts",
+					Value: conf.GetSyncDownloadAttempts(),
+					Usage: "number of attempts at downloading all chunks of state before restarting state syncing",
+				},
+				cli.IntFlag{
+					Name:  "sync.chunk.concurrency",
+					Value: conf.GetSyncChunkConcurrency(),
+					Usage: "number of chunks of state downloaded at once during state syncing",
+				},
+				cli.Uint64Flag{
+					Name:  "sync.replay.limit",
+					Value: conf.GetSyncReplayLimit(),
+					Usage: "maximum number of blocks behind at which missed blocks are replayed instead of syncing state",
+				},
 				cli.Uint64Flag{
 					Name:  "pruning.limit",
 					Value: uint64(conf.GetPruningLimit()),
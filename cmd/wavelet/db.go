@@ -0,0 +1,118 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/perlin-network/wavelet"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var dbCommand = cli.Command{
+	Name:  "db",
+	Usage: "manage the database of a node which is not running",
+	Subcommands: []cli.Command{
+		{
+			Name: "migrate",
+			Usage: "copy all keys of a database into an empty database of another storage engine, and verify " +
+				"that both hold the same state and latest block",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name: "from",
+					Usage: "Storage engine (level, badger or bbolt) and directory path of the database to copy " +
+						"from, e.g. level:/path.",
+				},
+				cli.StringFlag{
+					Name: "to",
+					Usage: "Storage engine (level, badger or bbolt) and directory path of the empty database to " +
+						"copy to, e.g. badger:/path.",
+				},
+			},
+			Action: dbMigrate,
+		},
+	},
+}
+
+func dbMigrate(c *cli.Context) error {
+	if c.String("from") == "" || c.String("to") == "" {
+		return errors.New("usage: db migrate --from <engine>:<path> --to <engine>:<path>")
+	}
+
+	src, err := openDB(c.String("from"))
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = src.Close()
+	}()
+
+	dst, err := openDB(c.String("to"))
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = dst.Close()
+	}()
+
+	migration, err := wavelet.Migrate(dst, src, func(keys uint64, bytes uint64) {
+		logger.Info().
+			Uint64("num_keys", keys).
+			Uint64("num_bytes", bytes).
+			Msg("Copying keys...")
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate database")
+	}
+
+	event := logger.Info().
+		Uint64("num_keys", migration.Keys).
+		Uint64("num_bytes", migration.Bytes).
+		Hex("merkle_root", migration.Checksum[:])
+
+	if migration.Latest != nil {
+		event = event.
+			Uint64("block_height", migration.Latest.Index).
+			Hex("block_id", migration.Latest.ID[:])
+	}
+
+	event.Msg("Migrated database.")
+
+	return nil
+}
+
+// openDB opens a database given its storage engine and directory path, formatted as engine:path.
+func openDB(spec string) (store.KV, error) {
+	fields := strings.SplitN(spec, ":", 2)
+	if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+		return nil, errors.Errorf("database %q must be formatted as <engine>:<path>", spec)
+	}
+
+	kv, err := store.Open(fields[0], fields[1])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open database located at %s", fields[1])
+	}
+
+	return kv, nil
+}
@@ -29,6 +29,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/perlin-network/noise/edwards25519"
@@ -37,6 +38,7 @@ import (
 	"github.com/perlin-network/wavelet/cmd/wavelet/node"
 	"github.com/perlin-network/wavelet/conf"
 	"github.com/perlin-network/wavelet/log"
+	"github.com/perlin-network/wavelet/store"
 	"github.com/perlin-network/wavelet/sys"
 	"github.com/perlin-network/wavelet/wctl"
 	"github.com/pkg/errors"
@@ -112,6 +114,11 @@ func Run(args []string, stdin io.ReadCloser, stdout io.Writer, disableGC bool) {
 			Usage:  "Host a local HTTP API at port.",
 			EnvVar: "WAVELET_API_PORT",
 		}),
+		altsrc.NewUintFlag(cli.UintFlag{
+			Name:   "api.grpc.port",
+			Usage:  "Host a local gRPC API at port. Disabled if 0.",
+			EnvVar: "WAVELET_API_GRPC_PORT",
+		}),
 		altsrc.NewStringFlag(cli.StringFlag{
 			Name:   "cli.host",
 			Usage:  "Host to reach to manage the node.",
@@ -139,6 +146,44 @@ func Run(args []string, stdin io.ReadCloser, stdout io.Writer, disableGC bool) {
 			Usage:  "Shared secret to restrict access to some api",
 			EnvVar: "WAVELET_API_SECRET",
 		},
+		altsrc.NewStringFlag(cli.StringFlag{
+			Name: "api.keys",
+			Usage: "Path to a JSON file of named API keys, formatted as [{\"name\": ..., \"key\": ..., " +
+				"\"scopes\": [...], \"rate_limit_multiplier\": ...}]. Possible scopes: read-only, submit-tx, " +
+				"node-admin, debug. The rate limit multiplier is optional. The file is reloaded upon SIGHUP.",
+			EnvVar: "WAVELET_API_KEYS",
+		}),
+		altsrc.NewStringSliceFlag(cli.StringSliceFlag{
+			Name: "api.public_scopes",
+			Usage: "Scopes of API routes which may be accessed without an API key. Defaults to read-only and " +
+				"submit-tx.",
+			EnvVar: "WAVELET_API_PUBLIC_SCOPES",
+		}),
+		altsrc.NewStringSliceFlag(cli.StringSliceFlag{
+			Name: "api.rate_limit",
+			Usage: "Rate limit per client of a group of API routes, formatted as group=requests_per_sec:burst. " +
+				"Possible groups: poll, debug, ledger, accounts, contract, tx, tx.send, node, webhooks. " +
+				"An empty group sets the rate limit of all other groups.",
+			EnvVar: "WAVELET_API_RATE_LIMIT",
+		}),
+		altsrc.NewStringSliceFlag(cli.StringSliceFlag{
+			Name: "api.rate_limit.tier",
+			Usage: "Multiplier of the API rate limits of clients bearing a token, formatted as token=multiplier. " +
+				"Clients bearing the API secret have their rate limits multiplied by 10 unless specified otherwise.",
+			EnvVar: "WAVELET_API_RATE_LIMIT_TIER",
+		}),
+		altsrc.NewStringFlag(cli.StringFlag{
+			Name:   "api.ws.policy",
+			Value:  conf.GetAPIWebsocketPolicy(),
+			Usage:  "Policy applied to websocket clients too slow to keep up with events: drop-oldest, coalesce or disconnect.",
+			EnvVar: "WAVELET_API_WS_POLICY",
+		}),
+		altsrc.NewIntFlag(cli.IntFlag{
+			Name:   "api.ws.queue_size",
+			Value:  conf.GetAPIWebsocketQueueSize(),
+			Usage:  "Max number of events queued to a single websocket client.",
+			EnvVar: "WAVELET_API_WS_QUEUE_SIZE",
+		}),
 		altsrc.NewStringFlag(cli.StringFlag{
 			Name: "wallet",
 			Usage: "Path to file containing hex-encoded private key. If the path specified is invalid, or no file " +
@@ -157,6 +202,18 @@ func Run(args []string, stdin io.ReadCloser, stdout io.Writer, disableGC bool) {
 			Usage:  "Directory path to the database. If empty, a temporary in-memory database will be used instead.",
 			EnvVar: "WAVELET_DB_PATH",
 		}),
+		altsrc.NewStringFlag(cli.StringFlag{
+			Name:   "db.engine",
+			Value:  store.EngineLevelDB,
+			Usage:  "Storage engine of the database. Possible values: level, badger, bbolt.",
+			EnvVar: "WAVELET_DB_ENGINE",
+		}),
+		altsrc.NewBoolFlag(cli.BoolFlag{
+			Name: "archive",
+			Usage: "Run as an archival node which persists every finalized block and transaction, and retains " +
+				"the state of all accounts at every block height.",
+			EnvVar: "WAVELET_ARCHIVE",
+		}),
 		altsrc.NewStringFlag(cli.StringFlag{
 			Name:   "loglevel",
 			Value:  "debug",
@@ -200,12 +257,24 @@ func Run(args []string, stdin io.ReadCloser, stdout io.Writer, disableGC bool) {
 			Usage:  "Snowball consensus protocol parameter beta",
 			EnvVar: "WAVELET_SNOWBALL_BETA",
 		}),
+		altsrc.NewStringFlag(cli.StringFlag{
+			Name:   "sys.snowball.sampler",
+			Value:  wavelet.SamplerUniform,
+			Usage:  "Strategy used to sample peers during consensus. Possible values: uniform, stake.",
+			EnvVar: "WAVELET_SNOWBALL_SAMPLER",
+		}),
 		cli.StringFlag{
 			Name:  "config, c",
 			Usage: "Path to TOML config file, will override the arguments.",
 		},
 	}
 
+	app.Commands = []cli.Command{
+		snapshotCommand,
+		verifyCommand,
+		dbCommand,
+	}
+
 	// apply the toml before processing the flags
 	app.Before = altsrc.InitInputSourceWithContext(
 		app.Flags, func(c *cli.Context) (altsrc.InputSourceContext, error) {
@@ -272,13 +341,54 @@ func start(c *cli.Context, stdin io.ReadCloser, stdout io.Writer, disableGC bool
 		secret = base64.StdEncoding.EncodeToString(sha[:])
 	}
 
-	conf.Update(
+	rateLimits, err := rateLimitOptions(secret, c.StringSlice("api.rate_limit"), c.StringSlice("api.rate_limit.tier"))
+	if err != nil {
+		return err
+	}
+
+	confOpts := append([]conf.Option{
 		conf.WithSnowballK(c.Int("sys.snowball.k")),
 		conf.WithSnowballBeta(c.Int("sys.snowball.beta")),
 		conf.WithQueryTimeout(c.Duration("sys.query_timeout")),
 		conf.WithSecret(secret),
+	}, rateLimits...)
+
+	if c.IsSet("api.public_scopes") {
+		scopes, err := publicScopes(c.StringSlice("api.public_scopes"))
+		if err != nil {
+			return err
+		}
+
+		confOpts = append(confOpts, conf.WithAPIPublicScopes(scopes...))
+	}
+
+	if policy := c.String("api.ws.policy"); !conf.IsWebsocketPolicy(policy) {
+		return errors.Errorf("unknown websocket policy %q; expected one of %v", policy, conf.WebsocketPolicies)
+	}
+
+	if c.Int("api.ws.queue_size") <= 0 {
+		return errors.New("websocket queue size must be positive")
+	}
+
+	confOpts = append(confOpts,
+		conf.WithAPIWebsocketPolicy(c.String("api.ws.policy")),
+		conf.WithAPIWebsocketQueueSize(c.Int("api.ws.queue_size")),
 	)
 
+	if path := c.String("api.keys"); len(path) > 0 {
+		keys, err := conf.ReadAPIKeys(path)
+		if err != nil {
+			return err
+		}
+
+		confOpts = append(confOpts, conf.WithAPIKeys(keys...))
+
+		stop := reloadAPIKeysOnSignal(path)
+		defer stop()
+	}
+
+	conf.Update(confOpts...)
+
 	// set the the sys variables
 	sys.DefaultTransactionFee = c.Uint64("sys.transaction_fee_amount")
 	sys.MinimumStake = c.Uint64("sys.min_stake")
@@ -293,9 +403,13 @@ func start(c *cli.Context, stdin io.ReadCloser, stdout io.Writer, disableGC bool
 			Port:        c.Uint("port"),
 			Wallet:      w,
 			APIPort:     c.Uint("api.port"),
+			GRPCPort:    c.Uint("api.grpc.port"),
 			Peers:       c.Args(),
 			Database:    c.String("db"),
+			DBEngine:    c.String("db.engine"),
 			MaxMemoryMB: c.Uint64("memory.max"),
+			PeerSampler: c.String("sys.snowball.sampler"),
+			Archive:     c.Bool("archive"),
 			// HTTPS
 			APIHost:       c.String("api.host"),
 			APICertsCache: c.String("api.certs"),
@@ -325,6 +439,7 @@ func start(c *cli.Context, stdin io.ReadCloser, stdout io.Writer, disableGC bool
 			wctlCfg.APIPort = uint16(c.Uint("cli.port"))
 		} else {
 			wctlCfg.APIPort = uint16(c.Uint("api.port"))
+			wctlCfg.GRPCPort = uint16(c.Uint("api.grpc.port"))
 		}
 
 		wctlCfg.PrivateKey = srv.Keys.PrivateKey()
@@ -413,3 +528,49 @@ func wallet(wallet string) (string, error) {
 
 	return hex.EncodeToString(privateKey[:]), nil
 }
+
+// rateLimitOptions parses API rate limits formatted as group=requests_per_sec:burst, and API rate
+// limit tiers formatted as token=multiplier. The secret is given a tier by default.
+func rateLimitOptions(secret string, limits []string, tiers []string) ([]conf.Option, error) {
+	opts := []conf.Option{conf.WithAPIRateLimitTier(secret, 10)}
+
+	for _, limit := range limits {
+		fields := strings.SplitN(limit, "=", 2)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("api rate limit %q must be formatted as group=requests_per_sec:burst", limit)
+		}
+
+		rates := strings.SplitN(fields[1], ":", 2)
+		if len(rates) != 2 {
+			return nil, errors.Errorf("api rate limit %q must be formatted as group=requests_per_sec:burst", limit)
+		}
+
+		perSec, err := strconv.ParseFloat(rates[0], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid requests per second in api rate limit %q", limit)
+		}
+
+		burst, err := strconv.Atoi(rates[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid burst in api rate limit %q", limit)
+		}
+
+		opts = append(opts, conf.WithAPIRateLimit(fields[0], conf.APIRateLimit{PerSecond: perSec, Burst: burst}))
+	}
+
+	for _, tier := range tiers {
+		fields := strings.SplitN(tier, "=", 2)
+		if len(fields) != 2 || len(fields[0]) == 0 {
+			return nil, errors.Errorf("api rate limit tier %q must be formatted as token=multiplier", tier)
+		}
+
+		multiplier, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid multiplier in api rate limit tier %q", tier)
+		}
+
+		opts = append(opts, conf.WithAPIRateLimitTier(fields[0], multiplier))
+	}
+
+	return opts, nil
+}
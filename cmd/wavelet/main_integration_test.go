@@ -547,6 +547,9 @@ func TestMain_UpdateParams(t *testing.T) {
 		{"check.out.of.sync.timeout", "checkOutOfSyncTimeout", time.Second * 7},
 		{"sync.chunk.size", "syncChunkSize", 1337},
 		{"sync.if.block.indices.differ.by", "syncIfBlockIndicesDifferBy", uint64(42)},
+		{"sync.download.attempts", "syncDownloadAttempts", 5},
+		{"sync.chunk.concurrency", "syncChunkConcurrency", 4},
+		{"sync.replay.limit", "syncReplayLimit", uint64(12)},
 		{"pruning.limit", "pruningLimit", uint64(255)},
 		{"api.secret", "secret", "shambles"},
 	}
@@ -33,9 +33,13 @@ type Config struct {
 	Wallet      string // hex encoded
 	Genesis     *string
 	APIPort     uint
+	GRPCPort    uint
 	Peers       []string
 	Database    string
+	DBEngine    string
 	MaxMemoryMB uint64
+	PeerSampler string
+	Archive     bool
 
 	// HTTPS
 	APIHost       string
@@ -53,6 +57,7 @@ var DefaultConfig = Config{
 	APIPort:  9000,
 	Peers:    []string{},
 	Database: "",
+	DBEngine: store.EngineLevelDB,
 }
 
 var (
@@ -178,7 +183,7 @@ func New(cfg *Config) (*Wavelet, error) {
 	var kv store.KV
 	if len(cfg.Database) == 0 {
 		kv = store.NewInmem()
-	} else if kv, err = store.NewLevelDB(cfg.Database); err != nil {
+	} else if kv, err = store.Open(cfg.DBEngine, cfg.Database); err != nil {
 		return nil, errors.Wrapf(err, "failed to create/open database located at %s", cfg.Database)
 	}
 
@@ -196,6 +201,19 @@ func New(cfg *Config) (*Wavelet, error) {
 		opts = append(opts, wavelet.WithMaxMemoryMB(cfg.MaxMemoryMB))
 	}
 
+	if cfg.Archive {
+		opts = append(opts, wavelet.WithArchive())
+	}
+
+	if len(cfg.PeerSampler) > 0 {
+		sampler, err := wavelet.NewPeerSampler(cfg.PeerSampler)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, wavelet.WithPeerSampler(sampler))
+	}
+
 	ledger, err := wavelet.NewLedger(kv, client, opts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating ledger")
@@ -211,6 +229,8 @@ func (w *Wavelet) Start() {
 		w.config.APIPort = 9000
 	}
 
+	w.Gateway.WithGRPCPort(int(w.config.GRPCPort))
+
 	if w.config.APIHost != "" {
 		w.Gateway.StartHTTPS(
 			int(w.config.APIPort),
@@ -0,0 +1,194 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/perlin-network/wavelet"
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var snapshotCommand = cli.Command{
+	Name:  "snapshot",
+	Usage: "export or import a snapshot of the state of all accounts to bootstrap a node",
+	Subcommands: []cli.Command{
+		{
+			Name:      "export",
+			Usage:     "write a snapshot of the state of all accounts at a finalized block to a file",
+			ArgsUsage: "<file, or - for stdout>",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "db",
+					Usage: "Directory path to the database of a node which is not running.",
+				},
+				cli.StringFlag{
+					Name:  "db.engine",
+					Value: store.EngineLevelDB,
+					Usage: "Storage engine of the database. Possible values: level, badger, bbolt.",
+				},
+				cli.Uint64Flag{
+					Name:  "height",
+					Usage: "Height of the block to export state at. Requires the database to have been archived.",
+				},
+			},
+			Action: snapshotExport,
+		},
+		{
+			Name:      "import",
+			Usage:     "verify and load a snapshot into an empty database",
+			ArgsUsage: "<file, or - for stdin>",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "db",
+					Usage: "Directory path to an empty database.",
+				},
+				cli.StringFlag{
+					Name:  "db.engine",
+					Value: store.EngineLevelDB,
+					Usage: "Storage engine of the database. Possible values: level, badger, bbolt.",
+				},
+				cli.StringFlag{
+					Name: "genesis",
+					Usage: "Directory path or JSON contents containing genesis files of the network the snapshot " +
+						"was exported from.",
+				},
+			},
+			Action: snapshotImport,
+		},
+	},
+}
+
+func snapshotExport(c *cli.Context) error {
+	if c.String("db") == "" || c.NArg() != 1 {
+		return errors.New("usage: snapshot export --db <path> [--db.engine <engine>] [--height <height>] <file>")
+	}
+
+	kv, err := store.Open(c.String("db.engine"), c.String("db"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to open database located at %s", c.String("db"))
+	}
+
+	defer func() {
+		_ = kv.Close()
+	}()
+
+	blocks, err := wavelet.NewBlocks(kv, conf.GetPruningLimit())
+	if err != nil {
+		return errors.Wrap(err, "failed to load blocks")
+	}
+
+	block := blocks.Latest()
+
+	var state *avl.Tree
+
+	if c.IsSet("height") {
+		if block, err = blocks.GetByIndex(c.Uint64("height")); err != nil {
+			return err
+		}
+
+		if state, err = wavelet.NewAccounts(kv).WithArchive(true).SnapshotAt(block.Index); err != nil {
+			return err
+		}
+	} else {
+		state = wavelet.NewAccounts(kv).Snapshot()
+	}
+
+	w := io.Writer(os.Stdout)
+
+	if path := c.Args().First(); path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create snapshot file %s", path)
+		}
+
+		defer func() {
+			_ = f.Close()
+		}()
+
+		w = f
+	}
+
+	if err := wavelet.ExportSnapshot(w, state, *block); err != nil {
+		return err
+	}
+
+	logger.Info().
+		Uint64("block_height", block.Index).
+		Hex("block_id", block.ID[:]).
+		Hex("merkle_root", block.Merkle[:]).
+		Msg("Exported snapshot.")
+
+	return nil
+}
+
+func snapshotImport(c *cli.Context) error {
+	if c.String("db") == "" || c.NArg() != 1 {
+		return errors.New("usage: snapshot import --db <path> [--db.engine <engine>] [--genesis <genesis>] <file>")
+	}
+
+	r := io.Reader(os.Stdin)
+
+	if path := c.Args().First(); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open snapshot file %s", path)
+		}
+
+		defer func() {
+			_ = f.Close()
+		}()
+
+		r = f
+	}
+
+	kv, err := store.Open(c.String("db.engine"), c.String("db"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create/open database located at %s", c.String("db"))
+	}
+
+	defer func() {
+		_ = kv.Close()
+	}()
+
+	var genesis *string
+
+	if g := c.String("genesis"); len(g) > 0 {
+		genesis = &g
+	}
+
+	block, err := wavelet.ImportSnapshot(kv, r, genesis)
+	if err != nil {
+		return err
+	}
+
+	logger.Info().
+		Uint64("block_height", block.Index).
+		Hex("block_id", block.ID[:]).
+		Hex("merkle_root", block.Merkle[:]).
+		Msg("Imported snapshot.")
+
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/hex"
+
+	"github.com/perlin-network/wavelet"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var verifyCommand = cli.Command{
+	Name: "verify",
+	Usage: "re-execute all finalized blocks stored in a database, and check that they reproduce the " +
+		"merkle roots recorded in each block",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "db",
+			Usage: "Directory path to the database of a node which is not running. It is opened read-only.",
+		},
+		cli.StringFlag{
+			Name:  "db.engine",
+			Value: store.EngineLevelDB,
+			Usage: "Storage engine of the database. Possible values: level, badger, bbolt.",
+		},
+		cli.StringFlag{
+			Name:  "genesis",
+			Usage: "Directory path or JSON contents containing genesis files of the network the database belongs to.",
+		},
+		cli.Uint64Flag{
+			Name: "from",
+			Usage: "Height of the block to start re-executing from, instead of from genesis. Requires the " +
+				"database to have been archived.",
+		},
+	},
+	Action: verify,
+}
+
+func verify(c *cli.Context) error {
+	if c.String("db") == "" {
+		return errors.New("usage: verify --db <path> [--db.engine <engine>] [--genesis <genesis>] [--from <height>]")
+	}
+
+	kv, err := store.OpenReadOnly(c.String("db.engine"), c.String("db"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to open database located at %s", c.String("db"))
+	}
+
+	defer func() {
+		_ = kv.Close()
+	}()
+
+	var genesis *string
+
+	if g := c.String("genesis"); len(g) > 0 {
+		genesis = &g
+	}
+
+	height, divergence, err := wavelet.Verify(kv, genesis, c.Uint64("from"))
+	if err != nil {
+		return errors.Wrapf(err, "verified blocks up to height %d", height)
+	}
+
+	if divergence == nil {
+		logger.Info().
+			Uint64("from", c.Uint64("from")).
+			Uint64("block_height", height).
+			Msg("Verified all blocks.")
+
+		return nil
+	}
+
+	keys := make([]string, 0, len(divergence.Keys))
+	for _, key := range divergence.Keys {
+		keys = append(keys, hex.EncodeToString(key))
+	}
+
+	event := logger.Error().
+		Uint64("block_height", divergence.Block.Index).
+		Hex("block_id", divergence.Block.ID[:]).
+		Hex("expected_merkle_root", divergence.Expected[:]).
+		Hex("actual_merkle_root", divergence.Actual[:]).
+		Strs("differing_keys", keys)
+
+	if divergence.Transaction != nil {
+		event = event.Hex("transaction_id", divergence.Transaction.ID[:])
+	}
+
+	event.Msg("Block does not reproduce its merkle root.")
+
+	return errors.Errorf("state diverged at block %d", divergence.Block.Index)
+}
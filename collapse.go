@@ -34,6 +34,7 @@ func collapseTransactions(
 	snapshot.SetViewID(height)
 
 	res := &collapseResults{
+		height:   height,
 		snapshot: snapshot,
 		ctx:      NewCollapseContext(snapshot),
 
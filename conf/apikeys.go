@@ -0,0 +1,90 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Scopes of http api routes which API keys may be granted access to.
+const (
+	ScopeReadOnly  = "read-only"
+	ScopeSubmitTx  = "submit-tx"
+	ScopeNodeAdmin = "node-admin"
+	ScopeDebug     = "debug"
+)
+
+// Scopes lists all scopes of http api routes.
+var Scopes = []string{ScopeReadOnly, ScopeSubmitTx, ScopeNodeAdmin, ScopeDebug}
+
+// IsScope returns whether or not scope is the name of a scope of http api routes.
+func IsScope(scope string) bool {
+	return hasScope(Scopes, scope)
+}
+
+// APIKey is a named key which grants access to the http api routes of its scopes.
+type APIKey struct {
+	Name   string   `json:"name"`
+	Key    string   `json:"key"`
+	Scopes []string `json:"scopes"`
+
+	// Multiplier of the http api rate limits of clients bearing the key. Rate limits are not
+	// multiplied should it be zero.
+	RateLimitMultiplier float64 `json:"rate_limit_multiplier,omitempty"`
+}
+
+// HasScope returns whether or not the key grants access to the given scope.
+func (k APIKey) HasScope(scope string) bool {
+	return hasScope(k.Scopes, scope)
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadAPIKeys reads API keys from a JSON file, formatted as a list of objects with a name, a key,
+// a list of scopes, and optionally a multiplier of their rate limits.
+func ReadAPIKeys(path string) ([]APIKey, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api keys file %s: %v", path, err)
+	}
+
+	var keys []APIKey
+
+	if err := json.Unmarshal(buf, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse api keys file %s: %v", path, err)
+	}
+
+	names := make(map[string]struct{}, len(keys))
+
+	for _, key := range keys {
+		if len(key.Name) == 0 || len(key.Key) == 0 {
+			return nil, fmt.Errorf("api keys file %s has a key without a name or key", path)
+		}
+
+		if _, exists := names[key.Name]; exists {
+			return nil, fmt.Errorf("api keys file %s has more than one key named %s", path, key.Name)
+		}
+
+		names[key.Name] = struct{}{}
+
+		if key.RateLimitMultiplier < 0 {
+			return nil, fmt.Errorf("api key %s has a negative rate limit multiplier", key.Name)
+		}
+
+		for _, scope := range key.Scopes {
+			if !IsScope(scope) {
+				return nil, fmt.Errorf("api key %s has unknown scope %q; expected one of %v", key.Name, scope, Scopes)
+			}
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,91 @@
+// +build unit
+
+package conf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadAPIKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apikeys")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "keys.json")
+
+	write := func(contents string) {
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	}
+
+	write(`[
+		{"name": "explorer", "key": "a", "scopes": ["read-only"]},
+		{"name": "operator", "key": "b", "scopes": ["node-admin", "debug"], "rate_limit_multiplier": 5}
+	]`)
+
+	keys, err := ReadAPIKeys(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []APIKey{
+		{Name: "explorer", Key: "a", Scopes: []string{ScopeReadOnly}},
+		{Name: "operator", Key: "b", Scopes: []string{ScopeNodeAdmin, ScopeDebug}, RateLimitMultiplier: 5},
+	}, keys)
+
+	assert.True(t, keys[1].HasScope(ScopeDebug))
+	assert.False(t, keys[1].HasScope(ScopeSubmitTx))
+
+	write(`[{"name": "explorer", "key": "a", "scopes": ["admin"]}]`)
+	_, err = ReadAPIKeys(path)
+	assert.Error(t, err)
+
+	write(`[{"name": "explorer", "key": "a"}, {"name": "explorer", "key": "b"}]`)
+	_, err = ReadAPIKeys(path)
+	assert.Error(t, err)
+
+	write(`[{"name": "explorer", "key": "a", "rate_limit_multiplier": -1}]`)
+	_, err = ReadAPIKeys(path)
+	assert.Error(t, err)
+
+	write(`[{"name": "explorer"}]`)
+	_, err = ReadAPIKeys(path)
+	assert.Error(t, err)
+
+	_, err = ReadAPIKeys(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestAPIKeys(t *testing.T) {
+	defer Reset()
+
+	assert.True(t, IsAPIPublicScope(ScopeReadOnly))
+	assert.True(t, IsAPIPublicScope(ScopeSubmitTx))
+	assert.False(t, IsAPIPublicScope(ScopeNodeAdmin))
+	assert.False(t, IsAPIPublicScope(ScopeDebug))
+
+	Update(
+		WithAPIKeys(APIKey{Name: "explorer", Key: "a", Scopes: []string{ScopeReadOnly}}),
+		WithAPIPublicScopes(),
+	)
+
+	key, ok := GetAPIKey("a")
+	assert.True(t, ok)
+	assert.Equal(t, "explorer", key.Name)
+	assert.False(t, IsAPIPublicScope(ScopeReadOnly))
+
+	// Keys are replaced as a whole, such that removed keys are revoked.
+	Update(WithAPIKeys(APIKey{Name: "operator", Key: "b", Scopes: []string{ScopeNodeAdmin}}))
+
+	_, ok = GetAPIKey("a")
+	assert.False(t, ok)
+
+	_, ok = GetAPIKey("b")
+	assert.True(t, ok)
+}
@@ -39,14 +39,81 @@ type config struct {
 	// Number of blocks we should be behind before we start syncing.
 	syncIfBlockIndicesDifferBy uint64
 
+	// Number of attempts at downloading all chunks of state from peers before restarting a sync.
+	syncDownloadAttempts int
+
+	// Number of chunks of state downloaded from peers at once while syncing.
+	syncChunkConcurrency int
+
+	// Maximum number of blocks we may be behind to catch up by replaying missed blocks rather
+	// than by syncing state. Zero disables replaying blocks.
+	syncReplayLimit uint64
+
 	// Number of blocks after which transactions will be pruned from the graph
 	pruningLimit uint8
 
 	// Max number of transactions within the block
 	blockTxLimit uint64
 
+	// Duration a peer is banned for once its reputation drops too low
+	peerBanDuration time.Duration
+
 	// shared secret for http api authorization
 	secret string
+
+	// Rate limits of each group of http api routes, applied per client. The limit keyed by an
+	// empty group applies to all groups which are not listed.
+	apiRateLimits map[string]APIRateLimit
+
+	// Multipliers of the http api rate limits of clients bearing each token.
+	apiRateLimitTiers map[string]float64
+
+	// Named keys for http api authorization, keyed by their token.
+	apiKeys map[string]APIKey
+
+	// Scopes of http api routes which may be accessed without a key.
+	apiPublicScopes []string
+
+	// Policy applied to websocket clients whose queue of events is full.
+	apiWebsocketPolicy string
+
+	// Max number of events queued to a single websocket client.
+	apiWebsocketQueueSize int
+}
+
+// APIRateLimit is the rate at which a single client may make requests to a group of http api
+// routes. A non-positive rate disables rate limiting.
+type APIRateLimit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// Policies applied to websocket clients whose queue of events is full.
+const (
+	// WebsocketDropOldest drops the oldest queued event to make room for the newest.
+	WebsocketDropOldest = "drop-oldest"
+
+	// WebsocketCoalesce replaces a queued event with a newer event of the same kind about the
+	// same account, contract or transaction, and otherwise drops the oldest queued event.
+	WebsocketCoalesce = "coalesce"
+
+	// WebsocketDisconnect disconnects the client.
+	WebsocketDisconnect = "disconnect"
+)
+
+// WebsocketPolicies lists all policies applied to websocket clients whose queue of events is full.
+var WebsocketPolicies = []string{WebsocketDropOldest, WebsocketCoalesce, WebsocketDisconnect}
+
+// IsWebsocketPolicy returns whether or not policy is the name of a policy applied to websocket
+// clients whose queue of events is full.
+func IsWebsocketPolicy(policy string) bool {
+	for _, p := range WebsocketPolicies {
+		if p == policy {
+			return true
+		}
+	}
+
+	return false
 }
 
 var (
@@ -73,6 +140,9 @@ func defaultConfig() config {
 		checkOutOfSyncTimeout:      5 * time.Second,
 		syncChunkSize:              16384,
 		syncIfBlockIndicesDifferBy: 5,
+		syncDownloadAttempts:       3,
+		syncChunkConcurrency:       16,
+		syncReplayLimit:            20,
 
 		txSyncChunkSize: 5000,
 		txSyncLimit:     1 << 20,
@@ -82,6 +152,17 @@ func defaultConfig() config {
 		pruningLimit: 30,
 
 		blockTxLimit: 1 << 16,
+
+		peerBanDuration: 5 * time.Minute,
+
+		apiRateLimits: map[string]APIRateLimit{
+			"": {PerSecond: 1000, Burst: 1000},
+		},
+
+		apiPublicScopes: []string{ScopeReadOnly, ScopeSubmitTx},
+
+		apiWebsocketPolicy:    WebsocketDropOldest,
+		apiWebsocketQueueSize: 256,
 	}
 
 	if sys.VersionMeta == "testnet" {
@@ -172,6 +253,24 @@ func WithSyncIfBlockIndicesDifferBy(rdb uint64) Option {
 	}
 }
 
+func WithSyncDownloadAttempts(n int) Option {
+	return func(c *config) {
+		c.syncDownloadAttempts = n
+	}
+}
+
+func WithSyncChunkConcurrency(n int) Option {
+	return func(c *config) {
+		c.syncChunkConcurrency = n
+	}
+}
+
+func WithSyncReplayLimit(n uint64) Option {
+	return func(c *config) {
+		c.syncReplayLimit = n
+	}
+}
+
 func WithPruningLimit(pl uint8) Option {
 	return func(c *config) {
 		c.pruningLimit = pl
@@ -184,6 +283,72 @@ func WithSecret(s string) Option {
 	}
 }
 
+// WithAPIRateLimit sets the rate limit of a group of http api routes. An empty group sets the rate
+// limit of all groups which have no rate limit of their own.
+func WithAPIRateLimit(group string, limit APIRateLimit) Option {
+	return func(c *config) {
+		limits := make(map[string]APIRateLimit, len(c.apiRateLimits)+1)
+		for k, v := range c.apiRateLimits {
+			limits[k] = v
+		}
+
+		limits[group] = limit
+		c.apiRateLimits = limits
+	}
+}
+
+// WithAPIRateLimitTier multiplies the http api rate limits of clients bearing the given token.
+func WithAPIRateLimitTier(token string, multiplier float64) Option {
+	return func(c *config) {
+		tiers := make(map[string]float64, len(c.apiRateLimitTiers)+1)
+		for k, v := range c.apiRateLimitTiers {
+			tiers[k] = v
+		}
+
+		tiers[token] = multiplier
+		c.apiRateLimitTiers = tiers
+	}
+}
+
+// WithAPIKeys replaces all http api keys with the given keys.
+func WithAPIKeys(keys ...APIKey) Option {
+	return func(c *config) {
+		c.apiKeys = make(map[string]APIKey, len(keys))
+
+		for _, key := range keys {
+			c.apiKeys[key.Key] = key
+		}
+	}
+}
+
+// WithAPIPublicScopes sets the scopes of http api routes which may be accessed without a key.
+func WithAPIPublicScopes(scopes ...string) Option {
+	return func(c *config) {
+		c.apiPublicScopes = scopes
+	}
+}
+
+// WithAPIWebsocketPolicy sets the policy applied to websocket clients whose queue of events is
+// full. It is one of WebsocketPolicies.
+func WithAPIWebsocketPolicy(policy string) Option {
+	return func(c *config) {
+		c.apiWebsocketPolicy = policy
+	}
+}
+
+// WithAPIWebsocketQueueSize sets the max number of events queued to a single websocket client.
+func WithAPIWebsocketQueueSize(size int) Option {
+	return func(c *config) {
+		c.apiWebsocketQueueSize = size
+	}
+}
+
+func WithPeerBanDuration(d time.Duration) Option {
+	return func(c *config) {
+		c.peerBanDuration = d
+	}
+}
+
 func WithTXSyncChunkSize(n uint64) Option {
 	return func(c *config) {
 		c.txSyncChunkSize = n
@@ -312,6 +477,30 @@ func GetSyncIfBlockIndicesDifferBy() uint64 {
 	return t
 }
 
+func GetSyncDownloadAttempts() int {
+	l.RLock()
+	t := c.syncDownloadAttempts
+	l.RUnlock()
+
+	return t
+}
+
+func GetSyncChunkConcurrency() int {
+	l.RLock()
+	t := c.syncChunkConcurrency
+	l.RUnlock()
+
+	return t
+}
+
+func GetSyncReplayLimit() uint64 {
+	l.RLock()
+	t := c.syncReplayLimit
+	l.RUnlock()
+
+	return t
+}
+
 func GetPruningLimit() uint8 {
 	l.RLock()
 	t := c.pruningLimit
@@ -328,6 +517,66 @@ func GetSecret() string {
 	return t
 }
 
+// GetAPIRateLimit returns the rate limit of a group of http api routes.
+func GetAPIRateLimit(group string) APIRateLimit {
+	l.RLock()
+	t, ok := c.apiRateLimits[group]
+	if !ok {
+		t = c.apiRateLimits[""]
+	}
+	l.RUnlock()
+
+	return t
+}
+
+// GetAPIRateLimitTier returns the multiplier of the http api rate limits of clients bearing the
+// given token, and whether or not the token belongs to any tier.
+func GetAPIRateLimitTier(token string) (float64, bool) {
+	l.RLock()
+	t, ok := c.apiRateLimitTiers[token]
+	l.RUnlock()
+
+	return t, ok
+}
+
+// GetAPIKey returns the http api key whose token is the given token, if any.
+func GetAPIKey(token string) (APIKey, bool) {
+	l.RLock()
+	t, ok := c.apiKeys[token]
+	l.RUnlock()
+
+	return t, ok
+}
+
+// IsAPIPublicScope returns whether or not the http api routes of a scope may be accessed without
+// a key.
+func IsAPIPublicScope(scope string) bool {
+	l.RLock()
+	t := hasScope(c.apiPublicScopes, scope)
+	l.RUnlock()
+
+	return t
+}
+
+// GetAPIWebsocketPolicy returns the policy applied to websocket clients whose queue of events is
+// full.
+func GetAPIWebsocketPolicy() string {
+	l.RLock()
+	t := c.apiWebsocketPolicy
+	l.RUnlock()
+
+	return t
+}
+
+// GetAPIWebsocketQueueSize returns the max number of events queued to a single websocket client.
+func GetAPIWebsocketQueueSize() int {
+	l.RLock()
+	t := c.apiWebsocketQueueSize
+	l.RUnlock()
+
+	return t
+}
+
 func GetTXSyncChunkSize() uint64 {
 	l.RLock()
 	t := c.txSyncChunkSize
@@ -352,6 +601,14 @@ func GetBlockTXLimit() uint64 {
 	return t
 }
 
+func GetPeerBanDuration() time.Duration {
+	l.RLock()
+	t := c.peerBanDuration
+	l.RUnlock()
+
+	return t
+}
+
 func GetMissingTxPullLimit() uint64 {
 	l.RLock()
 	t := c.missingTxPullLimit
@@ -26,8 +26,16 @@ func TestGet(t *testing.T) {
 
 	assert.EqualValues(t, 16384, GetSyncChunkSize())
 	assert.EqualValues(t, uint64(5), GetSyncIfBlockIndicesDifferBy())
+	assert.EqualValues(t, 3, GetSyncDownloadAttempts())
+	assert.EqualValues(t, 16, GetSyncChunkConcurrency())
+	assert.EqualValues(t, 20, GetSyncReplayLimit())
 	assert.EqualValues(t, 30, GetPruningLimit())
 	assert.EqualValues(t, "", GetSecret())
+	assert.EqualValues(t, 5*time.Minute, GetPeerBanDuration())
+	assert.EqualValues(t, APIRateLimit{PerSecond: 1000, Burst: 1000}, GetAPIRateLimit("tx"))
+
+	_, ok := GetAPIRateLimitTier("")
+	assert.False(t, ok)
 }
 
 func TestUpdate(t *testing.T) {
@@ -49,8 +57,14 @@ func TestUpdate(t *testing.T) {
 
 		WithSyncChunkSize(666),
 		WithSyncIfBlockIndicesDifferBy(7),
+		WithSyncDownloadAttempts(4),
+		WithSyncChunkConcurrency(8),
+		WithSyncReplayLimit(9),
 		WithPruningLimit(13),
 		WithSecret("shambles"),
+		WithPeerBanDuration(time.Second*42),
+		WithAPIRateLimit("tx", APIRateLimit{PerSecond: 5, Burst: 10}),
+		WithAPIRateLimitTier("token", 10),
 	)
 
 	assert.EqualValues(t, 10, GetSnowballK())
@@ -68,8 +82,18 @@ func TestUpdate(t *testing.T) {
 
 	assert.EqualValues(t, 666, GetSyncChunkSize())
 	assert.EqualValues(t, 7, GetSyncIfBlockIndicesDifferBy())
+	assert.EqualValues(t, 4, GetSyncDownloadAttempts())
+	assert.EqualValues(t, 8, GetSyncChunkConcurrency())
+	assert.EqualValues(t, 9, GetSyncReplayLimit())
 	assert.EqualValues(t, 13, GetPruningLimit())
 	assert.EqualValues(t, "shambles", GetSecret())
+	assert.EqualValues(t, 42*time.Second, GetPeerBanDuration())
+	assert.EqualValues(t, APIRateLimit{PerSecond: 5, Burst: 10}, GetAPIRateLimit("tx"))
+	assert.EqualValues(t, APIRateLimit{PerSecond: 1000, Burst: 1000}, GetAPIRateLimit("ledger"))
+
+	multiplier, ok := GetAPIRateLimitTier("token")
+	assert.True(t, ok)
+	assert.EqualValues(t, 10, multiplier)
 }
 
 func resetConfig() {
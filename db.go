@@ -27,6 +27,7 @@ import (
 	"github.com/perlin-network/wavelet/avl"
 	"github.com/perlin-network/wavelet/store"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
 	"io"
 	"strconv"
 )
@@ -41,6 +42,11 @@ var (
 	keyBlockStoredCount     = [...]byte{0x6}
 	keyRewardWithdrawals    = [...]byte{0x7}
 	keyTransactionFinalized = [...]byte{0x8}
+	keySyncCheckpoint       = [...]byte{0x9}
+	keySyncChunks           = [...]byte{0xa}
+	keyBlockArchive         = [...]byte{0xb}
+	keyTransactionArchive   = [...]byte{0xc}
+	keyArchiveGaps          = [...]byte{0xd}
 
 	// Account-local prefixes.
 	keyAccountBalance            = [...]byte{0x2}
@@ -416,3 +422,237 @@ func LoadFinalizedTransactionIDs(tree *avl.Tree) []TransactionID {
 
 	return ids
 }
+
+// StoreSyncCheckpoint records the block and the checksums of all chunks of state of a sync that is
+// in progress, such that the sync may be resumed should our node restart.
+func StoreSyncCheckpoint(kv store.KV, block Block, checksums [][blake2b.Size256]byte) error {
+	buf := make([]byte, 4, 4+len(checksums)*blake2b.Size256)
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(checksums)))
+
+	for _, checksum := range checksums {
+		buf = append(buf, checksum[:]...)
+	}
+
+	buf = append(buf, block.Marshal()...)
+
+	if err := kv.Put(keySyncCheckpoint[:], buf); err != nil {
+		return errors.Wrap(err, "error storing sync checkpoint")
+	}
+
+	return nil
+}
+
+// LoadSyncCheckpoint loads the block and the checksums of all chunks of state of a sync that was
+// in progress. It returns store.ErrNotFound should there be no sync in progress.
+func LoadSyncCheckpoint(kv store.KV) (Block, [][blake2b.Size256]byte, error) {
+	buf, err := kv.Get(keySyncCheckpoint[:])
+	if err != nil {
+		return Block{}, nil, err
+	}
+
+	if len(buf) < 4 {
+		return Block{}, nil, errors.New("sync checkpoint is corrupt")
+	}
+
+	n := int(binary.BigEndian.Uint32(buf[:4]))
+	buf = buf[4:]
+
+	if len(buf) < n*blake2b.Size256 {
+		return Block{}, nil, errors.New("sync checkpoint is corrupt")
+	}
+
+	checksums := make([][blake2b.Size256]byte, n)
+
+	for i := range checksums {
+		copy(checksums[i][:], buf[i*blake2b.Size256:])
+	}
+
+	block, err := UnmarshalBlock(bytes.NewReader(buf[n*blake2b.Size256:]))
+	if err != nil {
+		return Block{}, nil, errors.Wrap(err, "error unmarshaling sync checkpoint block")
+	}
+
+	return block, checksums, nil
+}
+
+// ClearSyncCheckpoint deletes the checkpoint of a sync that was in progress, alongside all chunks of
+// state that were downloaded for any sync.
+func ClearSyncCheckpoint(kv store.KV) error {
+	var keys [][]byte
+
+	it := store.NewPrefixIterator(kv, keySyncChunks[:])
+
+	for it.Next() {
+		keys = append(keys, append([]byte{}, it.Key()...))
+	}
+
+	err := it.Error()
+	it.Release()
+
+	if err != nil {
+		return errors.Wrap(err, "error iterating over sync chunks")
+	}
+
+	for _, key := range keys {
+		if err := kv.Delete(key); err != nil {
+			return errors.Wrap(err, "error deleting sync chunk")
+		}
+	}
+
+	if err := kv.Delete(keySyncCheckpoint[:]); err != nil {
+		return errors.Wrap(err, "error deleting sync checkpoint")
+	}
+
+	return nil
+}
+
+// StoreSyncChunk persists a chunk of state downloaded while syncing, keyed by its checksum.
+func StoreSyncChunk(kv store.KV, checksum [blake2b.Size256]byte, chunk []byte) error {
+	if err := kv.Put(append(keySyncChunks[:], checksum[:]...), chunk); err != nil {
+		return errors.Wrap(err, "error storing sync chunk")
+	}
+
+	return nil
+}
+
+// LoadSyncChunk loads a chunk of state downloaded while syncing given its checksum. Should the chunk
+// on disk no longer match its checksum, it is deleted and an error is returned.
+func LoadSyncChunk(kv store.KV, checksum [blake2b.Size256]byte) ([]byte, error) {
+	key := append(keySyncChunks[:], checksum[:]...)
+
+	chunk, err := kv.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if blake2b.Sum256(chunk) != checksum {
+		_ = kv.Delete(key)
+		return nil, errors.Errorf("sync chunk %x is corrupt", checksum)
+	}
+
+	return chunk, nil
+}
+
+// StoreArchivedBlock persists a finalized block indexed by its height, such that it may still be
+// loaded once it has been evicted from the ring of recent blocks.
+func StoreArchivedBlock(kv store.KV, block Block) error {
+	var buf [8]byte
+
+	binary.BigEndian.PutUint64(buf[:], block.Index)
+
+	if err := kv.Put(append(keyBlockArchive[:], buf[:]...), block.Marshal()); err != nil {
+		return errors.Wrap(err, "error storing archived block")
+	}
+
+	return nil
+}
+
+// LoadArchivedBlock loads a finalized block at the given height that was archived. It returns
+// store.ErrNotFound should the block not have been archived.
+func LoadArchivedBlock(kv store.KV, index uint64) (*Block, error) {
+	var buf [8]byte
+
+	binary.BigEndian.PutUint64(buf[:], index)
+
+	b, err := kv.Get(append(keyBlockArchive[:], buf[:]...))
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := UnmarshalBlock(bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling archived block")
+	}
+
+	return &block, nil
+}
+
+// StoreArchivedTransactions persists finalized transactions indexed by their IDs, such that they
+// may still be loaded once they have been pruned from memory.
+func StoreArchivedTransactions(kv store.KV, txs ...*Transaction) error {
+	batch := kv.NewWriteBatch()
+
+	for _, tx := range txs {
+		if err := batch.Put(append(keyTransactionArchive[:], tx.ID[:]...), tx.Marshal()); err != nil {
+			return errors.Wrap(err, "error storing archived transaction")
+		}
+	}
+
+	if err := kv.CommitWriteBatch(batch); err != nil {
+		return errors.Wrap(err, "error committing archived transactions")
+	}
+
+	return nil
+}
+
+// LoadArchivedTransaction loads a finalized transaction that was archived given its ID. It returns
+// store.ErrNotFound should the transaction not have been archived.
+func LoadArchivedTransaction(kv store.KV, id TransactionID) (*Transaction, error) {
+	b, err := kv.Get(append(keyTransactionArchive[:], id[:]...))
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := UnmarshalTransaction(bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling archived transaction")
+	}
+
+	return &tx, nil
+}
+
+// ArchiveGap is an inclusive range of block heights which an archive node skipped over by syncing
+// state rather than replaying blocks. The blocks and transactions finalized within the range may
+// be missing from the archive.
+type ArchiveGap struct {
+	From uint64
+	To   uint64
+}
+
+// StoreArchiveGap records a range of block heights the archive is missing blocks or transactions
+// from.
+func StoreArchiveGap(kv store.KV, gap ArchiveGap) error {
+	buf, err := kv.Get(keyArchiveGaps[:])
+	if err != nil && errors.Cause(err) != store.ErrNotFound {
+		return errors.Wrap(err, "error loading archive gaps")
+	}
+
+	var entry [16]byte
+
+	binary.BigEndian.PutUint64(entry[:8], gap.From)
+	binary.BigEndian.PutUint64(entry[8:], gap.To)
+
+	if err := kv.Put(keyArchiveGaps[:], append(buf, entry[:]...)); err != nil {
+		return errors.Wrap(err, "error storing archive gap")
+	}
+
+	return nil
+}
+
+// LoadArchiveGaps loads all ranges of block heights the archive is missing blocks or transactions
+// from, in the order they were recorded.
+func LoadArchiveGaps(kv store.KV) ([]ArchiveGap, error) {
+	buf, err := kv.Get(keyArchiveGaps[:])
+	if err != nil {
+		if errors.Cause(err) == store.ErrNotFound {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "error loading archive gaps")
+	}
+
+	if len(buf)%16 != 0 {
+		return nil, errors.Errorf("archive gaps are malformed: got %d bytes", len(buf))
+	}
+
+	gaps := make([]ArchiveGap, 0, len(buf)/16)
+
+	for i := 0; i < len(buf); i += 16 {
+		gaps = append(gaps, ArchiveGap{
+			From: binary.BigEndian.Uint64(buf[i : i+8]),
+			To:   binary.BigEndian.Uint64(buf[i+8 : i+16]),
+		})
+	}
+
+	return gaps, nil
+}
@@ -28,9 +28,13 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/perlin-network/noise/skademlia"
 	"github.com/perlin-network/wavelet/avl"
 	"github.com/perlin-network/wavelet/store"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
 )
 
 func TestRewardWithdrawals(t *testing.T) {
@@ -230,3 +234,112 @@ func BenchmarkWriteAccountContractPage(b *testing.B) {
 	_, exist := ReadAccountContractPage(state, id, 0)
 	assert.True(b, exist)
 }
+
+func TestSyncCheckpoint(t *testing.T) {
+	kv := store.NewInmem()
+
+	_, _, err := LoadSyncCheckpoint(kv)
+	assert.Equal(t, store.ErrNotFound, errors.Cause(err))
+
+	chunks := [][]byte{[]byte("hello"), []byte("world")}
+	checksums := make([][blake2b.Size256]byte, len(chunks))
+
+	for i, chunk := range chunks {
+		checksums[i] = blake2b.Sum256(chunk)
+		assert.NoError(t, StoreSyncChunk(kv, checksums[i], chunk))
+	}
+
+	block := NewBlock(42, MerkleNodeID{1, 2, 3})
+	assert.NoError(t, StoreSyncCheckpoint(kv, block, checksums))
+
+	loadedBlock, loadedChecksums, err := LoadSyncCheckpoint(kv)
+	assert.NoError(t, err)
+	assert.Equal(t, block.ID, loadedBlock.ID)
+	assert.Equal(t, checksums, loadedChecksums)
+
+	for i, checksum := range checksums {
+		chunk, err := LoadSyncChunk(kv, checksum)
+		assert.NoError(t, err)
+		assert.Equal(t, chunks[i], chunk)
+	}
+
+	// Chunks which no longer match their checksum are discarded.
+	assert.NoError(t, kv.Put(append(keySyncChunks[:], checksums[0][:]...), []byte("corrupt")))
+
+	_, err = LoadSyncChunk(kv, checksums[0])
+	assert.Error(t, err)
+
+	_, err = LoadSyncChunk(kv, checksums[0])
+	assert.Equal(t, store.ErrNotFound, errors.Cause(err))
+
+	assert.NoError(t, ClearSyncCheckpoint(kv))
+
+	_, _, err = LoadSyncCheckpoint(kv)
+	assert.Equal(t, store.ErrNotFound, errors.Cause(err))
+
+	_, err = LoadSyncChunk(kv, checksums[1])
+	assert.Equal(t, store.ErrNotFound, errors.Cause(err))
+}
+
+func TestArchive(t *testing.T) {
+	kv := store.NewInmem()
+
+	keys, err := skademlia.NewKeys(1, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	a := NewTransaction(keys, 1, 0, sys.TagTransfer, []byte("a"))
+	b := NewTransaction(keys, 2, 0, sys.TagTransfer, []byte("b"))
+	txs := []*Transaction{&a, &b}
+
+	_, err = LoadArchivedTransaction(kv, txs[0].ID)
+	assert.Equal(t, store.ErrNotFound, errors.Cause(err))
+
+	assert.NoError(t, StoreArchivedTransactions(kv, txs...))
+
+	for _, tx := range txs {
+		loaded, err := LoadArchivedTransaction(kv, tx.ID)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, tx.ID, loaded.ID)
+		assert.Equal(t, tx.Payload, loaded.Payload)
+	}
+
+	_, err = LoadArchivedBlock(kv, 42)
+	assert.Equal(t, store.ErrNotFound, errors.Cause(err))
+
+	block := NewBlock(42, MerkleNodeID{1, 2, 3}, txs[0].ID, txs[1].ID)
+	assert.NoError(t, StoreArchivedBlock(kv, block))
+
+	loaded, err := LoadArchivedBlock(kv, 42)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, block.ID, loaded.ID)
+	assert.Equal(t, block.Transactions, loaded.Transactions)
+}
+
+func TestArchiveGaps(t *testing.T) {
+	kv := store.NewInmem()
+
+	gaps, err := LoadArchiveGaps(kv)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Empty(t, gaps)
+
+	assert.NoError(t, StoreArchiveGap(kv, ArchiveGap{From: 3, To: 40}))
+	assert.NoError(t, StoreArchiveGap(kv, ArchiveGap{From: 50, To: 51}))
+
+	gaps, err = LoadArchiveGaps(kv)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []ArchiveGap{{From: 3, To: 40}, {From: 50, To: 51}}, gaps)
+}
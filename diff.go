@@ -0,0 +1,186 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/perlin-network/wavelet/avl"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Uint64Change is a change to a numeric field of an account.
+type Uint64Change struct {
+	Before uint64
+	After  uint64
+}
+
+// CodeChange is a change to the code of a smart contract, described by checksums of the code
+// before and after the change. A zero checksum denotes that there was no code.
+type CodeChange struct {
+	Before [blake2b.Size256]byte
+	After  [blake2b.Size256]byte
+}
+
+// AccountDiff describes all changes made to the state of a single account between two states.
+// Fields which did not change are nil.
+type AccountDiff struct {
+	ID AccountID
+
+	Balance    *Uint64Change
+	Stake      *Uint64Change
+	Reward     *Uint64Change
+	GasBalance *Uint64Change
+	NumPages   *Uint64Change
+
+	Code *CodeChange
+
+	// Pages holds the indices of all memory pages of a smart contract which changed, in
+	// ascending order.
+	Pages []uint64
+
+	Globals bool
+}
+
+// DiffState decodes all changes made to accounts between two states, including accounts and
+// fields which were deleted. Only the parts of both states which differ are visited, which makes
+// it cheap to compare states which are not far apart. It returns account diffs sorted by account
+// ID.
+func DiffState(from, to *avl.Tree) []AccountDiff {
+	diffs := make(map[AccountID]*AccountDiff)
+	seen := make(map[string]struct{})
+
+	visit := func(key []byte) bool {
+		if _, exists := seen[string(key)]; exists {
+			return true
+		}
+
+		seen[string(key)] = struct{}{}
+
+		before, _ := from.Lookup(key)
+		after, _ := to.Lookup(key)
+
+		if bytes.Equal(before, after) {
+			return true
+		}
+
+		id, field, page, ok := decodeAccountKey(key)
+		if !ok {
+			return true
+		}
+
+		diff, exists := diffs[id]
+		if !exists {
+			diff = &AccountDiff{ID: id}
+			diffs[id] = diff
+		}
+
+		switch field {
+		case keyAccountBalance[0]:
+			diff.Balance = decodeUint64Change(before, after)
+		case keyAccountStake[0]:
+			diff.Stake = decodeUint64Change(before, after)
+		case keyAccountReward[0]:
+			diff.Reward = decodeUint64Change(before, after)
+		case keyAccountContractGasBalance[0]:
+			diff.GasBalance = decodeUint64Change(before, after)
+		case keyAccountContractNumPages[0]:
+			diff.NumPages = decodeUint64Change(before, after)
+		case keyAccountContractCode[0]:
+			diff.Code = &CodeChange{}
+
+			if len(before) > 0 {
+				diff.Code.Before = blake2b.Sum256(before)
+			}
+
+			if len(after) > 0 {
+				diff.Code.After = blake2b.Sum256(after)
+			}
+		case keyAccountContractPages[0]:
+			diff.Pages = append(diff.Pages, page)
+		case keyAccountContractGlobals[0]:
+			diff.Globals = true
+		}
+
+		return true
+	}
+
+	avl.IterateLeafChanges(from, to, visit)
+
+	accounts := make([]AccountDiff, 0, len(diffs))
+
+	for _, diff := range diffs {
+		sort.Slice(diff.Pages, func(i, j int) bool {
+			return diff.Pages[i] < diff.Pages[j]
+		})
+
+		accounts = append(accounts, *diff)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return bytes.Compare(accounts[i].ID[:], accounts[j].ID[:]) < 0
+	})
+
+	return accounts
+}
+
+// decodeAccountKey decodes the ID of the account, the field, and the index of the memory page, if
+// any, that a key of the state is stored under.
+func decodeAccountKey(key []byte) (id AccountID, field byte, page uint64, ok bool) {
+	if len(key) < len(keyAccounts)+1+SizeAccountID || key[0] != keyAccounts[0] {
+		return id, 0, 0, false
+	}
+
+	field = key[len(keyAccounts)]
+	rest := key[len(keyAccounts)+1:]
+
+	if field == keyAccountContractPages[0] {
+		if len(rest) != 8+SizeAccountID {
+			return id, 0, 0, false
+		}
+
+		page = binary.LittleEndian.Uint64(rest[:8])
+		rest = rest[8:]
+	}
+
+	if len(rest) != SizeAccountID {
+		return id, 0, 0, false
+	}
+
+	copy(id[:], rest)
+
+	return id, field, page, true
+}
+
+func decodeUint64Change(before, after []byte) *Uint64Change {
+	var change Uint64Change
+
+	if len(before) >= 8 {
+		change.Before = binary.LittleEndian.Uint64(before)
+	}
+
+	if len(after) >= 8 {
+		change.After = binary.LittleEndian.Uint64(after)
+	}
+
+	return &change
+}
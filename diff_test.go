@@ -0,0 +1,166 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package wavelet
+
+import (
+	"testing"
+
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestDiffState(t *testing.T) {
+	// Archive roots, such that earlier states may be loaded by their merkle roots.
+	tree := avl.New(store.NewInmem()).WithArchive(true)
+
+	alice := AccountID{1}
+	bob := AccountID{2}
+	contract := TransactionID{3}
+
+	WriteAccountBalance(tree, alice, 100)
+	WriteAccountBalance(tree, bob, 50)
+	WriteAccountStake(tree, bob, 10)
+	WriteAccountsLen(tree, 2)
+
+	assert.NoError(t, tree.Commit())
+
+	from := tree.Snapshot()
+
+	tree.SetViewID(1)
+
+	WriteAccountBalance(tree, alice, 90)
+	WriteAccountBalance(tree, bob, 50) // Unchanged value.
+	WriteAccountReward(tree, bob, 5)
+	WriteAccountContractCode(tree, contract, []byte("code"))
+	WriteAccountContractNumPages(tree, contract, 2)
+	WriteAccountContractPage(tree, contract, 1, []byte("page"))
+	WriteAccountContractGasBalance(tree, contract, 7)
+	WriteAccountContractGlobals(tree, contract, []byte("globals"))
+	WriteAccountsLen(tree, 3)
+
+	assert.NoError(t, tree.Commit())
+
+	to := tree.Snapshot()
+
+	expected := []AccountDiff{
+		{
+			ID:      alice,
+			Balance: &Uint64Change{Before: 100, After: 90},
+		},
+		{
+			ID:     bob,
+			Reward: &Uint64Change{Before: 0, After: 5},
+		},
+		{
+			ID:         contract,
+			GasBalance: &Uint64Change{Before: 0, After: 7},
+			NumPages:   &Uint64Change{Before: 0, After: 2},
+			Code:       &CodeChange{After: blake2b.Sum256([]byte("code"))},
+			Pages:      []uint64{1},
+			Globals:    true,
+		},
+	}
+
+	assert.Equal(t, expected, DiffState(from, to))
+
+	// Diffs are symmetric.
+	reversed := DiffState(to, from)
+	if assert.Len(t, reversed, 3) {
+		assert.Equal(t, &Uint64Change{Before: 90, After: 100}, reversed[0].Balance)
+	}
+
+	// States may be loaded by their merkle roots.
+	loaded, err := tree.SnapshotWithRoot(from.Checksum())
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, DiffState(loaded, to))
+	}
+
+	assert.Empty(t, DiffState(to, to))
+}
+
+func TestDiffState_Deletions(t *testing.T) {
+	tree := avl.New(store.NewInmem())
+
+	accounts := make([]AccountID, 32)
+
+	for i := range accounts {
+		accounts[i] = AccountID{byte(i + 1)}
+
+		WriteAccountBalance(tree, accounts[i], 100)
+		WriteAccountStake(tree, accounts[i], 10)
+	}
+
+	assert.NoError(t, tree.Commit())
+
+	from := tree.Snapshot()
+
+	// The stake of the account was written long before the view it is deleted in.
+	tree.SetViewID(1)
+
+	key := append(append(keyAccounts[:], keyAccountStake[:]...), accounts[7][:]...)
+	assert.True(t, tree.Delete(key))
+
+	assert.NoError(t, tree.Commit())
+
+	to := tree.Snapshot()
+
+	expected := []AccountDiff{{ID: accounts[7], Stake: &Uint64Change{Before: 10, After: 0}}}
+
+	assert.Equal(t, expected, DiffState(from, to))
+	assert.Equal(t, []AccountDiff{{ID: accounts[7], Stake: &Uint64Change{Before: 0, After: 10}}}, DiffState(to, from))
+}
+
+func TestDiffState_Forks(t *testing.T) {
+	tree := avl.New(store.NewInmem())
+
+	alice := AccountID{1}
+	bob := AccountID{2}
+
+	WriteAccountBalance(tree, alice, 100)
+	WriteAccountBalance(tree, bob, 100)
+
+	base := tree.Snapshot()
+
+	// Both forks diverge two views before the view they are at.
+	fork := func(balance uint64) *avl.Tree {
+		tree := base.Snapshot()
+
+		tree.SetViewID(1)
+		WriteAccountBalance(tree, alice, balance)
+
+		tree.SetViewID(2)
+		WriteAccountStake(tree, bob, 1)
+
+		tree.SetViewID(3)
+		WriteAccountReward(tree, bob, 1)
+
+		return tree
+	}
+
+	a, b := fork(90), fork(80)
+
+	expected := []AccountDiff{{ID: alice, Balance: &Uint64Change{Before: 90, After: 80}}}
+
+	assert.Equal(t, expected, DiffState(a, b))
+}
@@ -31,6 +31,7 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0
 	github.com/valyala/fasthttp v1.34.0
 	github.com/valyala/fastjson v1.4.1
+	go.etcd.io/bbolt v1.3.5
 	go.uber.org/atomic v1.5.0
 	golang.org/x/crypto v0.35.0
 	golang.org/x/net v0.36.0
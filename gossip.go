@@ -31,16 +31,20 @@ import (
 )
 
 type Gossiper struct {
-	client  *skademlia.Client
-	metrics *Metrics
+	client     *skademlia.Client
+	metrics    *Metrics
+	reputation *PeerReputation
 
 	debouncer *debounce.Limiter
 }
 
-func NewGossiper(ctx context.Context, client *skademlia.Client, metrics *Metrics) *Gossiper {
+func NewGossiper(
+	ctx context.Context, client *skademlia.Client, metrics *Metrics, reputation *PeerReputation,
+) *Gossiper {
 	g := &Gossiper{
-		client:  client,
-		metrics: metrics,
+		client:     client,
+		metrics:    metrics,
+		reputation: reputation,
 	}
 
 	g.debouncer = debounce.NewLimiter(
@@ -64,7 +68,7 @@ func (g *Gossiper) Push(tx Transaction) {
 func (g *Gossiper) Gossip(transactions [][]byte) {
 	logger := log.TX("gossip")
 
-	peers, err := SelectPeers(g.client.ClosestPeers(), conf.GetSnowballK())
+	peers, err := SelectPeers(g.client.ClosestPeers(), conf.GetSnowballK(), g.reputation)
 	if err != nil {
 		logger.Err(err).Msg("Failed to select peers to gossip transactions.")
 		return
@@ -88,6 +92,7 @@ func (g *Gossiper) Gossip(transactions [][]byte) {
 			_, err := client.Gossip(ctx, batch)
 			if err != nil {
 				logger.Err(err).Msg("Failed to send batch")
+				g.reputation.Record(p.ID().PublicKey(), ReputationUnresponsive)
 			}
 		}(p)
 	}
@@ -58,9 +58,12 @@ type Ledger struct {
 	blocks       *Blocks
 	transactions *Transactions
 	db           store.KV
+	archive      bool
 
-	gossiper  *Gossiper
-	finalizer *Snowball
+	gossiper   *Gossiper
+	finalizer  *Snowball
+	sampler    PeerSampler
+	reputation *PeerReputation
 
 	consensus     sync.WaitGroup
 	consensusStop chan struct{}
@@ -88,6 +91,8 @@ type config struct {
 	GCDisabled  bool
 	Genesis     *string
 	MaxMemoryMB uint64
+	Archive     bool
+	Sampler     PeerSampler
 }
 
 type Option func(cfg *config)
@@ -111,8 +116,25 @@ func WithMaxMemoryMB(n uint64) Option {
 	}
 }
 
+// WithArchive retains the state of all accounts at every finalized block height, such
+// that historical state may be queried through SnapshotAt. Every finalized block and its
+// transactions are persisted as well, such that they are never pruned.
+func WithArchive() Option {
+	return func(cfg *config) {
+		cfg.Archive = true
+	}
+}
+
+// WithPeerSampler sets the strategy used to sample peers while performing consensus. By
+// default, peers are sampled uniformly at random.
+func WithPeerSampler(sampler PeerSampler) Option {
+	return func(cfg *config) {
+		cfg.Sampler = sampler
+	}
+}
+
 func NewLedger(kv store.KV, client *skademlia.Client, opts ...Option) (*Ledger, error) {
-	var cfg config
+	cfg := config{Sampler: UniformSampler{}}
 
 	for _, opt := range opts {
 		opt(&cfg)
@@ -120,16 +142,18 @@ func NewLedger(kv store.KV, client *skademlia.Client, opts ...Option) (*Ledger,
 
 	metrics := NewMetrics(context.TODO())
 	indexer := radix.NewIndexer()
-	accounts := NewAccounts(kv)
+	accounts := NewAccounts(kv).WithArchive(cfg.Archive)
 
 	var block *Block
 
 	blocks, err := NewBlocks(kv, conf.GetPruningLimit())
-	if err != nil {
-		if errors.Cause(err) != store.ErrNotFound {
-			return nil, errors.Wrap(err, "error getting blocks from db")
-		}
+	if err != nil && errors.Cause(err) != store.ErrNotFound {
+		return nil, errors.Wrap(err, "error getting blocks from db")
+	}
+
+	blocks = blocks.WithArchive(cfg.Archive)
 
+	if err != nil {
 		genesis := performInception(accounts.tree, cfg.Genesis)
 
 		if err := accounts.Commit(nil); err != nil {
@@ -150,12 +174,14 @@ func NewLedger(kv store.KV, client *skademlia.Client, opts ...Option) (*Ledger,
 	transactions := NewTransactions(*block)
 	transactions.BatchMarkFinalized(LoadFinalizedTransactionIDs(accounts.tree)...)
 
-	gossiper := NewGossiper(context.TODO(), client, metrics)
+	reputation := NewPeerReputation()
+
+	gossiper := NewGossiper(context.TODO(), client, metrics, reputation)
 	finalizer := NewSnowball()
 
 	filePool := filebuffer.NewPool(sys.SyncPooledFileSize, "")
 
-	syncManager := NewSyncManager(client, accounts, blocks, filePool)
+	syncManager := NewSyncManager(client, kv, accounts, blocks, cfg.Sampler, reputation, filePool)
 
 	ledger := &Ledger{
 		client:  client,
@@ -166,9 +192,12 @@ func NewLedger(kv store.KV, client *skademlia.Client, opts ...Option) (*Ledger,
 		blocks:       blocks,
 		transactions: transactions,
 		db:           kv,
+		archive:      cfg.Archive,
 
-		gossiper:  gossiper,
-		finalizer: finalizer,
+		gossiper:   gossiper,
+		finalizer:  finalizer,
+		sampler:    cfg.Sampler,
+		reputation: reputation,
 
 		filePool:    filePool,
 		syncManager: syncManager,
@@ -217,6 +246,22 @@ func NewLedger(kv store.KV, client *skademlia.Client, opts ...Option) (*Ledger,
 
 		ledger.transactions.BatchMarkFinalized(LoadFinalizedTransactionIDs(accounts.tree)...)
 
+		if latest := ledger.blocks.Latest(); ledger.archive && block.Index > latest.Index {
+			gap := ArchiveGap{From: latest.Index + 1, To: block.Index}
+
+			logger := log.Node()
+			logger.Warn().
+				Uint64("from_block_height", gap.From).
+				Uint64("to_block_height", gap.To).
+				Msg("Synced state past blocks we could not replay. The archive is missing their history.")
+
+			if err := StoreArchiveGap(kv, gap); err != nil {
+				logger.Error().
+					Err(err).
+					Msg("Failed to record the gap in the archive")
+			}
+		}
+
 		if _, err = ledger.blocks.Save(&block); err != nil {
 			logger := log.Node()
 			logger.Error().
@@ -228,7 +273,18 @@ func NewLedger(kv store.KV, client *skademlia.Client, opts ...Option) (*Ledger,
 		ledger.PerformConsensus()
 	})
 
-	if !cfg.GCDisabled {
+	syncManager.ReplayBlocks = func() bool {
+		if !ledger.replayBlocks() {
+			return false
+		}
+
+		ledger.consensusStop = make(chan struct{})
+		ledger.PerformConsensus()
+
+		return true
+	}
+
+	if !cfg.GCDisabled && !cfg.Archive {
 		ctx, cancel := context.WithCancel(context.Background())
 
 		ledger.stopWG.Add(1)
@@ -360,11 +416,53 @@ func (l *Ledger) Blocks() *Blocks {
 	return l.blocks
 }
 
+// Reputation returns the reputation of all peers the ledger has interacted with.
+func (l *Ledger) Reputation() *PeerReputation {
+	return l.reputation
+}
+
+// Metrics returns the metrics of the ledger.
+func (l *Ledger) Metrics() *Metrics {
+	return l.metrics
+}
+
+// SyncProgress returns how far along the ledger is in downloading the latest state from its
+// peers, should it have fallen out of sync.
+func (l *Ledger) SyncProgress() SyncProgress {
+	return l.syncManager.Progress()
+}
+
 // Transactions returns the transaction manager for the ledger.
 func (l *Ledger) Transactions() *Transactions {
 	return l.transactions
 }
 
+// FindTransaction returns a transaction given its ID. Should the ledger be running in archive
+// mode, transactions that have been pruned from memory are loaded from the database. It returns
+// nil should the transaction not be found.
+func (l *Ledger) FindTransaction(id TransactionID) *Transaction {
+	if tx := l.transactions.Find(id); tx != nil {
+		return tx
+	}
+
+	if !l.archive {
+		return nil
+	}
+
+	tx, err := LoadArchivedTransaction(l.db, id)
+	if err != nil {
+		return nil
+	}
+
+	return tx
+}
+
+// TransactionReceipt returns the outcome of a transaction which was finalized in a block, should
+// the transaction not yet have been pruned.
+func (l *Ledger) TransactionReceipt(id TransactionID) (TransactionReceipt, bool) {
+	return l.transactions.Receipt(id)
+}
+
 // Restart restart wavelet process by means of stall detector (approach is platform dependent)
 func (l *Ledger) Restart() error {
 	return l.stallDetector.TryRestart()
@@ -387,6 +485,23 @@ func (l *Ledger) Snapshot() *avl.Tree {
 	return l.accounts.Snapshot()
 }
 
+// SnapshotAt returns a snapshot of all accounts as they were once the block at the given
+// height was finalized. Heights other than the latest one require the ledger to be
+// running in archive mode.
+func (l *Ledger) SnapshotAt(height uint64) (*avl.Tree, error) {
+	if height == l.blocks.LatestHeight() {
+		return l.Snapshot(), nil
+	}
+
+	return l.accounts.SnapshotAt(height)
+}
+
+// SnapshotWithRoot returns a snapshot of all accounts whose merkle root is the given one. Only
+// the latest state may be loaded, unless the ledger archives historical states.
+func (l *Ledger) SnapshotWithRoot(root MerkleNodeID) (*avl.Tree, error) {
+	return l.accounts.SnapshotWithRoot(root)
+}
+
 // SyncTransactions is an infinite loop which constantly sends transaction ids from its index
 // into a Cuckoo Filter to randomly sampled number of peers and adds to it's state all received
 // transactions.
@@ -402,7 +517,7 @@ func (l *Ledger) SyncTransactions() { // nolint:gocognit
 
 		snowballK := conf.GetSnowballK()
 
-		peers, err := SelectPeers(l.client.ClosestPeers(), snowballK)
+		peers, err := SelectPeers(l.client.ClosestPeers(), snowballK, l.reputation)
 		if err != nil {
 			continue
 		}
@@ -431,7 +546,7 @@ func (l *Ledger) SyncTransactions() { // nolint:gocognit
 		for _, p := range peers {
 			wg.Add(1)
 
-			go func(conn *grpc.ClientConn) {
+			go func(conn *grpc.ClientConn, id AccountID) {
 				defer wg.Done()
 
 				ctx, cancel := context.WithTimeout(context.Background(), conf.GetDownloadTxTimeout())
@@ -440,6 +555,8 @@ func (l *Ledger) SyncTransactions() { // nolint:gocognit
 				stream, err := NewWaveletClient(conn).SyncTransactions(ctx)
 				if err != nil {
 					logger.Error().Err(err).Msg("failed to create sync transactions stream")
+					l.reputation.Record(id, ReputationUnresponsive)
+
 					return
 				}
 
@@ -457,6 +574,8 @@ func (l *Ledger) SyncTransactions() { // nolint:gocognit
 				res, err := stream.Recv()
 				if err != nil {
 					logger.Error().Err(err).Msg("failed to receive sync transactions header")
+					l.reputation.Record(id, ReputationUnresponsive)
+
 					return
 				}
 
@@ -537,7 +656,7 @@ func (l *Ledger) SyncTransactions() { // nolint:gocognit
 					l.metrics.downloadedTX.Mark(int64(downloadedNum))
 					l.metrics.receivedTX.Mark(int64(downloadedNum))
 				}
-			}(p.Conn())
+			}(p.Conn(), p.ID().PublicKey())
 		}
 
 		wg.Wait()
@@ -559,7 +678,7 @@ func (l *Ledger) PullMissingTransactions() {
 
 		snowballK := conf.GetSnowballK()
 
-		peers, err := SelectPeers(l.client.ClosestPeers(), snowballK)
+		peers, err := SelectPeers(l.client.ClosestPeers(), snowballK, l.reputation)
 		if err != nil {
 			continue
 		}
@@ -588,7 +707,7 @@ func (l *Ledger) PullMissingTransactions() {
 		responseChan := make(chan response)
 
 		for _, p := range peers {
-			go func(conn *grpc.ClientConn) {
+			go func(conn *grpc.ClientConn, id AccountID) {
 				var response response
 
 				defer func() {
@@ -603,6 +722,7 @@ func (l *Ledger) PullMissingTransactions() {
 				batch, err := client.PullTransactions(ctx, req)
 				if err != nil {
 					logger.Error().Err(err).Msg("failed to download missing transactions")
+					l.reputation.Record(id, ReputationUnresponsive)
 
 					return
 				}
@@ -622,7 +742,7 @@ func (l *Ledger) PullMissingTransactions() {
 
 					response.txs = append(response.txs, tx)
 				}
-			}(p.Conn())
+			}(p.Conn(), p.ID().PublicKey())
 		}
 
 		count := int64(0)
@@ -738,7 +858,7 @@ func (l *Ledger) proposeBlock() *Block {
 
 	latest := l.blocks.Latest()
 
-	results, err := l.collapseTransactions(latest.Index+1, latest, proposing, false)
+	results, err := l.collapseTransactions(latest.Index+1, latest, proposing)
 	if err != nil {
 		logger := log.Node()
 		logger.Error().
@@ -758,25 +878,61 @@ func (l *Ledger) finalize(block Block) {
 
 	logger := log.Consensus("finalized")
 
-	results, err := l.collapseTransactions(block.Index, current, block.Transactions, true)
+	results, pruned, err := l.applyBlock(current, block)
 	if err != nil {
 		logger := log.Node()
 		logger.Error().
 			Err(err).
-			Msg("error collapsing transactions during finalization")
+			Uint64("target_block_id", block.Index).
+			Msg("Failed to finalize block")
 
 		return
 	}
 
+	// Reset sampler(s).
+	l.finalizer.Reset()
+
+	// Reset querying-related cache(s).
+	for id := range l.queryBlockValidCache {
+		delete(l.queryBlockValidCache, id)
+	}
+
+	logger.Info().
+		Int("num_applied_tx", results.appliedCount).
+		Int("num_rejected_tx", results.rejectedCount).
+		Int("num_pruned_tx", len(pruned)).
+		Uint64("old_block_height", current.Index).
+		Uint64("new_block_height", block.Index).
+		Hex("old_block_id", current.ID[:]).
+		Hex("new_block_id", block.ID[:]).
+		Msg("Finalized block.")
+}
+
+// applyBlock applies all transactions of a finalized block on top of the current block, and
+// persists both the block and the resulting state. It returns the results of collapsing the
+// transactions of the block, and the IDs of all transactions that were pruned as a result.
+func (l *Ledger) applyBlock(current *Block, block Block) (*collapseResults, []TransactionID, error) {
+	results, err := l.collapseTransactions(block.Index, current, block.Transactions)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error collapsing transactions during finalization")
+	}
+
 	if checksum := results.snapshot.Checksum(); checksum != block.Merkle {
-		logger := log.Node()
-		logger.Error().
-			Uint64("target_block_id", block.Index).
-			Hex("expected_merkle_root", block.Merkle[:]).
-			Hex("yielded_merkle_root", checksum[:]).
-			Msg("Merkle root does not match")
+		return nil, nil, errors.Errorf(
+			"merkle root does not match: expected %x but yielded %x",
+			block.Merkle, checksum,
+		)
+	}
 
-		return
+	if l.archive {
+		finalized, err := l.transactions.BatchFind(block.Transactions)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to find transactions to archive")
+		}
+
+		if err = StoreArchivedTransactions(l.db, finalized...); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to archive finalized transactions")
+		}
 	}
 
 	pruned := l.transactions.ReshufflePending(block)
@@ -787,51 +943,30 @@ func (l *Ledger) finalize(block Block) {
 	l.transactionFilterLock.Unlock()
 
 	if _, err = l.blocks.Save(&block); err != nil {
-		logger := log.Node()
-		logger.Error().
-			Err(err).
-			Msg("Failed to save preferred block to database")
-
-		return
+		return nil, nil, errors.Wrap(err, "failed to save preferred block to database")
 	}
 
 	if err = l.accounts.Commit(results.snapshot); err != nil {
-		logger := log.Node()
-		logger.Error().
-			Err(err).
-			Msg("Failed to commit collaped state to our database")
-
-		return
+		return nil, nil, errors.Wrap(err, "failed to commit collapsed state to our database")
 	}
 
 	l.metrics.acceptedTX.Mark(int64(results.appliedCount))
 	l.metrics.finalizedBlocks.Mark(1)
 
+	// Transactions are only announced as applied or rejected once the block they were finalized
+	// in, and the state they yielded, are persisted. Their receipts are recorded beforehand, such
+	// that a client which does not find the receipt of a transaction sees it announced instead.
+	l.transactions.BatchStoreReceipts(results.height, results.applied, results.rejected, results.rejectedErrors)
+	l.collapseResultsLogger.Log(results)
 	l.LogChanges(results)
 
-	// Reset sampler(s).
-	l.finalizer.Reset()
-
-	// Reset querying-related cache(s).
-	for id := range l.queryBlockValidCache {
-		delete(l.queryBlockValidCache, id)
-	}
-
-	logger.Info().
-		Int("num_applied_tx", results.appliedCount).
-		Int("num_rejected_tx", results.rejectedCount).
-		Int("num_pruned_tx", len(pruned)).
-		Uint64("old_block_height", current.Index).
-		Uint64("new_block_height", block.Index).
-		Hex("old_block_id", current.ID[:]).
-		Hex("new_block_id", block.ID[:]).
-		Msg("Finalized block.")
+	return results, pruned, nil
 }
 
 func (l *Ledger) query() {
 	snowballK := conf.GetSnowballK()
 
-	peers, err := SelectPeers(l.client.ClosestPeers(), snowballK)
+	peers, err := l.sampler.Sample(l.Snapshot(), l.reputation, l.client.ClosestPeers(), snowballK)
 	if err != nil {
 		return
 	}
@@ -846,6 +981,7 @@ func (l *Ledger) query() {
 
 	for _, p := range peers {
 		conn := p.Conn()
+		id := p.ID().PublicKey()
 		cached, _ := l.queryPeerBlockCache.Load(p.ID().Checksum())
 
 		f := func() {
@@ -879,9 +1015,12 @@ func (l *Ledger) query() {
 						Err(err).
 						Msg("error while querying peer")
 
+					l.reputation.Record(id, ReputationUnresponsive)
+
 					return
 				}
 
+				l.reputation.Record(id, ReputationResponded)
 				l.metrics.queried.Mark(1)
 
 				info := noise.InfoFromPeer(p)
@@ -948,6 +1087,9 @@ func (l *Ledger) query() {
 // to understand what counts of accepted, rejected, or otherwise ignored transactions truly represent
 // after calling collapseTransactions.
 type collapseResults struct {
+	// height is the height of the block the transactions were collapsed in.
+	height uint64
+
 	applied        []*Transaction
 	rejected       []*Transaction
 	rejectedErrors []error
@@ -964,7 +1106,7 @@ type collapseResults struct {
 // snapshot with all finalized transactions applied, alongside count summaries of the number of
 // applied, rejected, or otherwise ignored transactions.
 func (l *Ledger) collapseTransactions(
-	height uint64, current *Block, proposed []TransactionID, logging bool,
+	height uint64, current *Block, proposed []TransactionID,
 ) (*collapseResults, error) {
 	transactions, err := l.transactions.BatchFind(proposed)
 	if err != nil {
@@ -976,10 +1118,6 @@ func (l *Ledger) collapseTransactions(
 		return nil, errors.Wrap(err, "failed to collapse transactions")
 	}
 
-	if logging && results != nil {
-		l.collapseResultsLogger.Log(results)
-	}
-
 	return results, err
 }
 
@@ -1065,12 +1203,14 @@ ValidateVotes:
 		for i := range transactions {
 			// Validate the height recorded on transactions inside the block proposal.
 			if vote.block.Index >= transactions[i].Block+uint64(conf.GetPruningLimit()) {
+				l.reputation.Record(vote.voter.PublicKey(), ReputationInvalidBlock)
 				vote.block = nil
 				continue ValidateVotes
 			}
 
 			if i > 0 { // Filter away block proposals with transaction IDs that are not properly sorted.
 				if bytes.Compare(transactions[i-1].ComputeIndex(current.ID), transactions[i].ComputeIndex(current.ID)) >= 0 {
+					l.reputation.Record(vote.voter.PublicKey(), ReputationInvalidBlock)
 					vote.block = nil
 					continue ValidateVotes
 				}
@@ -1079,7 +1219,7 @@ ValidateVotes:
 
 		// Derive the Merkle root of the block by cloning the current ledger state, and applying
 		// all transactions in the block into the ledger state.
-		results, err := l.collapseTransactions(vote.block.Index, current, vote.block.Transactions, false)
+		results, err := l.collapseTransactions(vote.block.Index, current, vote.block.Transactions)
 		if err != nil {
 			dbg("failed to collapse for block",
 				hex.EncodeToString(vote.block.ID[:]),
@@ -1116,6 +1256,7 @@ ValidateVotes:
 				hex.EncodeToString(c[:]),
 			)
 
+			l.reputation.Record(vote.voter.PublicKey(), ReputationInvalidBlock)
 			vote.block = nil
 			continue ValidateVotes
 		}
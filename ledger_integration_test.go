@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -194,7 +196,7 @@ type account struct {
 }
 
 func TestLedger_Sync(t *testing.T) {
-	testnet, err := NewTestNetwork()
+	testnet, err := NewTestNetwork(WithoutReplay())
 	FailTest(t, err)
 
 	defer testnet.Cleanup()
@@ -296,4 +298,270 @@ DONE:
 		checkCode, _ := ReadAccountContractCode(charlie.ledger.accounts.Snapshot(), acc.PublicKey)
 		assert.True(t, bytes.Equal(code[:], checkCode))
 	}
+
+	// The chunks of state downloaded while syncing are cleared once syncing is done.
+	assert.NoError(t, waitFor(func() bool {
+		_, _, err := LoadSyncCheckpoint(charlie.ledger.db)
+		return errors.Cause(err) == store.ErrNotFound
+	}))
+}
+
+func TestLedger_SyncIncremental(t *testing.T) {
+	sim := NewSimNetwork(3)
+
+	testnet, err := NewTestNetwork(WithSimNetwork(sim), WithoutReplay())
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode()
+	FailTest(t, err)
+
+	bob, err := testnet.AddNode()
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	_, err = testnet.Faucet().Pay(alice, 1000000)
+	FailTest(t, err)
+
+	FailTest(t, alice.WaitUntilBalance(1000000))
+	FailTest(t, waitFor(func() bool { return bob.BalanceOfAccount(alice) == 1000000 }))
+
+	// Remember the state bob is at before he falls behind.
+	root := bob.Ledger().accounts.Snapshot().Checksum()
+	view := &SyncView{ViewId: bob.BlockIndex(), MerkleRoot: root[:]}
+
+	sim.Isolate(bob)
+
+	for i := uint64(1); i <= conf.GetSyncIfBlockIndicesDifferBy()+1; i++ {
+		_, err = testnet.Faucet().Pay(alice, 1)
+		FailTest(t, err)
+
+		FailTest(t, alice.WaitUntilBalance(1000000+i))
+	}
+
+	sim.Heal()
+
+	FailTest(t, waitFor(func() bool { return bob.BlockIndex() >= alice.BlockIndex() }))
+	assert.EqualValues(t, alice.Balance(), bob.BalanceOfAccount(alice))
+
+	// Alice still has the state bob was at, and so only has to send him the changes made since.
+	protocol := &Protocol{ledger: alice.Ledger()}
+	request := func(view *SyncView) *SyncRequest {
+		return &SyncRequest{Data: &SyncRequest_View{View: view}}
+	}
+
+	assert.EqualValues(t, view.ViewId, protocol.syncDiffBase(request(view)))
+
+	// A peer at a state alice does not have, or at a state alice knows nothing about, is sent
+	// the full state instead.
+	forked := &SyncView{ViewId: view.ViewId, MerkleRoot: make([]byte, len(root))}
+	unknown := &SyncView{ViewId: alice.BlockIndex() + 100, MerkleRoot: root[:]}
+
+	assert.EqualValues(t, 0, protocol.syncDiffBase(request(forked)))
+	assert.EqualValues(t, 0, protocol.syncDiffBase(request(unknown)))
+}
+
+func TestLedger_SyncReplay(t *testing.T) {
+	sim := NewSimNetwork(4)
+
+	testnet, err := NewTestNetwork(WithSimNetwork(sim))
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode()
+	FailTest(t, err)
+
+	bob, err := testnet.AddNode()
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	start := bob.BlockIndex()
+
+	sim.Isolate(bob)
+
+	var txs []Transaction
+
+	for i := uint64(1); i <= conf.GetSyncIfBlockIndicesDifferBy()+1; i++ {
+		tx, err := testnet.Faucet().Pay(alice, 1)
+		FailTest(t, err)
+
+		FailTest(t, alice.WaitUntilBalance(i))
+
+		txs = append(txs, tx)
+	}
+
+	sim.Heal()
+
+	FailTest(t, waitFor(func() bool { return bob.BlockIndex() >= alice.BlockIndex() }))
+	assert.EqualValues(t, alice.Balance(), bob.BalanceOfAccount(alice))
+
+	// Bob replayed every block he missed, rather than only syncing to the latest state.
+	for i := start + 1; i <= alice.BlockIndex(); i++ {
+		expected, err := alice.Ledger().Blocks().GetByIndex(i)
+		FailTest(t, err)
+
+		block, err := bob.Ledger().Blocks().GetByIndex(i)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected.ID, block.ID)
+		}
+	}
+
+	for _, tx := range txs {
+		assert.True(t, bob.Ledger().Transactions().Has(tx.ID))
+	}
+}
+
+func TestLedger_SyncReplayLimit(t *testing.T) {
+	// Nodes further behind than the limit sync state instead of replaying the blocks they missed.
+	defer conf.Update(conf.WithSyncReplayLimit(conf.GetSyncReplayLimit()))
+	conf.Update(conf.WithSyncReplayLimit(2))
+
+	sim := NewSimNetwork(5)
+
+	testnet, err := NewTestNetwork(WithSimNetwork(sim))
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode()
+	FailTest(t, err)
+
+	bob, err := testnet.AddNode()
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	start := bob.BlockIndex()
+
+	sim.Isolate(bob)
+
+	for i := uint64(1); i <= conf.GetSyncIfBlockIndicesDifferBy()+1; i++ {
+		_, err := testnet.Faucet().Pay(alice, 1)
+		FailTest(t, err)
+
+		FailTest(t, alice.WaitUntilBalance(i))
+	}
+
+	sim.Heal()
+
+	FailTest(t, waitFor(func() bool { return bob.BlockIndex() >= alice.BlockIndex() }))
+	assert.EqualValues(t, alice.Balance(), bob.BalanceOfAccount(alice))
+
+	_, err = bob.Ledger().Blocks().GetByIndex(start + 1)
+	assert.Error(t, err)
+}
+
+func TestLedger_SyncReplayArchive(t *testing.T) {
+	// Archive nodes replay the blocks they missed no matter how far behind they are.
+	defer conf.Update(conf.WithSyncReplayLimit(conf.GetSyncReplayLimit()))
+	conf.Update(conf.WithSyncReplayLimit(2))
+
+	sim := NewSimNetwork(5)
+
+	testnet, err := NewTestNetwork(WithSimNetwork(sim))
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode()
+	FailTest(t, err)
+
+	bob, err := testnet.AddNode(WithArchival())
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	start := bob.BlockIndex()
+
+	sim.Isolate(bob)
+
+	var txs []Transaction
+
+	for i := uint64(1); i <= conf.GetSyncIfBlockIndicesDifferBy()+1; i++ {
+		tx, err := testnet.Faucet().Pay(alice, 1)
+		FailTest(t, err)
+
+		FailTest(t, alice.WaitUntilBalance(i))
+
+		txs = append(txs, tx)
+	}
+
+	sim.Heal()
+
+	FailTest(t, waitFor(func() bool { return bob.BlockIndex() >= alice.BlockIndex() }))
+	assert.EqualValues(t, alice.Balance(), bob.BalanceOfAccount(alice))
+
+	for i := start + 1; i <= alice.BlockIndex(); i++ {
+		_, err := bob.Ledger().Blocks().GetByIndex(i)
+		assert.NoError(t, err)
+	}
+
+	for _, tx := range txs {
+		assert.NotNil(t, bob.Ledger().FindTransaction(tx.ID))
+	}
+
+	gaps, err := LoadArchiveGaps(bob.KV())
+	if assert.NoError(t, err) {
+		assert.Empty(t, gaps)
+	}
+}
+
+func TestLedger_Archive(t *testing.T) {
+	defer conf.Update(conf.WithPruningLimit(conf.GetPruningLimit()))
+	conf.Update(conf.WithPruningLimit(5))
+
+	sim := NewSimNetwork(6)
+
+	testnet, err := NewTestNetwork(WithSimNetwork(sim))
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode(WithArchival())
+	FailTest(t, err)
+
+	bob, err := testnet.AddNode()
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	tx, err := testnet.Faucet().Pay(alice, 1)
+	FailTest(t, err)
+
+	FailTest(t, alice.WaitUntilBalance(1))
+
+	height := alice.BlockIndex()
+
+	for i := uint64(2); i <= uint64(conf.GetPruningLimit())+2; i++ {
+		_, err := testnet.Faucet().Pay(alice, 1)
+		FailTest(t, err)
+
+		FailTest(t, alice.WaitUntilBalance(i))
+	}
+
+	// Bob has pruned the block and the transaction.
+	_, err = bob.Ledger().Blocks().GetByIndex(height)
+	assert.Error(t, err)
+	assert.Nil(t, bob.Ledger().FindTransaction(tx.ID))
+
+	// Alice still has both, as well as the state at the height of the block.
+	block, err := alice.Ledger().Blocks().GetByIndex(height)
+	if assert.NoError(t, err) {
+		assert.Equal(t, height, block.Index)
+		assert.Contains(t, block.Transactions, tx.ID)
+	}
+
+	if found := alice.Ledger().FindTransaction(tx.ID); assert.NotNil(t, found) {
+		assert.Equal(t, tx.ID, found.ID)
+	}
+
+	snapshot, err := alice.Ledger().SnapshotAt(height)
+	if assert.NoError(t, err) {
+		balance, _ := ReadAccountBalance(snapshot, alice.PublicKey())
+		assert.EqualValues(t, 1, balance)
+	}
 }
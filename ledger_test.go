@@ -3,10 +3,18 @@
 package wavelet
 
 import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/perlin-network/noise/skademlia"
 	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/internal/cuckoo"
+	"github.com/perlin-network/wavelet/log"
+	"github.com/perlin-network/wavelet/store"
 	"github.com/perlin-network/wavelet/sys"
 	"github.com/stretchr/testify/assert"
-	"testing"
+	"github.com/valyala/fastjson"
 )
 
 func TestLedger_FilterInvalidVotes(t *testing.T) {
@@ -64,3 +72,102 @@ func TestLedger_FilterInvalidVotes(t *testing.T) {
 
 	assert.Nil(t, votes[len(votes)-1].(*finalizationVote).block)
 }
+
+func TestLedger_ApplyBlockLogsAfterCommit(t *testing.T) {
+	writerKey := "tx_apply_block_test"
+
+	log.ClearWriter(writerKey)
+	defer log.ClearWriter(writerKey)
+
+	sender, err := skademlia.NewKeys(1, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	recipient, err := skademlia.NewKeys(1, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	kv := store.NewInmem()
+
+	accounts := NewAccounts(kv)
+	WriteAccountBalance(accounts.tree, sender.PublicKey(), 5)
+
+	genesis := NewBlock(0, accounts.tree.Checksum())
+
+	// A fresh store has no latest block to load, which is of no concern here.
+	blocks, _ := NewBlocks(kv, 10)
+
+	_, err = blocks.Save(&genesis)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	payload, err := Transfer{Recipient: recipient.PublicKey(), Amount: 1}.Marshal()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tx := NewTransaction(sender, 1, 0, sys.TagTransfer, payload)
+
+	transactions := NewTransactions(genesis)
+	transactions.BatchUnsafeAdd([]*Transaction{&tx})
+
+	l := &Ledger{
+		metrics:               NewMetrics(context.TODO()),
+		accounts:              accounts,
+		blocks:                blocks,
+		transactions:          transactions,
+		db:                    kv,
+		transactionFilter:     cuckoo.NewFilter(),
+		collapseResultsLogger: NewCollapseResultsLogger(),
+	}
+
+	defer l.collapseResultsLogger.Stop()
+
+	events := make(chan string, 16)
+
+	log.SetWriter(writerKey, writerFunc(func(p []byte) (n int, err error) {
+		if v, err := fastjson.ParseBytes(p); err == nil && string(v.GetStringBytes("mod")) == "tx" {
+			events <- string(v.GetStringBytes("event"))
+		}
+
+		return len(p), nil
+	}))
+
+	// A block whose merkle root does not match the state it yields fails to be finalized, and so
+	// its transactions are not announced as applied.
+	_, _, err = l.applyBlock(&genesis, NewBlock(1, MerkleNodeID{1}, tx.ID))
+	assert.Error(t, err)
+
+	select {
+	case event := <-events:
+		t.Fatalf("got tx event %q for a block which failed to be finalized", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	assert.Equal(t, genesis.ID, l.blocks.Latest().ID)
+
+	// Once the block is finalized, its transactions are.
+	results, err := collapseTransactions(1, []*Transaction{&tx}, &genesis, accounts)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	block := NewBlock(1, results.snapshot.Checksum(), tx.ID)
+
+	_, _, err = l.applyBlock(&genesis, block)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "applied", event)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for tx event")
+	}
+
+	assert.Equal(t, block.ID, l.blocks.Latest().ID)
+}
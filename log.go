@@ -21,6 +21,7 @@ package wavelet
 
 import (
 	"encoding/hex"
+	"strconv"
 	"sync"
 	"time"
 
@@ -111,7 +112,7 @@ func (c *CollapseResultsLogger) Log(results *collapseResults) {
 		_ = hex.Encode(bufTxID, tx.ID[:])
 		_ = hex.Encode(bufAccount, tx.Sender[:])
 
-		c.addTx(modTx, eventApplied, timestamp, int(tx.Tag), bufTxID, bufAccount, nil)
+		c.addTx(modTx, eventApplied, timestamp, results.height, int(tx.Tag), bufTxID, bufAccount, nil)
 	}
 
 	eventRejected := []byte("rejected")
@@ -120,14 +121,17 @@ func (c *CollapseResultsLogger) Log(results *collapseResults) {
 		_ = hex.Encode(bufTxID, tx.ID[:])
 		_ = hex.Encode(bufAccount, tx.Sender[:])
 
-		c.addTx(modTx, eventRejected, timestamp, int(tx.Tag), bufTxID, bufAccount, results.rejectedErrors[i])
+		c.addTx(
+			modTx, eventRejected, timestamp, results.height, int(tx.Tag), bufTxID, bufAccount,
+			results.rejectedErrors[i],
+		)
 	}
 
 	c.flush()
 }
 
 func (c *CollapseResultsLogger) addTx(mod, event []byte,
-	timestamp time.Time, tag int,
+	timestamp time.Time, height uint64, tag int,
 	txID []byte, sender []byte, logError error) {
 
 	o := c.arena.NewObject()
@@ -136,6 +140,7 @@ func (c *CollapseResultsLogger) addTx(mod, event []byte,
 	o.Set("event", c.arena.NewStringBytes(event))
 	o.Set("time", c.arena.NewStringBytes(timestamp.AppendFormat(c.bufTime, c.timeLayout)))
 
+	o.Set("height", c.arena.NewNumberString(strconv.FormatUint(height, 10)))
 	o.Set("tag", c.arena.NewNumberInt(tag))
 	o.Set("tx_id", c.arena.NewStringBytes(txID))
 	o.Set("sender_id", c.arena.NewStringBytes(sender))
@@ -144,7 +149,7 @@ func (c *CollapseResultsLogger) addTx(mod, event []byte,
 		o.Set("error", c.arena.NewString(logError.Error()))
 	}
 
-	// The length of the JSON is 227, not including the error field.
+	// The length of the JSON is about 240, not including the error field.
 	buf := make([]byte, 0, 256)
 
 	c.bufBatch = append(c.bufBatch, logBuffer{module: mod, message: o.MarshalTo(buf)})
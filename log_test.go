@@ -71,7 +71,7 @@ func TestCollapseResultsLogger(t *testing.T) {
 	txRejected := NewTransaction(sender, 2, 0, sys.TagTransfer, payload)
 	txs = append(txs, &txRejected)
 
-	block := NewBlock(0, MerkleNodeID{})
+	block := NewBlock(7, MerkleNodeID{})
 
 	results, err := collapseTransactions(block.Index, txs, &block, accounts)
 	if !assert.NoError(t, err) {
@@ -108,6 +108,7 @@ func TestCollapseResultsLogger(t *testing.T) {
 	}
 	assert.Equal(t, "tx", string(v.GetStringBytes("mod")))
 	assert.Equal(t, "applied", string(v.GetStringBytes("event")))
+	assert.Equal(t, uint64(7), v.GetUint64("height"))
 	assert.Equal(t, hex.EncodeToString(txApplied.ID[:]), string(v.GetStringBytes("tx_id")))
 	assert.Equal(t, hex.EncodeToString(txApplied.Sender[:]), string(v.GetStringBytes("sender_id")))
 	assert.Nil(t, v.GetStringBytes("error"))
@@ -120,6 +121,7 @@ func TestCollapseResultsLogger(t *testing.T) {
 	}
 	assert.Equal(t, "tx", string(v.GetStringBytes("mod")))
 	assert.Equal(t, "rejected", string(v.GetStringBytes("event")))
+	assert.Equal(t, uint64(7), v.GetUint64("height"))
 	assert.Equal(t, hex.EncodeToString(txRejected.ID[:]), string(v.GetStringBytes("tx_id")))
 	assert.Equal(t, hex.EncodeToString(txRejected.Sender[:]), string(v.GetStringBytes("sender_id")))
 	assert.Contains(t, string(v.GetStringBytes("error")), "could not apply transfer transaction")
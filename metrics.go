@@ -40,6 +40,10 @@ type Metrics struct {
 	finalizedBlocks metrics.Meter
 
 	queryLatency metrics.Timer
+
+	wsQueued       metrics.Counter
+	wsDropped      metrics.Meter
+	wsDisconnected metrics.Meter
 }
 
 func NewMetrics(ctx context.Context) *Metrics {
@@ -56,6 +60,10 @@ func NewMetrics(ctx context.Context) *Metrics {
 
 	queryLatency := metrics.NewRegisteredTimer("query.latency", registry)
 
+	wsQueued := metrics.NewRegisteredCounter("ws.queued", registry)
+	wsDropped := metrics.NewRegisteredMeter("ws.dropped", registry)
+	wsDisconnected := metrics.NewRegisteredMeter("ws.disconnected", registry)
+
 	go func() {
 		logger := log.Metrics()
 
@@ -77,6 +85,9 @@ func NewMetrics(ctx context.Context) *Metrics {
 					Int64("query.latency.max.ms", queryLatency.Max()/(1.0e+7)).
 					Int64("query.latency.min.ms", queryLatency.Min()/(1.0e+7)).
 					Float64("query.latency.mean.ms", queryLatency.Mean()/(1.0e+7)).
+					Int64("ws.queued", wsQueued.Count()).
+					Int64("ws.dropped", wsDropped.Count()).
+					Int64("ws.disconnected", wsDisconnected.Count()).
 					Msg("Updated metrics.")
 			case <-ctx.Done():
 				return
@@ -97,6 +108,10 @@ func NewMetrics(ctx context.Context) *Metrics {
 		finalizedBlocks: finalizedBlocks,
 
 		queryLatency: queryLatency,
+
+		wsQueued:       wsQueued,
+		wsDropped:      wsDropped,
+		wsDisconnected: wsDisconnected,
 	}
 }
 
@@ -111,4 +126,41 @@ func (m *Metrics) Stop() {
 	m.finalizedBlocks.Stop()
 
 	m.queryLatency.Stop()
+
+	m.wsDropped.Stop()
+	m.wsDisconnected.Stop()
+}
+
+// UpdateWebsocketQueued changes the number of events queued to websocket clients by delta.
+func (m *Metrics) UpdateWebsocketQueued(delta int64) {
+	if delta >= 0 {
+		m.wsQueued.Inc(delta)
+	} else {
+		m.wsQueued.Dec(-delta)
+	}
+}
+
+// MarkWebsocketDropped marks events which were dropped from the queue of a slow websocket client.
+func (m *Metrics) MarkWebsocketDropped(n int64) {
+	m.wsDropped.Mark(n)
+}
+
+// MarkWebsocketDisconnected marks a slow websocket client which was disconnected.
+func (m *Metrics) MarkWebsocketDisconnected() {
+	m.wsDisconnected.Mark(1)
+}
+
+// WebsocketQueued returns the number of events queued to websocket clients.
+func (m *Metrics) WebsocketQueued() int64 {
+	return m.wsQueued.Count()
+}
+
+// WebsocketDropped returns the number of events dropped from the queues of slow websocket clients.
+func (m *Metrics) WebsocketDropped() int64 {
+	return m.wsDropped.Count()
+}
+
+// WebsocketDisconnected returns the number of slow websocket clients which were disconnected.
+func (m *Metrics) WebsocketDisconnected() int64 {
+	return m.wsDisconnected.Count()
 }
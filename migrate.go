@@ -0,0 +1,166 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/pkg/errors"
+)
+
+// migrateBatchSize is the max number of bytes of keys and values copied per write batch.
+const migrateBatchSize = 16 * 1024 * 1024
+
+// MigrateProgress reports the number of keys, and the number of bytes of keys and values, copied
+// over so far by Migrate.
+type MigrateProgress func(keys uint64, bytes uint64)
+
+// Migration describes the contents of a database which was copied over by Migrate.
+type Migration struct {
+	Keys  uint64
+	Bytes uint64
+
+	// Checksum is the merkle root of the state of all accounts.
+	Checksum MerkleNodeID
+
+	// Latest is the latest finalized block, or nil should no blocks have been stored.
+	Latest *Block
+}
+
+// Migrate copies every key from src into dst, which must be empty, in batches of write operations.
+// It is meant to move a database between storage engines while its node is not running. Once all
+// keys are copied, it verifies that every node of the state of all accounts was copied intact into
+// dst such that it hashes to the same merkle root as src, and that dst holds the same latest
+// finalized block as src.
+func Migrate(dst, src store.KV, progress MigrateProgress) (*Migration, error) {
+	it := dst.NewIterator(nil, nil)
+	empty := !it.Next()
+	err := it.Error()
+	it.Release()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check whether the destination database is empty")
+	}
+
+	if !empty {
+		return nil, errors.New("destination database is not empty")
+	}
+
+	snapshot, err := src.NewSnapshot()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to take snapshot of the source database")
+	}
+
+	defer snapshot.Release()
+
+	var (
+		migration Migration
+
+		// Number of keys, and number of bytes of keys and values, in the pending write batch.
+		pending, size int
+	)
+
+	batch := dst.NewWriteBatch()
+
+	commit := func() error {
+		if pending == 0 {
+			return nil
+		}
+
+		if err := dst.CommitWriteBatch(batch); err != nil {
+			return errors.Wrap(err, "failed to write to the destination database")
+		}
+
+		batch = dst.NewWriteBatch()
+		pending, size = 0, 0
+
+		if progress != nil {
+			progress(migration.Keys, migration.Bytes)
+		}
+
+		return nil
+	}
+
+	it = snapshot.NewIterator(nil, nil)
+
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			it.Release()
+			return nil, errors.Wrap(err, "failed to write to the destination database")
+		}
+
+		migration.Keys++
+		migration.Bytes += uint64(len(it.Key()) + len(it.Value()))
+
+		pending++
+
+		if size += len(it.Key()) + len(it.Value()); size >= migrateBatchSize {
+			if err := commit(); err != nil {
+				it.Release()
+				return nil, err
+			}
+		}
+	}
+
+	err = it.Error()
+	it.Release()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read from the source database")
+	}
+
+	if err := commit(); err != nil {
+		return nil, err
+	}
+
+	migration.Checksum = avl.New(src).Checksum()
+
+	checksum, err := avl.Verify(dst)
+	if err != nil {
+		return nil, errors.Wrap(err, "state of all accounts is corrupt after migrating")
+	}
+
+	if checksum != migration.Checksum {
+		return nil, errors.Errorf("expected merkle root %x after migrating, but got %x", migration.Checksum, checksum)
+	}
+
+	expected, err := NewBlocks(src, conf.GetPruningLimit())
+	if err != nil {
+		if errors.Cause(err) == store.ErrNotFound {
+			return &migration, nil
+		}
+
+		return nil, errors.Wrap(err, "failed to load blocks from the source database")
+	}
+
+	actual, err := NewBlocks(dst, conf.GetPruningLimit())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load blocks after migrating")
+	}
+
+	migration.Latest = expected.Latest()
+
+	if latest := actual.Latest(); latest.ID != migration.Latest.ID {
+		return nil, errors.Errorf("expected latest block %x after migrating, but got %x", migration.Latest.ID, latest.ID)
+	}
+
+	return &migration, nil
+}
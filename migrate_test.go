@@ -0,0 +1,218 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package wavelet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	src, cleanup, err := store.NewTestKV("level", "migrate_level")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer cleanup()
+
+	tree := avl.New(src)
+
+	for i := 0; i < 100; i++ {
+		var id AccountID
+		_, err := rand.Read(id[:])
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		WriteAccountBalance(tree, id, uint64(i))
+	}
+
+	WriteAccountsLen(tree, 100)
+
+	if !assert.NoError(t, tree.Commit()) {
+		return
+	}
+
+	blocks, _ := NewBlocks(src, 10)
+
+	for i := 0; i < 15; i++ {
+		block := NewBlock(uint64(i+1), tree.Checksum())
+
+		_, err := blocks.Save(&block)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	for _, kind := range []string{"badger", "bbolt"} {
+		kind := kind
+
+		t.Run(kind, func(t *testing.T) {
+			dst, cleanup, err := store.NewTestKV(kind, "migrate_"+kind)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer cleanup()
+
+			var keys uint64
+
+			migration, err := Migrate(dst, src, func(n uint64, _ uint64) {
+				keys = n
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, keys, migration.Keys)
+			assert.Equal(t, tree.Checksum(), migration.Checksum)
+			assert.Equal(t, blocks.Latest().ID, migration.Latest.ID)
+
+			accounts := NewAccounts(dst)
+			assert.Equal(t, uint64(100), ReadAccountsLen(accounts.Snapshot()))
+
+			// Migrating into a database which is not empty fails.
+			_, err = Migrate(dst, src, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMigrate_CorruptNode(t *testing.T) {
+	src, cleanup, err := store.NewTestKV("level", "migrate_corrupt_src")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer cleanup()
+
+	tree := avl.New(src)
+
+	for i := 0; i < 100; i++ {
+		WriteAccountBalance(tree, AccountID{byte(i)}, uint64(i))
+	}
+
+	if !assert.NoError(t, tree.Commit()) {
+		return
+	}
+
+	root := tree.Checksum()
+	rootKey := append(avl.NodeKeyPrefix, root[:]...)
+
+	// Pick any node of the tree besides its root.
+	var nodeKey []byte
+
+	it := src.NewIterator(avl.NodeKeyPrefix, nil)
+	for it.Next() && bytes.HasPrefix(it.Key(), avl.NodeKeyPrefix) {
+		if !bytes.Equal(it.Key(), rootKey) {
+			nodeKey = append([]byte{}, it.Key()...)
+			break
+		}
+	}
+	it.Release()
+
+	if !assert.NotNil(t, nodeKey) {
+		return
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(key, value []byte) []byte
+	}{
+		{
+			name: "missing root",
+			tamper: func(key, value []byte) []byte {
+				if bytes.Equal(key, rootKey) {
+					return nil
+				}
+
+				return value
+			},
+		},
+		{
+			name: "missing node",
+			tamper: func(key, value []byte) []byte {
+				if bytes.Equal(key, nodeKey) {
+					return nil
+				}
+
+				return value
+			},
+		},
+		{
+			name: "corrupt node",
+			tamper: func(key, value []byte) []byte {
+				if bytes.Equal(key, nodeKey) {
+					value = append([]byte{}, value...)
+					value[len(value)-1]++
+				}
+
+				return value
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dst, cleanup, err := store.NewTestKV("level", "migrate_corrupt_dst")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer cleanup()
+
+			_, err = Migrate(tamperedKV{KV: dst, tamper: tc.tamper}, src, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// tamperedKV tampers with the values put into it through write batches. A nil value returned by
+// tamper drops the key instead.
+type tamperedKV struct {
+	store.KV
+	tamper func(key, value []byte) []byte
+}
+
+func (kv tamperedKV) NewWriteBatch() store.WriteBatch {
+	return tamperedWriteBatch{WriteBatch: kv.KV.NewWriteBatch(), tamper: kv.tamper}
+}
+
+func (kv tamperedKV) CommitWriteBatch(batch store.WriteBatch) error {
+	return kv.KV.CommitWriteBatch(batch.(tamperedWriteBatch).WriteBatch)
+}
+
+type tamperedWriteBatch struct {
+	store.WriteBatch
+	tamper func(key, value []byte) []byte
+}
+
+func (b tamperedWriteBatch) Put(key, value []byte) error {
+	if value = b.tamper(key, value); value == nil {
+		return nil
+	}
+
+	return b.WriteBatch.Put(key, value)
+}
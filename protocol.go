@@ -53,6 +53,15 @@ func (p *Protocol) Gossip(ctx context.Context, req *GossipRequest) (*empty.Empty
 			continue
 		}
 
+		if !tx.VerifySignature() {
+			logger := log.TX("gossip")
+			logger.Error().
+				Hex("tx_id", tx.ID[:]).
+				Msg("bad signature")
+
+			continue
+		}
+
 		txs = append(txs, tx)
 	}
 
@@ -85,6 +94,8 @@ func (p *Protocol) Query(ctx context.Context, req *QueryRequest) (*QueryResponse
 		if err != nil {
 			return nil, err
 		}
+
+		res.Finalized = true
 	}
 
 	if block == nil {
@@ -117,7 +128,9 @@ func (p *Protocol) Sync(stream Wavelet_SyncServer) error {
 
 	defer p.ledger.filePool.Put(diffBuffer)
 
-	if err := p.ledger.accounts.Snapshot().DumpDiff(req.GetBlockId(), diffBuffer); err != nil {
+	prevViewID := p.syncDiffBase(req)
+
+	if err := p.ledger.accounts.Snapshot().DumpDiff(prevViewID, diffBuffer); err != nil {
 		return err
 	}
 
@@ -215,6 +228,41 @@ func (p *Protocol) CheckOutOfSync(ctx context.Context, req *OutOfSyncRequest) (*
 	}, nil
 }
 
+// syncDiffBase returns the view ID from which the state diff sent to a syncing peer should start.
+// Should the peer report being at a state we have finalized before, only the changes made to the
+// state since then are sent. Otherwise, the peer is sent the full state from genesis.
+//
+// Peers that predate views in sync requests only send the ID of the block they are at, which is
+// honoured as is.
+func (p *Protocol) syncDiffBase(req *SyncRequest) uint64 {
+	view := req.GetView()
+	if view == nil {
+		return req.GetBlockId()
+	}
+
+	if view.ViewId == 0 {
+		return 0
+	}
+
+	logger := log.Sync("sync")
+
+	block, err := p.ledger.blocks.GetByIndex(view.ViewId)
+	if err != nil || !bytes.Equal(block.Merkle[:], view.MerkleRoot) {
+		logger.Debug().
+			Uint64("view_id", view.ViewId).
+			Hex("merkle_root", view.MerkleRoot).
+			Msg("Peer is at a state we do not have. Sending them the full state.")
+
+		return 0
+	}
+
+	logger.Debug().
+		Uint64("view_id", view.ViewId).
+		Msg("Peer is at a state we have finalized before. Sending them only the changes made since.")
+
+	return view.ViewId
+}
+
 func (p *Protocol) SyncTransactions(stream Wavelet_SyncTransactionsServer) error {
 	req, err := stream.Recv()
 	if err != nil {
@@ -285,7 +333,7 @@ func (p *Protocol) PullTransactions(
 	for _, id := range req.TransactionIds {
 		copy(txID[:], id)
 
-		if tx := p.ledger.transactions.Find(txID); tx != nil {
+		if tx := p.ledger.FindTransaction(txID); tx != nil {
 			res.Transactions = append(res.Transactions, tx.Marshal())
 		}
 	}
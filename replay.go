@@ -0,0 +1,252 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/perlin-network/noise/skademlia"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/log"
+	"github.com/pkg/errors"
+)
+
+// replayBlocks catches our node up with its peers by downloading all blocks that were finalized
+// while our node was behind, alongside their transactions, and applying them one after another
+// as though our node had finalized them itself. Unlike syncing state, our node is left with the
+// full history of transactions it has missed.
+//
+// It returns false should our node be further behind than conf.GetSyncReplayLimit() blocks, or
+// should any of the blocks or transactions we missed no longer be available from our peers. The
+// latest state then has to be synced instead. Archive nodes always attempt to replay blocks no
+// matter how far behind they are, as syncing state leaves holes in their archive.
+func (l *Ledger) replayBlocks() bool {
+	limit := conf.GetSyncReplayLimit()
+	if limit == 0 && !l.archive {
+		return false
+	}
+
+	logger := log.Sync("replay")
+
+	peers, err := l.sampler.Sample(l.Snapshot(), l.reputation, l.client.ClosestPeers(), conf.GetSnowballK())
+	if err != nil {
+		return false
+	}
+
+	start := l.blocks.Latest()
+
+	// Should our peers have finalized a block past the limit, we are too far behind.
+	if far := !l.archive && l.queryFinalizedBlock(peers, start.Index+limit+1) != nil; far {
+		logger.Info().
+			Uint64("current_block_height", start.Index).
+			Uint64("replay_limit", limit).
+			Msg("We are too far behind our peers to replay the blocks we missed. Syncing state instead.")
+
+		return false
+	}
+
+	for {
+		block := l.queryFinalizedBlock(peers, l.blocks.Latest().Index+1)
+		if block == nil {
+			break
+		}
+
+		if err := l.replayBlock(peers, *block); err != nil {
+			logger.Warn().
+				Err(err).
+				Uint64("block_height", block.Index).
+				Hex("block_id", block.ID[:]).
+				Msg("Failed to replay a block we missed. Syncing state instead.")
+
+			return false
+		}
+	}
+
+	latest := l.blocks.Latest()
+	if latest.Index == start.Index {
+		return false
+	}
+
+	logger.Info().
+		Uint64("num_blocks", latest.Index-start.Index).
+		Uint64("old_block_height", start.Index).
+		Uint64("new_block_height", latest.Index).
+		Hex("new_block_id", latest.ID[:]).
+		Msg("Caught up with our peers by replaying the blocks we missed.")
+
+	return true
+}
+
+// replayBlock pulls all transactions of a finalized block that we do not have from our peers, and
+// applies the block on top of our latest block.
+func (l *Ledger) replayBlock(peers []skademlia.ClosestPeer, block Block) error {
+	current := l.blocks.Latest()
+
+	if block.Index != current.Index+1 {
+		return errors.Errorf("expected block at height %d, but got block at height %d", current.Index+1, block.Index)
+	}
+
+	missing := make(map[TransactionID]struct{})
+
+	for _, id := range block.Transactions {
+		if !l.transactions.Has(id) {
+			missing[id] = struct{}{}
+		}
+	}
+
+	if len(missing) > 0 {
+		txs := l.pullTransactions(peers, missing)
+
+		if len(txs) < len(missing) {
+			return errors.Errorf(
+				"%d out of %d transaction(s) of the block could not be pulled from our peers",
+				len(missing)-len(txs),
+				len(missing),
+			)
+		}
+
+		l.transactions.BatchAdd(txs)
+
+		l.transactionFilterLock.Lock()
+		for _, tx := range txs {
+			l.transactionFilter.Insert(tx.ID)
+		}
+		l.transactionFilterLock.Unlock()
+	}
+
+	if _, _, err := l.applyBlock(current, block); err != nil {
+		return err
+	}
+
+	l.finalizer.Reset()
+
+	for id := range l.queryBlockValidCache {
+		delete(l.queryBlockValidCache, id)
+	}
+
+	return nil
+}
+
+// queryFinalizedBlock asks our peers for the block they have finalized at the given height, and
+// returns the block which at least two thirds of our peers agree upon. It returns nil otherwise.
+func (l *Ledger) queryFinalizedBlock(peers []skademlia.ClosestPeer, height uint64) *Block {
+	blocks := make(chan *Block, len(peers))
+
+	for _, p := range peers {
+		go func(peer skademlia.ClosestPeer) {
+			var block *Block
+
+			defer func() {
+				blocks <- block
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), conf.GetQueryTimeout())
+			defer cancel()
+
+			res, err := NewWaveletClient(peer.Conn()).Query(ctx, &QueryRequest{BlockIndex: height})
+			if err != nil || !res.GetFinalized() || len(res.GetBlock()) == 0 {
+				return
+			}
+
+			b, err := UnmarshalBlock(bytes.NewReader(res.GetBlock()))
+			if err != nil || b.ID == ZeroBlockID || b.Index != height {
+				return
+			}
+
+			block = &b
+		}(p)
+	}
+
+	var agreed *Block
+
+	counts := make(map[BlockID]int)
+
+	for range peers {
+		block := <-blocks
+		if block == nil {
+			continue
+		}
+
+		counts[block.ID]++
+
+		if agreed == nil || counts[block.ID] > counts[agreed.ID] {
+			agreed = block
+		}
+	}
+
+	if agreed == nil || counts[agreed.ID] < (2*len(peers)+2)/3 {
+		return nil
+	}
+
+	return agreed
+}
+
+// pullTransactions pulls the transactions with the given IDs from our peers, and returns all
+// transactions that were pulled whose signatures are valid.
+func (l *Ledger) pullTransactions(peers []skademlia.ClosestPeer, ids map[TransactionID]struct{}) []Transaction {
+	req := &TransactionPullRequest{TransactionIds: make([][]byte, 0, len(ids))}
+
+	for id := range ids {
+		id := id
+		req.TransactionIds = append(req.TransactionIds, id[:])
+	}
+
+	pulled := make(map[TransactionID]Transaction, len(ids))
+
+	for _, p := range peers {
+		if len(pulled) == len(ids) {
+			break
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), conf.GetDownloadTxTimeout())
+		batch, err := NewWaveletClient(p.Conn()).PullTransactions(ctx, req)
+		cancel()
+
+		if err != nil {
+			l.reputation.Record(p.ID().PublicKey(), ReputationUnresponsive)
+			continue
+		}
+
+		for _, buf := range batch.Transactions {
+			tx, err := UnmarshalTransaction(bytes.NewReader(buf))
+			if err != nil {
+				continue
+			}
+
+			if _, wanted := ids[tx.ID]; !wanted {
+				continue
+			}
+
+			if !tx.VerifySignature() {
+				continue
+			}
+
+			pulled[tx.ID] = tx
+		}
+	}
+
+	txs := make([]Transaction, 0, len(pulled))
+	for _, tx := range pulled {
+		txs = append(txs, tx)
+	}
+
+	return txs
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/perlin-network/noise/skademlia"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/log"
+)
+
+const (
+	MaxReputation = 100.0
+	MinReputation = -100.0
+
+	// ReputationBanThreshold is the reputation at or below which a peer is temporarily banned.
+	ReputationBanThreshold = -50.0
+
+	// ReputationDecayPerMinute is how much the reputation of a peer moves back towards neutral
+	// every full minute, such that peers are not held to what they did long ago. Events within
+	// the same minute add up exactly.
+	ReputationDecayPerMinute = 1.0
+
+	// reputationPruneInterval is how often peers whose reputation decayed back to neutral are
+	// forgotten.
+	reputationPruneInterval = time.Minute
+)
+
+// ReputationEvent is something a peer did that affects its reputation.
+type ReputationEvent uint8
+
+const (
+	// ReputationResponded is recorded when a peer answers a request.
+	ReputationResponded ReputationEvent = iota
+
+	// ReputationUnresponsive is recorded when a request to a peer fails or times out.
+	ReputationUnresponsive
+
+	// ReputationInvalidBlock is recorded when a peer votes for a block that fails validation.
+	ReputationInvalidBlock
+
+	// ReputationBadChunk is recorded when a peer serves a chunk of state whose checksum does
+	// not match the checksum it advertised.
+	ReputationBadChunk
+)
+
+var reputationDeltas = map[ReputationEvent]float64{
+	ReputationResponded:    1,
+	ReputationUnresponsive: -5,
+	ReputationInvalidBlock: -25,
+	ReputationBadChunk:     -25,
+}
+
+func (e ReputationEvent) String() string {
+	switch e {
+	case ReputationResponded:
+		return "responded"
+	case ReputationUnresponsive:
+		return "unresponsive"
+	case ReputationInvalidBlock:
+		return "invalid_block"
+	case ReputationBadChunk:
+		return "bad_chunk"
+	default:
+		return "unknown"
+	}
+}
+
+// PeerScore is the reputation of a single peer.
+type PeerScore struct {
+	PublicKey   AccountID
+	Score       float64
+	Failures    uint64
+	BannedUntil time.Time
+
+	// updatedAt is when Score was last updated, from which it decays towards neutral.
+	updatedAt time.Time
+}
+
+func (s PeerScore) Banned(now time.Time) bool {
+	return now.Before(s.BannedUntil)
+}
+
+// PeerReputation scores peers based on how they respond to requests, such that peers which
+// misbehave or are unresponsive are sampled less often, and are temporarily banned should
+// their reputation drop to ReputationBanThreshold. All methods may be called on a nil
+// PeerReputation, in which case all peers are treated equally.
+type PeerReputation struct {
+	sync.RWMutex
+
+	// peers only holds peers whose reputation is not neutral, or which are banned.
+	peers map[AccountID]*PeerScore
+	now   func() time.Time
+
+	lastPruned time.Time
+}
+
+func NewPeerReputation() *PeerReputation {
+	return &PeerReputation{
+		peers: make(map[AccountID]*PeerScore),
+		now:   time.Now,
+	}
+}
+
+// Record updates the reputation of a peer given something it did.
+func (r *PeerReputation) Record(id AccountID, event ReputationEvent) {
+	if r == nil {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	now := r.now()
+	score := r.get(id, now)
+
+	defer func() {
+		r.store(score, now)
+	}()
+
+	delta := reputationDeltas[event]
+	if delta < 0 {
+		score.Failures++
+	}
+
+	// Peers may not redeem themselves while banned.
+	if score.Banned(now) && delta > 0 {
+		return
+	}
+
+	score.Score += delta
+
+	if score.Score > MaxReputation {
+		score.Score = MaxReputation
+	}
+
+	if score.Score < MinReputation {
+		score.Score = MinReputation
+	}
+
+	if score.Score <= ReputationBanThreshold && !score.Banned(now) {
+		score.BannedUntil = now.Add(conf.GetPeerBanDuration())
+
+		logger := log.Node()
+		logger.Warn().
+			Hex("public_key", id[:]).
+			Float64("score", score.Score).
+			Str("event", event.String()).
+			Time("banned_until", score.BannedUntil).
+			Msg("Banned peer for having too low of a reputation.")
+	}
+}
+
+// get returns the score of a peer as of now without storing it. Scores decay towards neutral
+// over time, and peers whose ban has expired are given a fresh start halfway above the ban
+// threshold. It must be called with the lock held.
+func (r *PeerReputation) get(id AccountID, now time.Time) PeerScore {
+	stored, exists := r.peers[id]
+	if !exists {
+		return PeerScore{PublicKey: id, updatedAt: now}
+	}
+
+	score := *stored
+
+	if !score.BannedUntil.IsZero() && !score.Banned(now) {
+		score.updatedAt = score.BannedUntil
+		score.BannedUntil = time.Time{}
+		score.Score = ReputationBanThreshold / 2
+	}
+
+	// Banned peers do not decay, so that they may not redeem themselves while banned.
+	if minutes := now.Sub(score.updatedAt) / time.Minute; !score.Banned(now) && minutes > 0 {
+		decay := ReputationDecayPerMinute * float64(minutes)
+
+		switch {
+		case score.Score > decay:
+			score.Score -= decay
+		case score.Score < -decay:
+			score.Score += decay
+		default:
+			score.Score = 0
+		}
+
+		score.updatedAt = score.updatedAt.Add(minutes * time.Minute)
+	}
+
+	return score
+}
+
+// store saves the score of a peer, forgetting the peer should its reputation be neutral and it
+// not be banned. Peers whose reputation has since decayed back to neutral are forgotten every
+// reputationPruneInterval. It must be called with the lock held.
+func (r *PeerReputation) store(score PeerScore, now time.Time) {
+	if score.Score == 0 && !score.Banned(now) {
+		delete(r.peers, score.PublicKey)
+	} else {
+		r.peers[score.PublicKey] = &score
+	}
+
+	if now.Sub(r.lastPruned) < reputationPruneInterval {
+		return
+	}
+
+	r.lastPruned = now
+
+	for id := range r.peers {
+		if current := r.get(id, now); current.Score == 0 && !current.Banned(now) {
+			delete(r.peers, id)
+		}
+	}
+}
+
+// Score returns the reputation of a peer.
+func (r *PeerReputation) Score(id AccountID) PeerScore {
+	if r == nil {
+		return PeerScore{PublicKey: id}
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.get(id, r.now())
+}
+
+// Banned returns true if a peer is currently banned.
+func (r *PeerReputation) Banned(id AccountID) bool {
+	if r == nil {
+		return false
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	now := r.now()
+
+	return r.get(id, now).Banned(now)
+}
+
+// Scores returns the reputation of all peers that have been scored, ordered by public key.
+func (r *PeerReputation) Scores() []PeerScore {
+	if r == nil {
+		return nil
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	now := r.now()
+	scores := make([]PeerScore, 0, len(r.peers))
+
+	for id := range r.peers {
+		scores = append(scores, r.get(id, now))
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		return bytes.Compare(scores[i].PublicKey[:], scores[j].PublicKey[:]) < 0
+	})
+
+	return scores
+}
+
+// Filter removes all peers that are currently banned. Should all peers be banned, no peers
+// are removed, such that a node which was cut off from the network for a while (and hence
+// found all of its peers to be unresponsive) may still reach them once it reconnects.
+func (r *PeerReputation) Filter(peers []skademlia.ClosestPeer) []skademlia.ClosestPeer {
+	if r == nil {
+		return peers
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	now := r.now()
+	filtered := make([]skademlia.ClosestPeer, 0, len(peers))
+
+	for _, p := range peers {
+		if !r.get(p.ID().PublicKey(), now).Banned(now) {
+			filtered = append(filtered, p)
+		}
+	}
+
+	if len(filtered) == 0 {
+		return peers
+	}
+
+	return filtered
+}
+
+// Weight returns how likely a peer is to be sampled relative to other peers, in (0, 1].
+// Peers that have not yet been scored are weighed in the middle.
+func (r *PeerReputation) Weight(id AccountID) float64 {
+	score := r.Score(id).Score
+	return (score - MinReputation + 1) / (MaxReputation - MinReputation + 1)
+}
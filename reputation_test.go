@@ -0,0 +1,146 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package wavelet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerReputation(t *testing.T) {
+	now := time.Now()
+
+	r := NewPeerReputation()
+	r.now = func() time.Time { return now }
+
+	alice, bob := AccountID{1}, AccountID{2}
+
+	assert.Equal(t, 0.0, r.Score(alice).Score)
+	assert.False(t, r.Banned(alice))
+
+	for i := 0; i < int(MaxReputation)*2; i++ {
+		r.Record(alice, ReputationResponded)
+	}
+
+	assert.Equal(t, MaxReputation, r.Score(alice).Score)
+	assert.True(t, r.Weight(alice) > r.Weight(bob))
+
+	r.Record(bob, ReputationInvalidBlock)
+	assert.False(t, r.Banned(bob))
+	assert.True(t, r.Weight(bob) > 0)
+
+	r.Record(bob, ReputationBadChunk)
+	assert.True(t, r.Banned(bob))
+	assert.EqualValues(t, 2, r.Score(bob).Failures)
+
+	// Banned peers may not redeem themselves.
+	r.Record(bob, ReputationResponded)
+	assert.Equal(t, ReputationBanThreshold, r.Score(bob).Score)
+
+	scores := r.Scores()
+	if assert.Len(t, scores, 2) {
+		assert.Equal(t, alice, scores[0].PublicKey)
+		assert.Equal(t, bob, scores[1].PublicKey)
+	}
+
+	// Once the ban expires, the peer is given a fresh start.
+	now = now.Add(conf.GetPeerBanDuration())
+
+	assert.False(t, r.Banned(bob))
+	assert.Equal(t, ReputationBanThreshold/2, r.Score(bob).Score)
+}
+
+func TestPeerReputation_Decay(t *testing.T) {
+	now := time.Now()
+
+	r := NewPeerReputation()
+	r.now = func() time.Time { return now }
+
+	alice, bob := AccountID{1}, AccountID{2}
+
+	// Looking peers up does not have them be stored.
+	r.Filter(nil)
+	r.Score(alice)
+	r.Weight(alice)
+	assert.False(t, r.Banned(alice))
+	assert.Empty(t, r.peers)
+
+	for i := 0; i < 10; i++ {
+		r.Record(alice, ReputationResponded)
+	}
+
+	r.Record(bob, ReputationUnresponsive)
+
+	// Reputations only decay every full minute.
+	now = now.Add(30 * time.Second)
+
+	assert.Equal(t, 10.0, r.Score(alice).Score)
+
+	now = now.Add(3 * time.Minute)
+
+	assert.Equal(t, 10-3*ReputationDecayPerMinute, r.Score(alice).Score)
+	assert.Equal(t, -5+3*ReputationDecayPerMinute, r.Score(bob).Score)
+
+	// Peers whose reputation decayed back to neutral are forgotten.
+	now = now.Add(10 * time.Minute)
+
+	assert.Equal(t, 0.0, r.Score(bob).Score)
+
+	r.Record(bob, ReputationResponded)
+
+	scores := r.Scores()
+	if assert.Len(t, scores, 1) {
+		assert.Equal(t, bob, scores[0].PublicKey)
+		assert.Equal(t, 1.0, scores[0].Score)
+	}
+
+	// Banned peers do not decay, and are only forgotten once their ban expires and they decay
+	// back to neutral.
+	r.Record(alice, ReputationInvalidBlock)
+	r.Record(alice, ReputationInvalidBlock)
+	r.Record(alice, ReputationInvalidBlock)
+
+	assert.True(t, r.Banned(alice))
+
+	now = now.Add(conf.GetPeerBanDuration())
+	assert.Equal(t, ReputationBanThreshold/2, r.Score(alice).Score)
+
+	now = now.Add(time.Duration(-ReputationBanThreshold/2/ReputationDecayPerMinute) * time.Minute)
+	r.Record(AccountID{3}, ReputationResponded)
+
+	_, exists := r.peers[alice]
+	assert.False(t, exists)
+}
+
+func TestPeerReputation_Nil(t *testing.T) {
+	var r *PeerReputation
+
+	r.Record(AccountID{1}, ReputationInvalidBlock)
+
+	assert.False(t, r.Banned(AccountID{1}))
+	assert.Nil(t, r.Scores())
+	assert.Nil(t, r.Filter(nil))
+	assert.Equal(t, r.Weight(AccountID{1}), NewPeerReputation().Weight(AccountID{1}))
+}
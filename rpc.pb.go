@@ -13,6 +13,7 @@
 		OutOfSyncRequest
 		OutOfSyncResponse
 		SyncInfo
+		SyncView
 		SyncRequest
 		SyncResponse
 		GossipRequest
@@ -74,6 +75,7 @@ func (m *QueryRequest) GetCacheBlockId() []byte {
 type QueryResponse struct {
 	Block      []byte `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
 	CacheValid bool   `protobuf:"varint,2,opt,name=cache_valid,json=cacheValid,proto3" json:"cache_valid,omitempty"`
+	Finalized  bool   `protobuf:"varint,3,opt,name=finalized,proto3" json:"finalized,omitempty"`
 }
 
 func (m *QueryResponse) Reset()                    { *m = QueryResponse{} }
@@ -95,6 +97,13 @@ func (m *QueryResponse) GetCacheValid() bool {
 	return false
 }
 
+func (m *QueryResponse) GetFinalized() bool {
+	if m != nil {
+		return m.Finalized
+	}
+	return false
+}
+
 type OutOfSyncRequest struct {
 	BlockIndex uint64 `protobuf:"varint,1,opt,name=block_index,json=blockIndex,proto3" json:"block_index,omitempty"`
 }
@@ -151,17 +160,42 @@ func (m *SyncInfo) GetChecksums() [][]byte {
 	return nil
 }
 
+type SyncView struct {
+	ViewId     uint64 `protobuf:"varint,1,opt,name=view_id,json=viewId,proto3" json:"view_id,omitempty"`
+	MerkleRoot []byte `protobuf:"bytes,2,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+}
+
+func (m *SyncView) Reset()                    { *m = SyncView{} }
+func (m *SyncView) String() string            { return proto.CompactTextString(m) }
+func (*SyncView) ProtoMessage()               {}
+func (*SyncView) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{5} }
+
+func (m *SyncView) GetViewId() uint64 {
+	if m != nil {
+		return m.ViewId
+	}
+	return 0
+}
+
+func (m *SyncView) GetMerkleRoot() []byte {
+	if m != nil {
+		return m.MerkleRoot
+	}
+	return nil
+}
+
 type SyncRequest struct {
 	// Types that are valid to be assigned to Data:
 	//	*SyncRequest_BlockId
 	//	*SyncRequest_Checksum
+	//	*SyncRequest_View
 	Data isSyncRequest_Data `protobuf_oneof:"Data"`
 }
 
 func (m *SyncRequest) Reset()                    { *m = SyncRequest{} }
 func (m *SyncRequest) String() string            { return proto.CompactTextString(m) }
 func (*SyncRequest) ProtoMessage()               {}
-func (*SyncRequest) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{5} }
+func (*SyncRequest) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{6} }
 
 type isSyncRequest_Data interface {
 	isSyncRequest_Data()
@@ -175,9 +209,13 @@ type SyncRequest_BlockId struct {
 type SyncRequest_Checksum struct {
 	Checksum []byte `protobuf:"bytes,2,opt,name=checksum,proto3,oneof"`
 }
+type SyncRequest_View struct {
+	View *SyncView `protobuf:"bytes,3,opt,name=view,oneof"`
+}
 
 func (*SyncRequest_BlockId) isSyncRequest_Data()  {}
 func (*SyncRequest_Checksum) isSyncRequest_Data() {}
+func (*SyncRequest_View) isSyncRequest_Data()     {}
 
 func (m *SyncRequest) GetData() isSyncRequest_Data {
 	if m != nil {
@@ -200,11 +238,19 @@ func (m *SyncRequest) GetChecksum() []byte {
 	return nil
 }
 
+func (m *SyncRequest) GetView() *SyncView {
+	if x, ok := m.GetData().(*SyncRequest_View); ok {
+		return x.View
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*SyncRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _SyncRequest_OneofMarshaler, _SyncRequest_OneofUnmarshaler, _SyncRequest_OneofSizer, []interface{}{
 		(*SyncRequest_BlockId)(nil),
 		(*SyncRequest_Checksum)(nil),
+		(*SyncRequest_View)(nil),
 	}
 }
 
@@ -218,6 +264,11 @@ func _SyncRequest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 	case *SyncRequest_Checksum:
 		_ = b.EncodeVarint(2<<3 | proto.WireBytes)
 		_ = b.EncodeRawBytes(x.Checksum)
+	case *SyncRequest_View:
+		_ = b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.View); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("SyncRequest.Data has unexpected type %T", x)
@@ -242,6 +293,14 @@ func _SyncRequest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Bu
 		x, err := b.DecodeRawBytes(true)
 		m.Data = &SyncRequest_Checksum{x}
 		return true, err
+	case 3: // Data.view
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SyncView)
+		err := b.DecodeMessage(msg)
+		m.Data = &SyncRequest_View{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -258,6 +317,11 @@ func _SyncRequest_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(2<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(len(x.Checksum)))
 		n += len(x.Checksum)
+	case *SyncRequest_View:
+		s := proto.Size(x.View)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -275,7 +339,7 @@ type SyncResponse struct {
 func (m *SyncResponse) Reset()                    { *m = SyncResponse{} }
 func (m *SyncResponse) String() string            { return proto.CompactTextString(m) }
 func (*SyncResponse) ProtoMessage()               {}
-func (*SyncResponse) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{6} }
+func (*SyncResponse) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{7} }
 
 type isSyncResponse_Data interface {
 	isSyncResponse_Data()
@@ -391,7 +455,7 @@ type GossipRequest struct {
 func (m *GossipRequest) Reset()                    { *m = GossipRequest{} }
 func (m *GossipRequest) String() string            { return proto.CompactTextString(m) }
 func (*GossipRequest) ProtoMessage()               {}
-func (*GossipRequest) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{7} }
+func (*GossipRequest) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{8} }
 
 func (m *GossipRequest) GetTransactions() [][]byte {
 	if m != nil {
@@ -410,7 +474,7 @@ type TransactionsSyncRequest struct {
 func (m *TransactionsSyncRequest) Reset()                    { *m = TransactionsSyncRequest{} }
 func (m *TransactionsSyncRequest) String() string            { return proto.CompactTextString(m) }
 func (*TransactionsSyncRequest) ProtoMessage()               {}
-func (*TransactionsSyncRequest) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{8} }
+func (*TransactionsSyncRequest) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{9} }
 
 type isTransactionsSyncRequest_Data interface {
 	isTransactionsSyncRequest_Data()
@@ -521,7 +585,7 @@ type TransactionsSyncPart struct {
 func (m *TransactionsSyncPart) Reset()                    { *m = TransactionsSyncPart{} }
 func (m *TransactionsSyncPart) String() string            { return proto.CompactTextString(m) }
 func (*TransactionsSyncPart) ProtoMessage()               {}
-func (*TransactionsSyncPart) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{9} }
+func (*TransactionsSyncPart) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{10} }
 
 func (m *TransactionsSyncPart) GetTransactions() [][]byte {
 	if m != nil {
@@ -540,7 +604,7 @@ type TransactionsSyncResponse struct {
 func (m *TransactionsSyncResponse) Reset()                    { *m = TransactionsSyncResponse{} }
 func (m *TransactionsSyncResponse) String() string            { return proto.CompactTextString(m) }
 func (*TransactionsSyncResponse) ProtoMessage()               {}
-func (*TransactionsSyncResponse) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{10} }
+func (*TransactionsSyncResponse) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{11} }
 
 type isTransactionsSyncResponse_Data interface {
 	isTransactionsSyncResponse_Data()
@@ -655,7 +719,7 @@ type TransactionPullRequest struct {
 func (m *TransactionPullRequest) Reset()                    { *m = TransactionPullRequest{} }
 func (m *TransactionPullRequest) String() string            { return proto.CompactTextString(m) }
 func (*TransactionPullRequest) ProtoMessage()               {}
-func (*TransactionPullRequest) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{11} }
+func (*TransactionPullRequest) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{12} }
 
 func (m *TransactionPullRequest) GetTransactionIds() [][]byte {
 	if m != nil {
@@ -671,7 +735,7 @@ type TransactionPullResponse struct {
 func (m *TransactionPullResponse) Reset()                    { *m = TransactionPullResponse{} }
 func (m *TransactionPullResponse) String() string            { return proto.CompactTextString(m) }
 func (*TransactionPullResponse) ProtoMessage()               {}
-func (*TransactionPullResponse) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{12} }
+func (*TransactionPullResponse) Descriptor() ([]byte, []int) { return fileDescriptorRpc, []int{13} }
 
 func (m *TransactionPullResponse) GetTransactions() [][]byte {
 	if m != nil {
@@ -686,6 +750,7 @@ func init() {
 	proto.RegisterType((*OutOfSyncRequest)(nil), "wavelet.OutOfSyncRequest")
 	proto.RegisterType((*OutOfSyncResponse)(nil), "wavelet.OutOfSyncResponse")
 	proto.RegisterType((*SyncInfo)(nil), "wavelet.SyncInfo")
+	proto.RegisterType((*SyncView)(nil), "wavelet.SyncView")
 	proto.RegisterType((*SyncRequest)(nil), "wavelet.SyncRequest")
 	proto.RegisterType((*SyncResponse)(nil), "wavelet.SyncResponse")
 	proto.RegisterType((*GossipRequest)(nil), "wavelet.GossipRequest")
@@ -1058,6 +1123,16 @@ func (m *QueryResponse) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if m.Finalized {
+		dAtA[i] = 0x18
+		i++
+		if m.Finalized {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -1144,6 +1219,35 @@ func (m *SyncInfo) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *SyncView) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SyncView) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ViewId != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintRpc(dAtA, i, uint64(m.ViewId))
+	}
+	if len(m.MerkleRoot) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintRpc(dAtA, i, uint64(len(m.MerkleRoot)))
+		i += copy(dAtA[i:], m.MerkleRoot)
+	}
+	return i, nil
+}
+
 func (m *SyncRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1186,6 +1290,20 @@ func (m *SyncRequest_Checksum) MarshalTo(dAtA []byte) (int, error) {
 	}
 	return i, nil
 }
+func (m *SyncRequest_View) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.View != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintRpc(dAtA, i, uint64(m.View.Size()))
+		n2, err := m.View.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	return i, nil
+}
 func (m *SyncResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1202,11 +1320,11 @@ func (m *SyncResponse) MarshalTo(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if m.Data != nil {
-		nn2, err := m.Data.MarshalTo(dAtA[i:])
+		nn3, err := m.Data.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += nn2
+		i += nn3
 	}
 	return i, nil
 }
@@ -1217,11 +1335,11 @@ func (m *SyncResponse_Header) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0xa
 		i++
 		i = encodeVarintRpc(dAtA, i, uint64(m.Header.Size()))
-		n3, err := m.Header.MarshalTo(dAtA[i:])
+		n4, err := m.Header.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n3
+		i += n4
 	}
 	return i, nil
 }
@@ -1277,11 +1395,11 @@ func (m *TransactionsSyncRequest) MarshalTo(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if m.Data != nil {
-		nn4, err := m.Data.MarshalTo(dAtA[i:])
+		nn5, err := m.Data.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += nn4
+		i += nn5
 	}
 	return i, nil
 }
@@ -1345,11 +1463,11 @@ func (m *TransactionsSyncResponse) MarshalTo(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if m.Data != nil {
-		nn5, err := m.Data.MarshalTo(dAtA[i:])
+		nn6, err := m.Data.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += nn5
+		i += nn6
 	}
 	return i, nil
 }
@@ -1367,11 +1485,11 @@ func (m *TransactionsSyncResponse_Transactions) MarshalTo(dAtA []byte) (int, err
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintRpc(dAtA, i, uint64(m.Transactions.Size()))
-		n6, err := m.Transactions.MarshalTo(dAtA[i:])
+		n7, err := m.Transactions.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n6
+		i += n7
 	}
 	return i, nil
 }
@@ -1477,6 +1595,9 @@ func (m *QueryResponse) Size() (n int) {
 	if m.CacheValid {
 		n += 2
 	}
+	if m.Finalized {
+		n += 2
+	}
 	return n
 }
 
@@ -1514,6 +1635,19 @@ func (m *SyncInfo) Size() (n int) {
 	return n
 }
 
+func (m *SyncView) Size() (n int) {
+	var l int
+	_ = l
+	if m.ViewId != 0 {
+		n += 1 + sovRpc(uint64(m.ViewId))
+	}
+	l = len(m.MerkleRoot)
+	if l > 0 {
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	return n
+}
+
 func (m *SyncRequest) Size() (n int) {
 	var l int
 	_ = l
@@ -1538,6 +1672,15 @@ func (m *SyncRequest_Checksum) Size() (n int) {
 	}
 	return n
 }
+func (m *SyncRequest_View) Size() (n int) {
+	var l int
+	_ = l
+	if m.View != nil {
+		l = m.View.Size()
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	return n
+}
 func (m *SyncResponse) Size() (n int) {
 	var l int
 	_ = l
@@ -1854,6 +1997,26 @@ func (m *QueryResponse) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.CacheValid = bool(v != 0)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Finalized", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Finalized = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRpc(dAtA[iNdEx:])
@@ -2124,6 +2287,106 @@ func (m *SyncInfo) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *SyncView) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SyncView: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SyncView: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ViewId", wireType)
+			}
+			m.ViewId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ViewId |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MerkleRoot", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MerkleRoot = append(m.MerkleRoot[:0], dAtA[iNdEx:postIndex]...)
+			if m.MerkleRoot == nil {
+				m.MerkleRoot = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *SyncRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -2203,6 +2466,38 @@ func (m *SyncRequest) Unmarshal(dAtA []byte) error {
 			copy(v, dAtA[iNdEx:postIndex])
 			m.Data = &SyncRequest_Checksum{v}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field View", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &SyncView{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Data = &SyncRequest_View{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRpc(dAtA[iNdEx:])
@@ -2962,46 +3257,50 @@ var (
 func init() { proto.RegisterFile("src/rpc.proto", fileDescriptorRpc) }
 
 var fileDescriptorRpc = []byte{
-	// 649 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcb, 0x6e, 0xd3, 0x40,
-	0x14, 0xb5, 0xfb, 0x48, 0xd3, 0x1b, 0xb7, 0xa4, 0xa3, 0x36, 0x18, 0xb7, 0xa4, 0x61, 0x84, 0x44,
-	0x24, 0x24, 0x17, 0x35, 0x1b, 0xa8, 0x04, 0x12, 0x2d, 0x8f, 0x64, 0x43, 0x8b, 0x0b, 0x74, 0x01,
-	0xc8, 0x72, 0xed, 0x49, 0x63, 0xd5, 0xf1, 0x04, 0x8f, 0x5d, 0x48, 0xbf, 0x82, 0x05, 0xbf, 0xc3,
-	0x9e, 0x25, 0x9f, 0x80, 0xca, 0x8f, 0x20, 0x8f, 0xed, 0x61, 0x1c, 0x1a, 0x94, 0x55, 0x34, 0x27,
-	0xe7, 0x9e, 0x7b, 0xee, 0xb9, 0xe3, 0x81, 0x15, 0x16, 0xb9, 0x3b, 0xd1, 0xc8, 0x35, 0x47, 0x11,
-	0x8d, 0x29, 0x5a, 0xfa, 0xec, 0x5c, 0x90, 0x80, 0xc4, 0xc6, 0xe6, 0x19, 0xa5, 0x67, 0x01, 0xd9,
-	0xe1, 0xf0, 0x69, 0xd2, 0xdf, 0x21, 0xc3, 0x51, 0x3c, 0xce, 0x58, 0xf8, 0x2d, 0x68, 0xaf, 0x13,
-	0x12, 0x8d, 0x2d, 0xf2, 0x29, 0x21, 0x2c, 0x46, 0xdb, 0x50, 0x3b, 0x0d, 0xa8, 0x7b, 0x6e, 0xfb,
-	0xa1, 0x47, 0xbe, 0xe8, 0x6a, 0x4b, 0x6d, 0x2f, 0x58, 0xc0, 0xa1, 0x5e, 0x8a, 0xa0, 0xbb, 0xb0,
-	0xea, 0x3a, 0xee, 0x80, 0xd8, 0x39, 0xcd, 0xd3, 0xe7, 0x5a, 0x6a, 0x5b, 0xb3, 0x34, 0x8e, 0xee,
-	0x73, 0xa2, 0x87, 0x5f, 0xc0, 0x4a, 0x2e, 0xcb, 0x46, 0x34, 0x64, 0x04, 0xad, 0xc3, 0x22, 0x2f,
-	0xe0, 0x8a, 0x9a, 0x95, 0x1d, 0xd2, 0x6e, 0x99, 0xd8, 0x85, 0x13, 0xe4, 0x4a, 0x55, 0x0b, 0x38,
-	0xf4, 0x2e, 0x45, 0x70, 0x07, 0xea, 0x87, 0x49, 0x7c, 0xd8, 0x3f, 0x1e, 0x87, 0xee, 0xac, 0x16,
-	0x71, 0x07, 0xd6, 0xa4, 0xa2, 0xdc, 0x40, 0x13, 0x6a, 0x34, 0x89, 0x6d, 0xda, 0xb7, 0xd9, 0x38,
-	0x74, 0x79, 0x55, 0xd5, 0x5a, 0xa6, 0x05, 0x0f, 0x3f, 0x81, 0x6a, 0xfa, 0xdb, 0x0b, 0xfb, 0x74,
-	0x8a, 0xd9, 0x2d, 0x58, 0x76, 0x07, 0xc4, 0x3d, 0x67, 0xc9, 0x90, 0xe9, 0x73, 0xad, 0xf9, 0xb6,
-	0x66, 0xfd, 0x05, 0xf0, 0x11, 0xd4, 0x64, 0x93, 0x9b, 0x50, 0x15, 0x01, 0x71, 0x87, 0x5d, 0xc5,
-	0x5a, 0xca, 0x3c, 0x7a, 0x68, 0x0b, 0xaa, 0x45, 0x61, 0x96, 0x5e, 0x57, 0xb1, 0x04, 0xb2, 0x5f,
-	0x81, 0x85, 0x67, 0x4e, 0xec, 0xe0, 0xf7, 0xa0, 0x95, 0x26, 0xb8, 0x0f, 0x95, 0x01, 0x71, 0x3c,
-	0x12, 0x71, 0xc1, 0xda, 0xee, 0x9a, 0x99, 0x6f, 0xd8, 0x2c, 0x8c, 0x77, 0x15, 0x2b, 0xa7, 0xa0,
-	0x06, 0x2c, 0xba, 0x83, 0x24, 0x3c, 0x17, 0xfa, 0xd9, 0x51, 0x88, 0x77, 0x60, 0xe5, 0x25, 0x65,
-	0xcc, 0x1f, 0x15, 0x86, 0x31, 0x68, 0x71, 0xe4, 0x84, 0xcc, 0x71, 0x63, 0x9f, 0x86, 0x4c, 0x57,
-	0xf9, 0x80, 0x25, 0x0c, 0x7f, 0x80, 0x9b, 0x6f, 0xa4, 0xb3, 0x3c, 0xaf, 0x0e, 0x95, 0xbe, 0x1f,
-	0xc4, 0xb9, 0xb9, 0xb4, 0x61, 0x7e, 0x46, 0xdb, 0x00, 0xbc, 0xb5, 0xcd, 0xfc, 0x4b, 0xc2, 0xed,
-	0xa4, 0x59, 0x2c, 0x73, 0xec, 0xd8, 0xbf, 0x24, 0xc2, 0xd2, 0x1e, 0xac, 0x4f, 0xaa, 0x1f, 0x39,
-	0xd1, 0x6c, 0xce, 0xbe, 0xa9, 0xa0, 0xff, 0x6b, 0x4d, 0x04, 0x57, 0x97, 0xc9, 0x76, 0x98, 0x0c,
-	0xc5, 0x4e, 0x6e, 0xc8, 0xff, 0xbc, 0x4a, 0x86, 0xe8, 0x60, 0xa2, 0xdb, 0x1c, 0xcf, 0xfa, 0xb6,
-	0xc8, 0xfa, 0x3a, 0x8b, 0x5d, 0xa5, 0x6c, 0x47, 0x8c, 0xf4, 0x14, 0x1a, 0x12, 0xff, 0x28, 0x09,
-	0x82, 0x22, 0xaf, 0x7b, 0x20, 0x77, 0xb6, 0x7d, 0xaf, 0x98, 0x6b, 0x55, 0x82, 0x7b, 0x1e, 0xc3,
-	0x8f, 0x4b, 0x99, 0x67, 0x12, 0xf9, 0x5c, 0x33, 0x04, 0xb3, 0xfb, 0x7d, 0x1e, 0x96, 0x4e, 0x32,
-	0xeb, 0x68, 0x0f, 0x2a, 0xd9, 0xce, 0x51, 0x43, 0x8c, 0x53, 0xba, 0x04, 0x46, 0xc3, 0xcc, 0xde,
-	0x0a, 0xb3, 0x78, 0x2b, 0xcc, 0xe7, 0xe9, 0x5b, 0x81, 0x15, 0xf4, 0x10, 0x16, 0xf9, 0x07, 0x8d,
-	0x36, 0x44, 0xa9, 0xfc, 0x6e, 0x18, 0x8d, 0x49, 0x38, 0xf3, 0x88, 0x15, 0xd4, 0x83, 0xd5, 0x83,
-	0xf4, 0x6a, 0x8b, 0x4f, 0x12, 0xdd, 0x12, 0xdc, 0xc9, 0x6f, 0xdb, 0x30, 0xae, 0xfb, 0x4b, 0x48,
-	0x3d, 0x82, 0x05, 0x2e, 0xb0, 0x5e, 0xba, 0xf9, 0x45, 0xed, 0xc6, 0x04, 0x5a, 0x94, 0xb5, 0xd5,
-	0x07, 0x2a, 0x3a, 0x81, 0x7a, 0x9a, 0x9d, 0xbc, 0x3d, 0xb4, 0x7d, 0xdd, 0x52, 0xa5, 0x25, 0x19,
-	0xad, 0xe9, 0x04, 0xe1, 0xe9, 0x23, 0xd4, 0xd3, 0x76, 0x25, 0xe1, 0xd6, 0xd4, 0xdb, 0x52, 0x28,
-	0xdf, 0xf9, 0x0f, 0x43, 0xf6, 0xbd, 0x5f, 0xff, 0x71, 0xd5, 0x54, 0x7f, 0x5e, 0x35, 0xd5, 0x5f,
-	0x57, 0x4d, 0xf5, 0xeb, 0xef, 0xa6, 0x72, 0x5a, 0xe1, 0xbb, 0xe9, 0xfc, 0x09, 0x00, 0x00, 0xff,
-	0xff, 0xce, 0x20, 0xb0, 0x69, 0xef, 0x05, 0x00, 0x00,
+	// 715 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xdd, 0x4e, 0xdb, 0x4a,
+	0x10, 0xb6, 0x43, 0xc8, 0xcf, 0xc4, 0x70, 0xc2, 0x0a, 0x82, 0x4f, 0xe0, 0x84, 0x1c, 0xab, 0x12,
+	0x91, 0x2a, 0x85, 0x8a, 0xdc, 0xb4, 0x48, 0xad, 0x54, 0xa0, 0x6a, 0x72, 0x53, 0xa8, 0x69, 0xe1,
+	0xa2, 0xad, 0x2c, 0x63, 0x6f, 0x88, 0x15, 0xc7, 0x9b, 0xfa, 0x07, 0x1a, 0x9e, 0xa2, 0x17, 0x7d,
+	0x9d, 0xde, 0xf7, 0xb2, 0x8f, 0x50, 0xd1, 0x17, 0xa9, 0x76, 0xd7, 0x5e, 0x36, 0x21, 0x54, 0x5c,
+	0x45, 0xf3, 0x79, 0xe6, 0x9b, 0x6f, 0xbe, 0xd9, 0x0c, 0x2c, 0x45, 0xa1, 0xb3, 0x13, 0x8e, 0x9d,
+	0xf6, 0x38, 0x24, 0x31, 0x41, 0xc5, 0x2b, 0xfb, 0x12, 0xfb, 0x38, 0xae, 0x6f, 0x5c, 0x10, 0x72,
+	0xe1, 0xe3, 0x1d, 0x06, 0x9f, 0x27, 0xfd, 0x1d, 0x3c, 0x1a, 0xc7, 0x13, 0x9e, 0x65, 0xbc, 0x07,
+	0xed, 0x6d, 0x82, 0xc3, 0x89, 0x89, 0x3f, 0x27, 0x38, 0x8a, 0xd1, 0x16, 0x54, 0xce, 0x7d, 0xe2,
+	0x0c, 0x2d, 0x2f, 0x70, 0xf1, 0x17, 0x5d, 0x6d, 0xaa, 0xad, 0xbc, 0x09, 0x0c, 0xea, 0x51, 0x04,
+	0x3d, 0x82, 0x65, 0xc7, 0x76, 0x06, 0xd8, 0x4a, 0xd3, 0x5c, 0x3d, 0xd7, 0x54, 0x5b, 0x9a, 0xa9,
+	0x31, 0x74, 0x9f, 0x25, 0xba, 0x86, 0x0b, 0x4b, 0x29, 0x6d, 0x34, 0x26, 0x41, 0x84, 0xd1, 0x2a,
+	0x2c, 0xb2, 0x02, 0xc6, 0xa8, 0x99, 0x3c, 0xa0, 0xdd, 0x38, 0xd9, 0xa5, 0xed, 0xa7, 0x4c, 0x25,
+	0x13, 0x18, 0x74, 0x4a, 0x11, 0xb4, 0x09, 0xe5, 0xbe, 0x17, 0xd8, 0xbe, 0x77, 0x8d, 0x5d, 0x7d,
+	0x81, 0x7d, 0xbe, 0x05, 0x8c, 0x0e, 0x54, 0x8f, 0x92, 0xf8, 0xa8, 0x7f, 0x32, 0x09, 0x9c, 0x87,
+	0x0e, 0x60, 0x74, 0x60, 0x45, 0x2a, 0x4a, 0xe5, 0x35, 0xa0, 0x42, 0x92, 0xd8, 0x22, 0x7d, 0x2b,
+	0x9a, 0x04, 0x0e, 0xab, 0x2a, 0x99, 0x65, 0x92, 0xe5, 0x19, 0x2f, 0xa0, 0x44, 0x7f, 0x7b, 0x41,
+	0x9f, 0xdc, 0x33, 0xca, 0x26, 0x94, 0x9d, 0x01, 0x76, 0x86, 0x51, 0x32, 0x8a, 0xf4, 0x5c, 0x73,
+	0xa1, 0xa5, 0x99, 0xb7, 0x80, 0x71, 0xc8, 0xeb, 0x4f, 0x3d, 0x7c, 0x85, 0xd6, 0xa1, 0x78, 0xe9,
+	0xe1, 0x2b, 0x6a, 0x1d, 0x57, 0x57, 0xa0, 0x61, 0xcf, 0xa5, 0xd2, 0x47, 0x38, 0x1c, 0xfa, 0xd8,
+	0x0a, 0x09, 0x89, 0x53, 0x5f, 0x81, 0x43, 0x26, 0x21, 0xb1, 0x31, 0x81, 0x8a, 0x3c, 0xea, 0x06,
+	0x94, 0xc4, 0x12, 0x18, 0x53, 0x57, 0x31, 0x8b, 0x7c, 0x52, 0xea, 0x5c, 0x29, 0x6b, 0xcf, 0x99,
+	0xba, 0x8a, 0x29, 0x10, 0xb4, 0x0d, 0x79, 0xda, 0x94, 0x59, 0x5a, 0xd9, 0x5d, 0x69, 0xa7, 0x6f,
+	0xa5, 0x9d, 0x89, 0xec, 0x2a, 0x26, 0x4b, 0xd8, 0x2f, 0x40, 0xfe, 0xd0, 0x8e, 0x6d, 0xe3, 0x03,
+	0x68, 0x53, 0x86, 0x3d, 0x86, 0xc2, 0x00, 0xdb, 0x2e, 0x0e, 0x75, 0x75, 0x0e, 0x05, 0xf5, 0xa9,
+	0xab, 0x98, 0x69, 0x0a, 0xaa, 0xc1, 0xa2, 0x33, 0x48, 0x82, 0xa1, 0x10, 0xc2, 0x43, 0x41, 0xde,
+	0x81, 0xa5, 0xd7, 0x24, 0x8a, 0xbc, 0x71, 0x36, 0x99, 0x01, 0x5a, 0x1c, 0xda, 0x41, 0x64, 0x3b,
+	0xb1, 0x47, 0x82, 0x48, 0x57, 0x99, 0x9f, 0x53, 0x98, 0xf1, 0x11, 0xd6, 0xdf, 0x49, 0xb1, 0x6c,
+	0x8c, 0x0e, 0x85, 0xbe, 0xe7, 0xc7, 0xa9, 0x38, 0xda, 0x30, 0x8d, 0xd1, 0x16, 0x00, 0x6b, 0x6d,
+	0x45, 0xde, 0x35, 0xd6, 0x73, 0xa9, 0x69, 0x65, 0x86, 0x9d, 0x78, 0xd7, 0x58, 0x48, 0xda, 0x83,
+	0xd5, 0x59, 0xf6, 0x63, 0x3b, 0x7c, 0x98, 0xb2, 0x6f, 0x2a, 0xe8, 0x77, 0xa5, 0x09, 0xe3, 0xaa,
+	0x72, 0xb2, 0x15, 0x24, 0x23, 0xb1, 0xbc, 0x7f, 0xe4, 0x2f, 0x6f, 0x92, 0x11, 0x3a, 0x98, 0xe9,
+	0x96, 0x63, 0x5e, 0xff, 0x27, 0xbc, 0x9e, 0x27, 0xb1, 0xab, 0x4c, 0xcb, 0x11, 0x23, 0xbd, 0x84,
+	0x9a, 0x94, 0x7f, 0x9c, 0xf8, 0x7e, 0xe6, 0xd7, 0x36, 0xc8, 0x9d, 0x2d, 0xcf, 0xcd, 0xe6, 0x5a,
+	0x96, 0xe0, 0x9e, 0x1b, 0x19, 0xcf, 0x61, 0xfd, 0x0e, 0x45, 0x3a, 0xd7, 0x03, 0x8c, 0xd9, 0xfd,
+	0xbe, 0x00, 0xc5, 0x33, 0x2e, 0x1d, 0xed, 0x41, 0x81, 0xef, 0x1c, 0xd5, 0xc4, 0x38, 0x53, 0x8f,
+	0xa0, 0x5e, 0x6b, 0xf3, 0xc3, 0xd5, 0xce, 0x0e, 0x57, 0xfb, 0x15, 0x3d, 0x5c, 0x86, 0x82, 0x9e,
+	0xc2, 0x22, 0xbb, 0x2e, 0x68, 0x4d, 0x94, 0xca, 0x47, 0xac, 0x5e, 0x9b, 0x85, 0xb9, 0x46, 0x43,
+	0x41, 0x3d, 0x58, 0x3e, 0xa0, 0xff, 0x01, 0x71, 0x01, 0xd0, 0xbf, 0x22, 0x77, 0xf6, 0x94, 0xd4,
+	0xeb, 0xf3, 0x3e, 0x09, 0xaa, 0x67, 0x90, 0x67, 0x04, 0xab, 0x53, 0x2f, 0x3f, 0xab, 0x5d, 0x9b,
+	0x41, 0xb3, 0xb2, 0x96, 0xfa, 0x44, 0x45, 0x67, 0x50, 0xa5, 0xde, 0xc9, 0xdb, 0x43, 0x5b, 0xf3,
+	0x96, 0x2a, 0x2d, 0xa9, 0xde, 0xbc, 0x3f, 0x41, 0x68, 0xfa, 0x04, 0x55, 0xda, 0x6e, 0x8a, 0xb8,
+	0x79, 0xef, 0x6b, 0xc9, 0x98, 0xff, 0xff, 0x4b, 0x86, 0xac, 0x7b, 0xbf, 0xfa, 0xe3, 0xa6, 0xa1,
+	0xfe, 0xbc, 0x69, 0xa8, 0xbf, 0x6e, 0x1a, 0xea, 0xd7, 0xdf, 0x0d, 0xe5, 0xbc, 0xc0, 0x76, 0xd3,
+	0xf9, 0x33, 0x00, 0xb7, 0x37, 0x1d, 0x46, 0x7c, 0x06, 0x00, 0x00,
 }
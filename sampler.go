@@ -0,0 +1,145 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/perlin-network/noise/skademlia"
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/pkg/errors"
+)
+
+// PeerSampler selects which peers out of the ones a node is connected to should be queried
+// while performing consensus. A snapshot of the latest state of all accounts is provided for
+// samplers which weigh peers by their accounts, alongside the reputation of peers which
+// samplers are to exclude banned peers by and bias samples with.
+type PeerSampler interface {
+	Sample(
+		snapshot *avl.Tree, reputation *PeerReputation, peers []skademlia.ClosestPeer, amount int,
+	) ([]skademlia.ClosestPeer, error)
+}
+
+var (
+	_ PeerSampler = (*UniformSampler)(nil)
+	_ PeerSampler = (*StakeWeightedSampler)(nil)
+)
+
+const (
+	SamplerUniform       = "uniform"
+	SamplerStakeWeighted = "stake"
+)
+
+// NewPeerSampler returns the peer sampling strategy registered under the given name.
+func NewPeerSampler(name string) (PeerSampler, error) {
+	switch name {
+	case SamplerUniform:
+		return UniformSampler{}, nil
+	case SamplerStakeWeighted:
+		return StakeWeightedSampler{}, nil
+	default:
+		return nil, errors.Errorf("unknown peer sampler %q", name)
+	}
+}
+
+// UniformSampler samples peers uniformly at random.
+type UniformSampler struct{}
+
+func (UniformSampler) Sample(
+	_ *avl.Tree, reputation *PeerReputation, peers []skademlia.ClosestPeer, amount int,
+) ([]skademlia.ClosestPeer, error) {
+	return SelectPeers(peers, amount, reputation)
+}
+
+// StakeWeightedSampler samples peers with a probability proportional to the amount of stake
+// they have placed. Peers with less than the minimum stake are only ever sampled should there
+// not be enough staked peers to sample from, which prevents Sybil peers that have placed no
+// stake from dominating samples. Should no peer have any stake, or should no snapshot of
+// accounts be provided, peers are sampled uniformly at random.
+type StakeWeightedSampler struct{}
+
+func (StakeWeightedSampler) Sample(
+	snapshot *avl.Tree, reputation *PeerReputation, peers []skademlia.ClosestPeer, amount int,
+) ([]skademlia.ClosestPeer, error) {
+	if snapshot == nil {
+		return SelectPeers(peers, amount, reputation)
+	}
+
+	if len(peers) < amount {
+		return peers, errors.Errorf("only connected to %d peer(s), but require a minimum of %d peer(s)", len(peers), amount)
+	}
+
+	activePeers := reputation.Filter(filterActivePeers(peers))
+
+	if len(activePeers) <= amount {
+		return activePeers, nil
+	}
+
+	type weightedPeer struct {
+		peer skademlia.ClosestPeer
+		key  float64
+	}
+
+	staked := make([]weightedPeer, 0, len(activePeers))
+	unstaked := make([]skademlia.ClosestPeer, 0, len(activePeers))
+
+	for _, p := range activePeers {
+		stake, _ := ReadAccountStake(snapshot, p.ID().PublicKey())
+
+		if stake < sys.MinimumStake {
+			unstaked = append(unstaked, p)
+			continue
+		}
+
+		// Weighted random sampling without replacement (Efraimidis and Spirakis): assign
+		// each peer the key u^(1/w) for u drawn uniformly from (0, 1), and take the peers
+		// with the largest keys. Keys are compared in log-space to retain precision.
+		weight := float64(stake) * reputation.Weight(p.ID().PublicKey())
+		staked = append(staked, weightedPeer{peer: p, key: math.Log(rand.Float64()) / weight})
+	}
+
+	if len(staked) == 0 {
+		return SelectPeers(activePeers, amount, reputation)
+	}
+
+	sort.Slice(staked, func(i, j int) bool {
+		return staked[i].key > staked[j].key
+	})
+
+	selected := make([]skademlia.ClosestPeer, 0, amount)
+
+	for i := 0; i < len(staked) && len(selected) < amount; i++ {
+		selected = append(selected, staked[i].peer)
+	}
+
+	if remaining := amount - len(selected); remaining > 0 {
+		filler, err := SelectPeers(unstaked, remaining, reputation)
+		if err != nil {
+			return nil, err
+		}
+
+		selected = append(selected, filler...)
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package wavelet
+
+import (
+	"testing"
+
+	"github.com/perlin-network/noise/skademlia"
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStakeWeightedSampler_ZeroStakeSybils has nodes place stake while sampling peers by stake,
+// after which Sybil nodes that have placed no stake join and outnumber the staked nodes. Sampling
+// by stake must never select any of the Sybils, whereas sampling uniformly does.
+func TestStakeWeightedSampler_ZeroStakeSybils(t *testing.T) {
+	testnet, err := NewTestNetwork()
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	faucet := testnet.Faucet()
+
+	alice, err := testnet.AddNode(WithSampler(StakeWeightedSampler{}))
+	FailTest(t, err)
+
+	bob, err := testnet.AddNode(WithSampler(StakeWeightedSampler{}))
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	for _, node := range []*TestLedger{alice, bob} {
+		_, err = faucet.Pay(node, 1000000)
+		FailTest(t, err)
+
+		FailTest(t, node.WaitUntilBalance(1000000))
+
+		_, err = node.PlaceStake(9001)
+		FailTest(t, err)
+
+		FailTest(t, node.WaitUntilStake(9001))
+	}
+
+	FailTest(t, waitFor(func() bool {
+		return faucet.StakeOfAccount(alice) == 9001 && faucet.StakeOfAccount(bob) == 9001
+	}))
+
+	sybils := make(map[AccountID]struct{})
+
+	for i := 0; i < 6; i++ {
+		sybil, err := testnet.AddNode()
+		FailTest(t, err)
+
+		sybils[sybil.PublicKey()] = struct{}{}
+	}
+
+	var peers []skademlia.ClosestPeer
+
+	FailTest(t, waitFor(func() bool {
+		peers = filterActivePeers(faucet.Client().ClosestPeers())
+		return len(peers) == 2+len(sybils)
+	}))
+
+	countSybils := func(sampler PeerSampler, snapshot *avl.Tree) int {
+		count := 0
+
+		for i := 0; i < 100; i++ {
+			sampled, err := sampler.Sample(snapshot, nil, peers, 2)
+			if !assert.NoError(t, err) || !assert.Len(t, sampled, 2) {
+				return -1
+			}
+
+			for _, p := range sampled {
+				if _, sybil := sybils[p.ID().PublicKey()]; sybil {
+					count++
+				}
+			}
+		}
+
+		return count
+	}
+
+	snapshot := faucet.Ledger().Snapshot()
+
+	assert.Equal(t, 0, countSybils(StakeWeightedSampler{}, snapshot))
+	assert.True(t, countSybils(UniformSampler{}, snapshot) > 0)
+
+	// Peers are to be sampled uniformly should their stakes be unknown.
+	assert.True(t, countSybils(StakeWeightedSampler{}, nil) > 0)
+}
+
+// TestStakeWeightedSampler_SybilCluster has a few nodes place stake and sample peers by stake,
+// after which Sybil nodes that have placed no stake join, outnumber the staked nodes, and
+// answer every query with bogus blocks. The staked nodes must keep finalizing the same blocks.
+func TestStakeWeightedSampler_SybilCluster(t *testing.T) {
+	testnet, err := NewTestNetwork(WithSimNetwork(NewSimNetwork(6)), WithoutFaucet())
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	faucet, err := testnet.AddNode(WithWallet(FaucetWallet), WithSampler(StakeWeightedSampler{}))
+	FailTest(t, err)
+
+	testnet.SetFaucet(faucet)
+
+	honest := []*TestLedger{faucet}
+
+	for i := 0; i < 2; i++ {
+		node, err := testnet.AddNode(WithSampler(StakeWeightedSampler{}))
+		FailTest(t, err)
+
+		honest = append(honest, node)
+	}
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	for _, node := range honest[1:] {
+		_, err = faucet.Pay(node, 1000000)
+		FailTest(t, err)
+
+		FailTest(t, node.WaitUntilBalance(1000000))
+	}
+
+	for _, node := range honest {
+		_, err = node.PlaceStake(9001)
+		FailTest(t, err)
+
+		FailTest(t, node.WaitUntilStake(9001))
+	}
+
+	for _, node := range honest {
+		for _, staker := range honest {
+			FailTest(t, waitFor(func() bool { return node.StakeOfAccount(staker) == 9001 }))
+		}
+	}
+
+	const numSybils = 8
+
+	for i := 0; i < numSybils; i++ {
+		_, err := testnet.AddNode(WithByzantine(ByzantineRandomBlocks))
+		FailTest(t, err)
+	}
+
+	FailTest(t, waitFor(func() bool {
+		return len(filterActivePeers(faucet.Client().ClosestPeers())) == len(honest)-1+numSybils
+	}))
+
+	alice := honest[1]
+	balance := alice.Balance()
+
+	for i := uint64(1); i <= 3; i++ {
+		tx, err := faucet.Pay(alice, 1000)
+		FailTest(t, err)
+
+		broadcast(tx, honest)
+
+		for _, node := range honest {
+			FailTest(t, waitFor(func() bool { return node.BalanceOfAccount(alice) == balance+1000*i }))
+		}
+	}
+
+	for _, node := range honest {
+		FailTest(t, node.WaitUntilBlock(faucet.BlockIndex()))
+	}
+
+	assertSameChain(t, honest)
+}
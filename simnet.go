@@ -0,0 +1,462 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit integration
+
+package wavelet
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/perlin-network/noise/edwards25519"
+	"github.com/perlin-network/noise/skademlia"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	simBufferSize = 1024 * 1024
+	simBasePort   = 30000
+)
+
+// SimClock is the source of time that the simulated network uses to delay messages.
+type SimClock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type manualTimer struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// ManualClock is a SimClock that only moves forward once it is advanced, such that messages
+// delayed by the simulated network are only delivered when a test decides so. It only governs
+// the delivery of messages: the timers of every ledger still run on the wall clock.
+type ManualClock struct {
+	sync.Mutex
+
+	now    time.Time
+	timers []manualTimer
+}
+
+func NewManualClock() *ManualClock {
+	return &ManualClock{now: time.Unix(0, 0)}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.now
+}
+
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	ch := make(chan time.Time, 1)
+
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.timers = append(c.timers, manualTimer{deadline: c.now.Add(d), ch: ch})
+
+	return ch
+}
+
+// Advance moves the clock forward by d, firing all timers whose deadline has passed in
+// order of their deadline.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+
+	pending := c.timers[:0]
+
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+
+		timer.ch <- timer.deadline
+	}
+
+	c.timers = pending
+}
+
+// SimStats counts the messages that were sent through a simulated network.
+type SimStats struct {
+	Delivered   uint64
+	Dropped     uint64
+	Partitioned uint64
+}
+
+// simLink holds the fault injection state of messages sent from one node to another. Each
+// link has its own random number generator seeded off of the network seed and the address
+// of both of its ends, such that the faults injected into a link only depend on the seed
+// and on the number of messages sent through the link so far.
+type simLink struct {
+	sync.Mutex
+
+	rng *rand.Rand
+}
+
+// SimNetwork is an in-process network that test ledgers may communicate through in place of
+// TCP. Messages sent through it may be delayed, dropped, or blocked by partitions.
+//
+// Every node connected to a SimNetwork sends messages to its peers through an in-memory
+// connection and a set of gRPC interceptors, so the Gossiper, Protocol and SyncManager of a
+// ledger are all subject to the faults injected by the network. Unary calls are subject to
+// faults per message, while streams are subject to faults when they are opened.
+//
+// The keys of nodes, and the faults injected into every link, are derived from a seed so that
+// the faults of a scenario may be replayed. A scenario as a whole is not deterministic however,
+// as the timers of every ledger run on the wall clock, and as the scheduling of the goroutines
+// of every ledger affects the order in which messages are sent.
+type SimNetwork struct {
+	sync.RWMutex
+
+	seed  int64
+	clock SimClock
+
+	keys *rand.Rand
+
+	listeners  map[string]*bufconn.Listener
+	links      map[string]*simLink
+	partitions map[string]int
+
+	minLatency time.Duration
+	maxLatency time.Duration
+	dropRate   float64
+
+	stats SimStats
+}
+
+type SimNetworkOption func(n *SimNetwork)
+
+// WithSimClock sets the clock used to delay messages. By default, the wall clock is used.
+func WithSimClock(clock SimClock) SimNetworkOption {
+	return func(n *SimNetwork) {
+		n.clock = clock
+	}
+}
+
+// WithSimLatency sets the range of latency every message sent through the network is
+// delayed by.
+func WithSimLatency(min, max time.Duration) SimNetworkOption {
+	return func(n *SimNetwork) {
+		n.minLatency, n.maxLatency = min, max
+	}
+}
+
+// WithSimDropRate sets the probability at which messages sent through the network are
+// dropped.
+func WithSimDropRate(rate float64) SimNetworkOption {
+	return func(n *SimNetwork) {
+		n.dropRate = rate
+	}
+}
+
+func NewSimNetwork(seed int64, opts ...SimNetworkOption) *SimNetwork {
+	n := &SimNetwork{
+		seed:  seed,
+		clock: realClock{},
+
+		keys: rand.New(rand.NewSource(seed)), // nolint:gosec
+
+		listeners:  make(map[string]*bufconn.Listener),
+		links:      make(map[string]*simLink),
+		partitions: make(map[string]int),
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+func (n *SimNetwork) Seed() int64 {
+	return n.seed
+}
+
+func (n *SimNetwork) Clock() SimClock {
+	return n.clock
+}
+
+func (n *SimNetwork) SetLatency(min, max time.Duration) {
+	n.Lock()
+	n.minLatency, n.maxLatency = min, max
+	n.Unlock()
+}
+
+func (n *SimNetwork) SetDropRate(rate float64) {
+	n.Lock()
+	n.dropRate = rate
+	n.Unlock()
+}
+
+// Partition splits the network such that nodes may only communicate with nodes within the
+// same group. Nodes that are not in any group may only communicate amongst themselves.
+func (n *SimNetwork) Partition(groups ...[]*TestLedger) {
+	n.Lock()
+	defer n.Unlock()
+
+	n.partitions = make(map[string]int)
+
+	for i, group := range groups {
+		for _, node := range group {
+			n.partitions[node.Addr()] = i + 1
+		}
+	}
+}
+
+// Isolate cuts off the given nodes from the rest of the network, and from each other.
+func (n *SimNetwork) Isolate(nodes ...*TestLedger) {
+	groups := make([][]*TestLedger, 0, len(nodes))
+
+	for _, node := range nodes {
+		groups = append(groups, []*TestLedger{node})
+	}
+
+	n.Partition(groups...)
+}
+
+// Heal removes all partitions from the network.
+func (n *SimNetwork) Heal() {
+	n.Lock()
+	n.partitions = make(map[string]int)
+	n.Unlock()
+}
+
+func (n *SimNetwork) Stats() SimStats {
+	n.RLock()
+	defer n.RUnlock()
+
+	return n.stats
+}
+
+// newKeys derives the next S/Kademlia keypair from the seed of the network.
+func (n *SimNetwork) newKeys() (*skademlia.Keypair, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	for {
+		_, privateKey, err := edwards25519.GenerateKey(n.keys)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := skademlia.LoadKeys(privateKey, sys.SKademliaC1, sys.SKademliaC2)
+		if err == nil {
+			return keys, nil
+		}
+	}
+}
+
+// listen registers a new node onto the network, returning the address it is reachable at.
+func (n *SimNetwork) listen() (string, net.Listener) {
+	n.Lock()
+	defer n.Unlock()
+
+	// Addresses must resolve to an IP, as S/Kademlia checks that the address a peer is
+	// reachable at matches the address written in its ID.
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(simBasePort+len(n.listeners)))
+	ln := bufconn.Listen(simBufferSize)
+
+	n.listeners[addr] = ln
+
+	return addr, ln
+}
+
+// dialOptions returns the options the S/Kademlia client of the node at the given address
+// is to dial its peers with.
+func (n *SimNetwork) dialOptions(from string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithContextDialer(n.dial),
+		grpc.WithUnaryInterceptor(func(
+			ctx context.Context, method string, req, reply interface{},
+			cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+		) error {
+			if err := n.transmit(ctx, from, cc.Target()); err != nil {
+				return err
+			}
+
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithStreamInterceptor(func(
+			ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+			method string, streamer grpc.Streamer, opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			if err := n.transmit(ctx, from, cc.Target()); err != nil {
+				return nil, err
+			}
+
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	}
+}
+
+func (n *SimNetwork) dial(ctx context.Context, addr string) (net.Conn, error) {
+	n.RLock()
+	ln, exists := n.listeners[addr]
+	n.RUnlock()
+
+	if !exists {
+		return nil, errors.Errorf("simnet: no node is listening on %s", addr)
+	}
+
+	conn, err := ln.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return simConn{Conn: conn, remote: remote}, nil
+}
+
+// simConn is an in-memory connection that reports the address it was dialed with as the
+// address of its remote end.
+type simConn struct {
+	net.Conn
+
+	remote net.Addr
+}
+
+func (c simConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (n *SimNetwork) link(from, to string) *simLink {
+	key := from + "->" + to
+
+	n.Lock()
+	defer n.Unlock()
+
+	link, exists := n.links[key]
+	if !exists {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+
+		link = &simLink{rng: rand.New(rand.NewSource(n.seed ^ int64(h.Sum64())))} // nolint:gosec
+		n.links[key] = link
+	}
+
+	return link
+}
+
+// fault decides whether or not the next message sent from one node to another is to be
+// dropped, and otherwise how long it is to be delayed by.
+func (n *SimNetwork) fault(from, to string) (bool, time.Duration) {
+	n.RLock()
+	dropRate, min, max := n.dropRate, n.minLatency, n.maxLatency
+	n.RUnlock()
+
+	link := n.link(from, to)
+
+	link.Lock()
+	defer link.Unlock()
+
+	// Both values are always drawn so that the sequence of faults on a link does not
+	// depend on the rates of faults being changed midway through a scenario.
+
+	drop := link.rng.Float64() < dropRate
+	latency := min
+
+	jitter := link.rng.Int63()
+	if max > min {
+		latency += time.Duration(jitter % int64(max-min))
+	}
+
+	return drop, latency
+}
+
+// transmit simulates sending a message from one node to another, returning an error
+// should the message not be delivered.
+func (n *SimNetwork) transmit(ctx context.Context, from, to string) error {
+	n.RLock()
+	partitioned := n.partitions[from] != n.partitions[to]
+	n.RUnlock()
+
+	if partitioned {
+		n.Lock()
+		n.stats.Partitioned++
+		n.Unlock()
+
+		return status.Errorf(codes.Unavailable, "simnet: %s is partitioned from %s", from, to)
+	}
+
+	drop, latency := n.fault(from, to)
+
+	if drop {
+		n.Lock()
+		n.stats.Dropped++
+		n.Unlock()
+
+		return status.Errorf(codes.Unavailable, "simnet: message from %s to %s was dropped", from, to)
+	}
+
+	if latency > 0 {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-n.clock.After(latency):
+		}
+	}
+
+	n.Lock()
+	n.stats.Delivered++
+	n.Unlock()
+
+	return nil
+}
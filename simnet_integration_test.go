@@ -0,0 +1,149 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package wavelet
+
+import (
+	"flag"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+var simNodes = flag.Int("sim.nodes", 32, "number of nodes spawned by TestSimNetwork_Large")
+
+func TestSimNetwork_Consensus(t *testing.T) {
+	sim := NewSimNetwork(1, WithSimLatency(1*time.Millisecond, 5*time.Millisecond))
+
+	testnet, err := NewTestNetwork(WithSimNetwork(sim))
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode()
+	FailTest(t, err)
+
+	bob, err := testnet.AddNode()
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	_, err = testnet.Faucet().Pay(alice, 1000000)
+	FailTest(t, err)
+
+	FailTest(t, alice.WaitUntilBalance(1000000))
+	FailTest(t, waitFor(func() bool { return bob.BalanceOfAccount(alice) == 1000000 }))
+
+	stats := sim.Stats()
+	assert.True(t, stats.Delivered > 0)
+	assert.EqualValues(t, 0, stats.Dropped)
+	assert.EqualValues(t, 0, stats.Partitioned)
+}
+
+func TestSimNetwork_Partition(t *testing.T) {
+	sim := NewSimNetwork(2)
+
+	testnet, err := NewTestNetwork(WithSimNetwork(sim))
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode()
+	FailTest(t, err)
+
+	bob, err := testnet.AddNode()
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	sim.Isolate(bob)
+
+	tx, err := testnet.Faucet().Pay(alice, 1000000)
+	FailTest(t, err)
+
+	FailTest(t, alice.WaitUntilBalance(1000000))
+
+	assert.False(t, bob.Ledger().Transactions().Has(tx.ID))
+	assert.EqualValues(t, 0, bob.BalanceOfAccount(alice))
+	assert.True(t, sim.Stats().Partitioned > 0)
+
+	sim.Heal()
+
+	// Bob only pulls the blocks that were finalized while it was partitioned
+	// once it has fallen far enough behind to sync.
+
+	for i := uint64(1); i <= conf.GetSyncIfBlockIndicesDifferBy(); i++ {
+		_, err = testnet.Faucet().Pay(alice, 1)
+		FailTest(t, err)
+
+		FailTest(t, alice.WaitUntilBalance(1000000+i))
+	}
+
+	FailTest(t, waitFor(func() bool { return bob.BalanceOfAccount(alice) >= 1000000 }))
+}
+
+// TestSimNetwork_Large spawns a simulated network of -sim.nodes nodes besides the faucet, and
+// has every node finalize a few transfers. Nodes bootstrap off of random peers rather than all
+// off of the faucet, as the routing table of the faucet only has room for so many peers.
+func TestSimNetwork_Large(t *testing.T) {
+	sim := NewSimNetwork(3, WithSimLatency(1*time.Millisecond, 5*time.Millisecond))
+
+	testnet, err := NewTestNetwork(WithSimNetwork(sim))
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	rng := rand.New(rand.NewSource(sim.Seed())) // nolint:gosec
+
+	nodes := []*TestLedger{testnet.Faucet()}
+
+	for len(nodes) <= *simNodes {
+		peers := make([]*TestLedger, 0, len(nodes))
+		for _, i := range rng.Perm(len(nodes)) {
+			peers = append(peers, nodes[i])
+		}
+
+		node, err := testnet.AddNode(WithPeers(peers...))
+		FailTest(t, err)
+
+		nodes = append(nodes, node)
+	}
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	alice := nodes[1]
+
+	for i := uint64(1); i <= 3; i++ {
+		tx, err := testnet.Faucet().Pay(alice, 1000)
+		FailTest(t, err)
+
+		broadcast(tx, nodes)
+
+		for _, node := range nodes {
+			FailTest(t, waitFor(func() bool { return node.BalanceOfAccount(alice) == 1000*i }))
+		}
+	}
+
+	assertSameChain(t, nodes)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package wavelet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManualClock(t *testing.T) {
+	clock := NewManualClock()
+	start := clock.Now()
+
+	a := clock.After(2 * time.Second)
+	b := clock.After(1 * time.Second)
+
+	clock.Advance(500 * time.Millisecond)
+
+	select {
+	case <-a:
+		t.Fatal("timer fired before its deadline")
+	case <-b:
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, start.Add(1*time.Second), <-b)
+
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-a)
+	assert.Equal(t, start.Add(6*time.Second), clock.Now())
+}
+
+func TestSimNetwork_ReplayFaults(t *testing.T) {
+	faults := func(seed int64) ([]bool, []time.Duration) {
+		sim := NewSimNetwork(seed, WithSimDropRate(0.5), WithSimLatency(10*time.Millisecond, 100*time.Millisecond))
+
+		var (
+			drops     []bool
+			latencies []time.Duration
+		)
+
+		for i := 0; i < 64; i++ {
+			drop, latency := sim.fault("127.0.0.1:30000", "127.0.0.1:30001")
+
+			assert.True(t, latency >= 10*time.Millisecond && latency < 100*time.Millisecond)
+
+			drops = append(drops, drop)
+			latencies = append(latencies, latency)
+		}
+
+		return drops, latencies
+	}
+
+	drops, latencies := faults(42)
+	replayedDrops, replayedLatencies := faults(42)
+
+	assert.Equal(t, drops, replayedDrops)
+	assert.Equal(t, latencies, replayedLatencies)
+
+	assert.Contains(t, drops, true)
+	assert.Contains(t, drops, false)
+
+	_, otherLatencies := faults(43)
+	assert.NotEqual(t, latencies, otherLatencies)
+
+	a, err := NewSimNetwork(42).newKeys()
+	assert.NoError(t, err)
+
+	b, err := NewSimNetwork(42).newKeys()
+	assert.NoError(t, err)
+
+	assert.Equal(t, a.PublicKey(), b.PublicKey())
+}
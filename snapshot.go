@@ -0,0 +1,221 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/internal/filebuffer"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+)
+
+// A snapshot is laid out as follows, and is followed by a BLAKE2b-256 checksum of all of its
+// contents such that it may be verified before it is loaded:
+//
+// [magic | version | block length (uint32) | block | merkle root | diff length (uint64) | diff]
+const (
+	snapshotMagic   = "WAVELET_SNAPSHOT"
+	snapshotVersion = byte(1)
+)
+
+var ErrSnapshotCorrupt = errors.New("snapshot: corrupt or tampered with")
+
+// ExportSnapshot writes a snapshot of the state of all accounts as of a finalized block. Like the
+// state sent to syncing peers, it comprises of all changes made to state since genesis. The
+// snapshot may be loaded into an empty database through ImportSnapshot to bootstrap a node without
+// having to sync its state from its peers.
+func ExportSnapshot(w io.Writer, state *avl.Tree, block Block) error {
+	if checksum := state.Checksum(); checksum != block.Merkle {
+		return errors.Errorf("snapshot: state has merkle root %x, but block %d has merkle root %x",
+			checksum, block.Index, block.Merkle)
+	}
+
+	pool := filebuffer.NewPool(sys.SyncPooledFileSize, "")
+
+	diff := pool.GetUnbounded()
+	defer pool.Put(diff)
+
+	if err := state.DumpDiff(0, diff); err != nil {
+		return errors.Wrap(err, "snapshot: failed to dump state")
+	}
+
+	hasher, err := blake2b.New256(nil)
+	if err != nil {
+		return err
+	}
+
+	mw := io.MultiWriter(w, hasher)
+
+	blockBuf := block.Marshal()
+
+	var header bytes.Buffer
+
+	header.WriteString(snapshotMagic)
+	header.WriteByte(snapshotVersion)
+
+	var buf [8]byte
+
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(blockBuf)))
+	header.Write(buf[:4])
+	header.Write(blockBuf)
+	header.Write(block.Merkle[:])
+
+	binary.BigEndian.PutUint64(buf[:8], uint64(diff.Len()))
+	header.Write(buf[:8])
+
+	if _, err := mw.Write(header.Bytes()); err != nil {
+		return errors.Wrap(err, "snapshot: failed to write header")
+	}
+
+	if _, err := io.Copy(mw, diff); err != nil {
+		return errors.Wrap(err, "snapshot: failed to write state")
+	}
+
+	if _, err := w.Write(hasher.Sum(nil)); err != nil {
+		return errors.Wrap(err, "snapshot: failed to write checksum")
+	}
+
+	return nil
+}
+
+// ImportSnapshot verifies and loads a snapshot written by ExportSnapshot into an empty database,
+// returning the block the snapshot was taken at. The genesis of the network the snapshot was
+// exported from is applied first, and follows the same semantics as the WithGenesis option.
+func ImportSnapshot(kv store.KV, r io.Reader, genesis *string) (Block, error) {
+	if _, err := kv.Get(keyBlockLatestIx[:]); err == nil {
+		return Block{}, errors.New("snapshot: may only be imported into an empty database")
+	}
+
+	hasher, err := blake2b.New256(nil)
+	if err != nil {
+		return Block{}, err
+	}
+
+	tr := io.TeeReader(r, hasher)
+
+	magic := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(tr, magic); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to read header")
+	}
+
+	if string(magic[:len(snapshotMagic)]) != snapshotMagic {
+		return Block{}, errors.New("snapshot: not a snapshot")
+	}
+
+	if version := magic[len(snapshotMagic)]; version != snapshotVersion {
+		return Block{}, errors.Errorf("snapshot: unsupported version %d", version)
+	}
+
+	var buf [8]byte
+
+	if _, err := io.ReadFull(tr, buf[:4]); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to read block length")
+	}
+
+	blockBuf := make([]byte, binary.BigEndian.Uint32(buf[:4]))
+	if _, err := io.ReadFull(tr, blockBuf); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to read block")
+	}
+
+	block, err := UnmarshalBlock(bytes.NewReader(blockBuf))
+	if err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to decode block")
+	}
+
+	var merkle MerkleNodeID
+	if _, err := io.ReadFull(tr, merkle[:]); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to read merkle root")
+	}
+
+	if merkle != block.Merkle {
+		return Block{}, ErrSnapshotCorrupt
+	}
+
+	if _, err := io.ReadFull(tr, buf[:8]); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to read state length")
+	}
+
+	pool := filebuffer.NewPool(sys.SyncPooledFileSize, "")
+
+	diff := pool.GetUnbounded()
+	defer pool.Put(diff)
+
+	size := int64(binary.BigEndian.Uint64(buf[:8]))
+
+	if n, err := io.CopyN(diff, tr, size); err != nil {
+		return Block{}, errors.Wrapf(err, "snapshot: only read %d out of %d byte(s) of state", n, size)
+	}
+
+	var checksum [blake2b.Size256]byte
+	if _, err := io.ReadFull(r, checksum[:]); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to read checksum")
+	}
+
+	if !bytes.Equal(hasher.Sum(nil), checksum[:]) {
+		return Block{}, ErrSnapshotCorrupt
+	}
+
+	if n, _ := io.Copy(ioutil.Discard, r); n > 0 {
+		return Block{}, ErrSnapshotCorrupt
+	}
+
+	accounts := NewAccounts(kv)
+
+	performInception(accounts.tree, genesis)
+
+	if err := accounts.Commit(nil); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to commit genesis")
+	}
+
+	snapshot := accounts.Snapshot()
+
+	if err := snapshot.ApplyDiff(diff); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to load state")
+	}
+
+	if checksum := snapshot.Checksum(); checksum != block.Merkle {
+		return Block{}, errors.Errorf("snapshot: got merkle root %x but expected %x", checksum, block.Merkle)
+	}
+
+	snapshot.SetViewID(block.Index)
+
+	blocks, err := NewBlocks(kv, conf.GetPruningLimit())
+	if err != nil && errors.Cause(err) != store.ErrNotFound {
+		return Block{}, errors.Wrap(err, "snapshot: failed to load blocks")
+	}
+
+	if _, err := blocks.Save(&block); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to save block")
+	}
+
+	if err := accounts.Commit(snapshot); err != nil {
+		return Block{}, errors.Wrap(err, "snapshot: failed to save state")
+	}
+
+	return block, nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package wavelet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/perlin-network/wavelet/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	accounts := NewAccounts(store.NewInmem())
+
+	performInception(accounts.tree, nil)
+	assert.NoError(t, accounts.Commit(nil))
+
+	state := accounts.Snapshot()
+	state.SetViewID(7)
+
+	ids := make([]AccountID, 100)
+	for i := range ids {
+		ids[i] = AccountID{byte(i), 1}
+
+		WriteAccountBalance(state, ids[i], uint64(i))
+		WriteAccountStake(state, ids[i], uint64(i)*2)
+	}
+
+	assert.NoError(t, accounts.Commit(state))
+
+	block := NewBlock(7, state.Checksum())
+
+	var buf bytes.Buffer
+	assert.NoError(t, ExportSnapshot(&buf, accounts.Snapshot(), block))
+
+	// Snapshots may not be exported given a block whose merkle root does not match the state.
+	assert.Error(t, ExportSnapshot(&bytes.Buffer{}, accounts.Snapshot(), NewBlock(7, MerkleNodeID{})))
+
+	kv := store.NewInmem()
+
+	imported, err := ImportSnapshot(kv, bytes.NewReader(buf.Bytes()), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, block.ID, imported.ID)
+
+	blocks, err := NewBlocks(kv, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, block.ID, blocks.Latest().ID)
+
+	restored := NewAccounts(kv).Snapshot()
+	assert.Equal(t, block.Merkle, restored.Checksum())
+
+	for i, id := range ids {
+		balance, _ := ReadAccountBalance(restored, id)
+		assert.EqualValues(t, i, balance)
+
+		stake, _ := ReadAccountStake(restored, id)
+		assert.EqualValues(t, i*2, stake)
+	}
+
+	// Snapshots may only be imported into an empty database.
+	_, err = ImportSnapshot(kv, bytes.NewReader(buf.Bytes()), nil)
+	assert.Error(t, err)
+
+	// Snapshots which have been tampered with are rejected.
+	for _, i := range []int{len(snapshotMagic) + 8, buf.Len() / 2, buf.Len() - 1} {
+		tampered := append([]byte{}, buf.Bytes()...)
+		tampered[i] ^= 0xFF
+
+		_, err = ImportSnapshot(store.NewInmem(), bytes.NewReader(tampered), nil)
+		assert.Equal(t, ErrSnapshotCorrupt, errors.Cause(err))
+	}
+
+	_, err = ImportSnapshot(store.NewInmem(), bytes.NewReader(buf.Bytes()[:buf.Len()-1]), nil)
+	assert.Error(t, err)
+}
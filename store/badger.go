@@ -20,6 +20,7 @@
 package store
 
 import (
+	"bytes"
 	"os"
 	"sync"
 	"time"
@@ -70,8 +71,14 @@ func NewBadger(dir string) (*badgerKV, error) { // nolint:golint
 		return nil, err
 	}
 
+	return openBadger(dir, false)
+}
+
+func openBadger(dir string, readOnly bool) (*badgerKV, error) {
 	// Explicitly specify compression. Because the default compression with CGO is ZSTD, and without CGO it's Snappy.
-	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nullLog{}).WithCompression(options.Snappy))
+	opts := badger.DefaultOptions(dir).WithLogger(nullLog{}).WithCompression(options.Snappy).WithReadOnly(readOnly)
+
+	db, err := badger.Open(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +89,10 @@ func NewBadger(dir string) (*badgerKV, error) { // nolint:golint
 		closeCh: make(chan struct{}),
 	}
 
-	b.gc(1 * time.Minute)
+	// Values may only be garbage collected from databases that may be written to.
+	if !readOnly {
+		b.gc(1 * time.Minute)
+	}
 
 	return b, nil
 }
@@ -164,6 +174,14 @@ func (b *badgerKV) CommitWriteBatch(batch WriteBatch) error {
 	return wb.batch.Flush()
 }
 
+func (b *badgerKV) NewIterator(start, end []byte) Iterator {
+	return newBadgerIterator(b.db.NewTransaction(false), true, start, end)
+}
+
+func (b *badgerKV) NewSnapshot() (Snapshot, error) {
+	return &badgerSnapshot{txn: b.db.NewTransaction(false)}, nil
+}
+
 func (b *badgerKV) gc(interval time.Duration) {
 	b.closeWg.Add(1)
 
@@ -197,6 +215,143 @@ func (b *badgerKV) gc(interval time.Duration) {
 	}()
 }
 
+var _ Iterator = (*badgerIterator)(nil)
+
+type badgerIterator struct {
+	txn *badger.Txn
+	it  *badger.Iterator
+
+	// Whether or not the transaction is discarded once the iterator is released.
+	ownsTxn bool
+
+	start, end []byte
+	started    bool
+
+	key, value []byte
+	err        error
+}
+
+func newBadgerIterator(txn *badger.Txn, ownsTxn bool, start, end []byte) *badgerIterator {
+	return &badgerIterator{
+		txn:     txn,
+		it:      txn.NewIterator(badger.DefaultIteratorOptions),
+		ownsTxn: ownsTxn,
+		start:   start,
+		end:     end,
+	}
+}
+
+func (i *badgerIterator) Next() bool {
+	if i.err != nil {
+		return false
+	}
+
+	if !i.started {
+		i.started = true
+
+		if i.start != nil {
+			i.it.Seek(i.start)
+		} else {
+			i.it.Rewind()
+		}
+	} else {
+		i.it.Next()
+	}
+
+	if !i.it.Valid() {
+		return false
+	}
+
+	item := i.it.Item()
+
+	if i.end != nil && bytes.Compare(item.Key(), i.end) >= 0 {
+		return false
+	}
+
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		i.err = err
+		return false
+	}
+
+	if value == nil {
+		value = []byte{}
+	}
+
+	i.key = item.KeyCopy(nil)
+	i.value = value
+
+	return true
+}
+
+func (i *badgerIterator) Key() []byte {
+	return i.key
+}
+
+func (i *badgerIterator) Value() []byte {
+	return i.value
+}
+
+func (i *badgerIterator) Error() error {
+	return i.err
+}
+
+func (i *badgerIterator) Release() {
+	i.it.Close()
+
+	if i.ownsTxn {
+		i.txn.Discard()
+	}
+}
+
+var _ Snapshot = (*badgerSnapshot)(nil)
+
+// badgerSnapshot is a read-only transaction, which reads from a consistent view of the database.
+type badgerSnapshot struct {
+	txn *badger.Txn
+}
+
+func (s *badgerSnapshot) Get(key []byte) ([]byte, error) {
+	item, err := s.txn.Get(key)
+	if err != nil {
+		return nil, errors.Wrap(ErrNotFound, err.Error())
+	}
+
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, errors.Wrap(ErrNotFound, err.Error())
+	}
+
+	if value == nil {
+		value = []byte{}
+	}
+
+	return value, nil
+}
+
+func (s *badgerSnapshot) MultiGet(keys ...[]byte) ([][]byte, error) {
+	var bufs = make([][]byte, len(keys))
+
+	for i := range keys {
+		b, err := s.Get(keys[i])
+		if err != nil {
+			return nil, err
+		}
+
+		bufs[i] = b
+	}
+
+	return bufs, nil
+}
+
+func (s *badgerSnapshot) NewIterator(start, end []byte) Iterator {
+	return newBadgerIterator(s.txn, false, start, end)
+}
+
+func (s *badgerSnapshot) Release() {
+	s.txn.Discard()
+}
+
 type nullLog struct{}
 
 func (l nullLog) Errorf(f string, v ...interface{})   {}
@@ -0,0 +1,397 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// bboltFile is the name of the file within the database directory that bbolt stores all of its
+// data in.
+const bboltFile = "data.db"
+
+// bboltMmapSize is the initial size of the memory map of the database. Reads and writes proceed
+// concurrently so long as the database need not grow past the memory map.
+const bboltMmapSize = 1 << 30
+
+// bboltBucket is the bucket all keys are stored under.
+var bboltBucket = []byte("kv")
+
+var _ WriteBatch = (*bboltWriteBatch)(nil)
+
+// bboltWriteBatch buffers put and delete operations in memory, and writes all of them in a single
+// transaction once committed.
+type bboltWriteBatch struct {
+	ops []bboltOp
+}
+
+type bboltOp struct {
+	key, value []byte
+	delete     bool
+}
+
+func (b *bboltWriteBatch) Put(key, value []byte) error {
+	b.ops = append(b.ops, bboltOp{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+	return nil
+}
+
+func (b *bboltWriteBatch) Delete(key []byte) error {
+	b.ops = append(b.ops, bboltOp{key: append([]byte{}, key...), delete: true})
+	return nil
+}
+
+func (b *bboltWriteBatch) Clear() {
+	b.ops = b.ops[:0]
+}
+
+func (b *bboltWriteBatch) Count() int {
+	return len(b.ops)
+}
+
+func (b *bboltWriteBatch) Destroy() {
+	b.ops = nil
+}
+
+var _ KV = (*bboltKV)(nil)
+
+type bboltKV struct {
+	dir string
+	db  *bolt.DB
+}
+
+func NewBbolt(dir string) (*bboltKV, error) { // nolint:golint
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, bboltFile), 0600, &bolt.Options{
+		Timeout:         1 * time.Second,
+		InitialMmapSize: bboltMmapSize,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init bbolt")
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bboltBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "failed to init bbolt bucket")
+	}
+
+	return &bboltKV{dir: dir, db: db}, nil
+}
+
+func openBboltReadOnly(dir string) (*bboltKV, error) {
+	db, err := bolt.Open(filepath.Join(dir, bboltFile), 0600, &bolt.Options{
+		Timeout:  1 * time.Second,
+		ReadOnly: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open bbolt")
+	}
+
+	// The bucket can not be created should it not exist, and all reads rely on it.
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bboltBucket) == nil {
+			return errors.New("bbolt bucket does not exist")
+		}
+
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "failed to open bbolt bucket")
+	}
+
+	return &bboltKV{dir: dir, db: db}, nil
+}
+
+func (b *bboltKV) Close() error {
+	return b.db.Close()
+}
+
+func (b *bboltKV) Get(key []byte) ([]byte, error) {
+	var value []byte
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		value, err = bboltGet(tx, key)
+
+		return err
+	})
+
+	return value, err
+}
+
+func (b *bboltKV) MultiGet(keys ...[]byte) ([][]byte, error) {
+	var bufs = make([][]byte, len(keys))
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		for i := range keys {
+			buf, err := bboltGet(tx, keys[i])
+			if err != nil {
+				return err
+			}
+
+			bufs[i] = buf
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bufs, nil
+}
+
+func (b *bboltKV) Put(key, value []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bboltBucket).Put(key, value)
+	})
+}
+
+func (b *bboltKV) Delete(key []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bboltBucket).Delete(key)
+	})
+}
+
+func (b *bboltKV) NewWriteBatch() WriteBatch {
+	return &bboltWriteBatch{}
+}
+
+func (b *bboltKV) CommitWriteBatch(batch WriteBatch) error {
+	wb, ok := batch.(*bboltWriteBatch)
+	if !ok {
+		return errors.New("bbolt: not fed in a proper bbolt write batch")
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bboltBucket)
+
+		for _, op := range wb.ops {
+			var err error
+
+			if op.delete {
+				err = bucket.Delete(op.key)
+			} else {
+				err = bucket.Put(op.key, op.value)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// NewIterator iterates over keys in chunks, with each chunk read within its own read-only
+// transaction. The iterator therefore does not observe a consistent view of the store should it be
+// written to while iterating.
+func (b *bboltKV) NewIterator(start, end []byte) Iterator {
+	return &bboltIterator{db: b.db, start: start, end: end}
+}
+
+// NewSnapshot opens a read-only transaction. Note that bbolt is unable to grow its memory map while
+// read-only transactions are open, such that writes which grow the database past bboltMmapSize
+// block until all snapshots are released. Snapshots should hence be released promptly.
+func (b *bboltKV) NewSnapshot() (Snapshot, error) {
+	tx, err := b.db.Begin(false)
+	if err != nil {
+		return nil, errors.Wrap(err, "bbolt: failed to take snapshot")
+	}
+
+	return &bboltSnapshot{tx: tx}, nil
+}
+
+func (b *bboltKV) Dir() string {
+	return b.dir
+}
+
+// bboltGet returns a copy of the value of a key, as values returned by bbolt are only valid
+// throughout the lifetime of a transaction.
+func bboltGet(tx *bolt.Tx, key []byte) ([]byte, error) {
+	value := tx.Bucket(bboltBucket).Get(key)
+	if value == nil {
+		return nil, errors.Wrapf(ErrNotFound, "bbolt: key %x not found", key)
+	}
+
+	return append([]byte{}, value...), nil
+}
+
+// bboltIteratorChunkSize is the max number of key-value pairs an iterator reads at once.
+const bboltIteratorChunkSize = 256
+
+var _ Iterator = (*bboltIterator)(nil)
+
+// bboltIterator reads key-value pairs in chunks, such that it does not keep a read-only
+// transaction open in between calls to Next, unless it iterates within a snapshot.
+type bboltIterator struct {
+	db *bolt.DB
+	tx *bolt.Tx // Only set if iterating within a snapshot.
+
+	start, end []byte
+
+	keys, values [][]byte
+	pos          int
+	last         []byte
+	done         bool
+
+	err error
+}
+
+func (i *bboltIterator) Next() bool {
+	if i.err != nil {
+		return false
+	}
+
+	if i.pos+1 < len(i.keys) {
+		i.pos++
+		return true
+	}
+
+	if i.done {
+		i.pos = len(i.keys)
+		return false
+	}
+
+	if i.tx != nil {
+		i.err = i.fill(i.tx)
+	} else {
+		i.err = i.db.View(i.fill)
+	}
+
+	if i.err != nil || len(i.keys) == 0 {
+		return false
+	}
+
+	i.pos = 0
+
+	return true
+}
+
+// fill reads the next chunk of key-value pairs after the last key read.
+func (i *bboltIterator) fill(tx *bolt.Tx) error {
+	i.keys, i.values = i.keys[:0], i.values[:0]
+
+	cursor := tx.Bucket(bboltBucket).Cursor()
+
+	var key, value []byte
+
+	switch {
+	case i.last != nil:
+		key, value = cursor.Seek(i.last)
+		if key != nil && bytes.Equal(key, i.last) {
+			key, value = cursor.Next()
+		}
+	case i.start != nil:
+		key, value = cursor.Seek(i.start)
+	default:
+		key, value = cursor.First()
+	}
+
+	for ; len(i.keys) < bboltIteratorChunkSize; key, value = cursor.Next() {
+		if key == nil || (i.end != nil && bytes.Compare(key, i.end) >= 0) {
+			i.done = true
+			break
+		}
+
+		i.keys = append(i.keys, append([]byte{}, key...))
+		i.values = append(i.values, append([]byte{}, value...))
+	}
+
+	if len(i.keys) > 0 {
+		i.last = i.keys[len(i.keys)-1]
+	}
+
+	return nil
+}
+
+func (i *bboltIterator) Key() []byte {
+	if i.pos >= len(i.keys) {
+		return nil
+	}
+
+	return i.keys[i.pos]
+}
+
+func (i *bboltIterator) Value() []byte {
+	if i.pos >= len(i.values) {
+		return nil
+	}
+
+	return i.values[i.pos]
+}
+
+func (i *bboltIterator) Error() error {
+	return i.err
+}
+
+func (i *bboltIterator) Release() {
+	i.keys, i.values = nil, nil
+	i.done = true
+}
+
+var _ Snapshot = (*bboltSnapshot)(nil)
+
+// bboltSnapshot is a read-only transaction, which reads from a consistent view of the database.
+type bboltSnapshot struct {
+	tx *bolt.Tx
+}
+
+func (s *bboltSnapshot) Get(key []byte) ([]byte, error) {
+	return bboltGet(s.tx, key)
+}
+
+func (s *bboltSnapshot) MultiGet(keys ...[]byte) ([][]byte, error) {
+	var bufs = make([][]byte, len(keys))
+
+	for i := range keys {
+		buf, err := bboltGet(s.tx, keys[i])
+		if err != nil {
+			return nil, err
+		}
+
+		bufs[i] = buf
+	}
+
+	return bufs, nil
+}
+
+func (s *bboltSnapshot) NewIterator(start, end []byte) Iterator {
+	return &bboltIterator{tx: s.tx, start: start, end: end}
+}
+
+func (s *bboltSnapshot) Release() {
+	_ = s.tx.Rollback()
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Names of the embedded storage engines which may be opened with Open.
+const (
+	EngineLevelDB = "level"
+	EngineBadger  = "badger"
+	EngineBbolt   = "bbolt"
+)
+
+// Engines lists the names of all embedded storage engines which may be opened with Open.
+var Engines = []string{EngineLevelDB, EngineBadger, EngineBbolt}
+
+// Open creates or opens a database located at dir using the storage engine named engine.
+func Open(engine string, dir string) (KV, error) {
+	switch engine {
+	case EngineLevelDB:
+		return NewLevelDB(dir)
+	case EngineBadger:
+		return NewBadger(dir)
+	case EngineBbolt:
+		return NewBbolt(dir)
+	default:
+		return nil, errors.Errorf("unknown database engine %q; expected one of %v", engine, Engines)
+	}
+}
+
+// OpenReadOnly opens an existing database located at dir using the storage engine named engine,
+// such that all writes to it fail.
+func OpenReadOnly(engine string, dir string) (KV, error) {
+	switch engine {
+	case EngineLevelDB:
+		return NewLevelDB(dir, WithReadOnly())
+	case EngineBadger:
+		return openBadger(dir, true)
+	case EngineBbolt:
+		return openBboltReadOnly(dir)
+	default:
+		return nil, errors.Errorf("unknown database engine %q; expected one of %v", engine, Engines)
+	}
+}
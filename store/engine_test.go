@@ -0,0 +1,71 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package store
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenReadOnly(t *testing.T) {
+	for _, engine := range Engines {
+		engine := engine
+
+		t.Run(engine, func(t *testing.T) {
+			path := "readonly_" + engine
+			_ = os.RemoveAll(path)
+
+			defer func() {
+				_ = os.RemoveAll(path)
+			}()
+
+			db, err := Open(engine, path)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.NoError(t, db.Put([]byte("key"), []byte("value")))
+			assert.NoError(t, db.Close())
+
+			db, err = OpenReadOnly(engine, path)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			defer func() {
+				_ = db.Close()
+			}()
+
+			v, err := db.Get([]byte("key"))
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("value"), v)
+
+			assert.Error(t, db.Put([]byte("key"), []byte("other")))
+		})
+	}
+
+	// Engines are only known by the names listed in Engines.
+	_, err := OpenReadOnly("leveldb", "readonly_leveldb")
+	assert.Error(t, err)
+}
@@ -21,6 +21,7 @@ package store
 
 import (
 	"bytes"
+	"sort"
 	"sync"
 
 	"github.com/huandu/skiplist"
@@ -200,6 +201,57 @@ func (s *inmemKV) Delete(key []byte) error {
 	return nil
 }
 
+// The returned iterator iterates over its own copy of all pairs within the range, such that it
+// is unaffected by writes made while iterating.
+func (s *inmemKV) NewIterator(start, end []byte) Iterator {
+	s.RLock()
+	defer s.RUnlock()
+
+	return &inmemIterator{pairs: s.pairs(start, end), pos: -1}
+}
+
+func (s *inmemKV) NewSnapshot() (Snapshot, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if s.db == nil {
+		return nil, errors.New("inmem: store is closed")
+	}
+
+	return &inmemSnapshot{pairs: s.pairs(nil, nil)}, nil
+}
+
+// pairs returns copies of all pairs whose keys are within the range [start, end) in ascending
+// order of keys.
+func (s *inmemKV) pairs(start, end []byte) []kvPair {
+	if s.db == nil {
+		return nil
+	}
+
+	var pairs []kvPair
+
+	for e := s.db.Front(); e != nil; e = e.Next() {
+		key := e.Key().([]byte)
+
+		if start != nil && bytes.Compare(key, start) < 0 {
+			continue
+		}
+
+		if end != nil && bytes.Compare(key, end) >= 0 {
+			break
+		}
+
+		value := e.Value.([]byte)
+
+		pairs = append(pairs, kvPair{
+			key:   append([]byte{}, key...),
+			value: append([]byte{}, value...),
+		})
+	}
+
+	return pairs
+}
+
 func (s *inmemKV) Dir() string {
 	return ""
 }
@@ -211,3 +263,95 @@ func NewInmem() *inmemKV { // nolint:golint
 
 	return &inmemKV{db: skiplist.New(comparator)}
 }
+
+var _ Iterator = (*inmemIterator)(nil)
+
+type inmemIterator struct {
+	pairs []kvPair
+	pos   int
+}
+
+func (i *inmemIterator) Next() bool {
+	if i.pos < len(i.pairs) {
+		i.pos++
+	}
+
+	return i.pos < len(i.pairs)
+}
+
+func (i *inmemIterator) Key() []byte {
+	return i.pairs[i.pos].key
+}
+
+func (i *inmemIterator) Value() []byte {
+	return i.pairs[i.pos].value
+}
+
+func (i *inmemIterator) Error() error {
+	return nil
+}
+
+func (i *inmemIterator) Release() {
+	i.pairs = nil
+	i.pos = 0
+}
+
+var _ Snapshot = (*inmemSnapshot)(nil)
+
+// inmemSnapshot holds a copy of all pairs of an in-memory store sorted by their keys.
+type inmemSnapshot struct {
+	pairs []kvPair
+}
+
+// search returns the index of the first pair whose key is not less than the given key.
+func (s *inmemSnapshot) search(key []byte) int {
+	return sort.Search(len(s.pairs), func(i int) bool {
+		return bytes.Compare(s.pairs[i].key, key) >= 0
+	})
+}
+
+func (s *inmemSnapshot) Get(key []byte) ([]byte, error) {
+	i := s.search(key)
+	if i == len(s.pairs) || !bytes.Equal(s.pairs[i].key, key) {
+		return nil, ErrNotFound
+	}
+
+	return append([]byte{}, s.pairs[i].value...), nil
+}
+
+func (s *inmemSnapshot) MultiGet(keys ...[]byte) ([][]byte, error) {
+	bufs := make([][]byte, 0, len(keys))
+
+	for _, key := range keys {
+		buf, err := s.Get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		bufs = append(bufs, buf)
+	}
+
+	return bufs, nil
+}
+
+func (s *inmemSnapshot) NewIterator(start, end []byte) Iterator {
+	lo, hi := 0, len(s.pairs)
+
+	if start != nil {
+		lo = s.search(start)
+	}
+
+	if end != nil {
+		hi = s.search(end)
+	}
+
+	if hi < lo {
+		hi = lo
+	}
+
+	return &inmemIterator{pairs: s.pairs[lo:hi], pos: -1}
+}
+
+func (s *inmemSnapshot) Release() {
+	s.pairs = nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package store
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func collect(t *testing.T, it Iterator) []string {
+	defer it.Release()
+
+	var pairs []string
+
+	for it.Next() {
+		pairs = append(pairs, string(it.Key())+"="+string(it.Value()))
+	}
+
+	assert.NoError(t, it.Error())
+
+	return pairs
+}
+
+func TestIterator(t *testing.T) {
+	for _, kind := range []string{"inmem", "level", "badger", "bbolt"} {
+		kind := kind
+
+		t.Run(kind, func(t *testing.T) {
+			db, cleanup, err := NewTestKV(kind, "iterator_"+kind)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			defer cleanup()
+
+			for _, key := range []string{"b2", "a1", "b1", "c1", "b\xff"} {
+				assert.NoError(t, db.Put([]byte(key), []byte("v"+key)))
+			}
+
+			assert.Equal(t, []string{"a1=va1", "b1=vb1", "b2=vb2", "b\xff=vb\xff", "c1=vc1"},
+				collect(t, db.NewIterator(nil, nil)))
+
+			assert.Equal(t, []string{"b1=vb1", "b2=vb2"}, collect(t, db.NewIterator([]byte("b"), []byte("b3"))))
+			assert.Equal(t, []string{"b\xff=vb\xff", "c1=vc1"}, collect(t, db.NewIterator([]byte("b3"), nil)))
+			assert.Empty(t, collect(t, db.NewIterator([]byte("d"), nil)))
+
+			assert.Equal(t, []string{"b1=vb1", "b2=vb2", "b\xff=vb\xff"},
+				collect(t, NewPrefixIterator(db, []byte("b"))))
+
+			snapshot, err := db.NewSnapshot()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			defer snapshot.Release()
+
+			// Writes made after taking a snapshot are not visible through it.
+			assert.NoError(t, db.Put([]byte("b3"), []byte("vb3")))
+			assert.NoError(t, db.Put([]byte("a1"), []byte("changed")))
+			assert.NoError(t, db.Delete([]byte("c1")))
+
+			v, err := snapshot.Get([]byte("a1"))
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("va1"), v)
+
+			_, err = snapshot.Get([]byte("b3"))
+			assert.EqualError(t, errors.Cause(err), ErrNotFound.Error())
+
+			values, err := snapshot.MultiGet([]byte("a1"), []byte("c1"))
+			assert.NoError(t, err)
+			assert.Equal(t, [][]byte{[]byte("va1"), []byte("vc1")}, values)
+
+			assert.Equal(t, []string{"a1=va1", "b1=vb1", "b2=vb2", "b\xff=vb\xff", "c1=vc1"},
+				collect(t, snapshot.NewIterator(nil, nil)))
+
+			assert.Equal(t, []string{"b1=vb1", "b2=vb2", "b\xff=vb\xff"},
+				collect(t, NewPrefixIterator(snapshot, []byte("b"))))
+
+			// Whereas they are visible through the store.
+			assert.Equal(t, []string{"a1=changed", "b1=vb1", "b2=vb2", "b3=vb3", "b\xff=vb\xff"},
+				collect(t, db.NewIterator(nil, nil)))
+		})
+	}
+}
+
+func TestPrefixRange(t *testing.T) {
+	start, end := PrefixRange([]byte{0x1, 0x2})
+	assert.Equal(t, []byte{0x1, 0x2}, start)
+	assert.Equal(t, []byte{0x1, 0x3}, end)
+
+	start, end = PrefixRange([]byte{0x1, 0xff})
+	assert.Equal(t, []byte{0x1, 0xff}, start)
+	assert.Equal(t, []byte{0x2}, end)
+
+	_, end = PrefixRange([]byte{0xff, 0xff})
+	assert.Nil(t, end)
+
+	start, end = PrefixRange(nil)
+	assert.Empty(t, start)
+	assert.Nil(t, end)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/filter"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 var _ WriteBatch = (*leveldbWriteBatch)(nil)
@@ -90,6 +91,19 @@ func (l *leveldbKV) MultiGet(keys ...[]byte) ([][]byte, error) {
 	return bufs, nil
 }
 
+func (l *leveldbKV) NewIterator(start, end []byte) Iterator {
+	return l.db.NewIterator(&util.Range{Start: start, Limit: end}, nil)
+}
+
+func (l *leveldbKV) NewSnapshot() (Snapshot, error) {
+	snapshot, err := l.db.GetSnapshot()
+	if err != nil {
+		return nil, errors.Wrap(err, "leveldb: failed to take snapshot")
+	}
+
+	return &leveldbSnapshot{snapshot: snapshot}, nil
+}
+
 func (l *leveldbKV) Dir() string {
 	return l.dir
 }
@@ -117,12 +131,26 @@ func (l *leveldbKV) Delete(key []byte) error {
 	return l.db.Delete(key, nil)
 }
 
-func NewLevelDB(dir string) (*leveldbKV, error) { // nolint:golint
+// LevelDBOption configures how a LevelDB database is opened.
+type LevelDBOption func(opts *opt.Options)
+
+// WithReadOnly opens a LevelDB database such that all writes to it fail.
+func WithReadOnly() LevelDBOption {
+	return func(opts *opt.Options) {
+		opts.ReadOnly = true
+	}
+}
+
+func NewLevelDB(dir string, options ...LevelDBOption) (*leveldbKV, error) { // nolint:golint
 	opts := &opt.Options{
 		Filter:       filter.NewBloomFilter(10),
 		NoWriteMerge: true,
 	}
 
+	for _, option := range options {
+		option(opts)
+	}
+
 	var (
 		db  *leveldb.DB
 		err error
@@ -145,3 +173,41 @@ func NewLevelDB(dir string) (*leveldbKV, error) { // nolint:golint
 		db:  db,
 	}, nil
 }
+
+var _ Snapshot = (*leveldbSnapshot)(nil)
+
+type leveldbSnapshot struct {
+	snapshot *leveldb.Snapshot
+}
+
+func (s *leveldbSnapshot) Get(key []byte) ([]byte, error) {
+	v, err := s.snapshot.Get(key, nil)
+	if err != nil {
+		return nil, errors.Wrap(ErrNotFound, err.Error())
+	}
+
+	return v, nil
+}
+
+func (s *leveldbSnapshot) MultiGet(keys ...[]byte) ([][]byte, error) {
+	var bufs = make([][]byte, len(keys))
+
+	for i := range keys {
+		b, err := s.Get(keys[i])
+		if err != nil {
+			return nil, err
+		}
+
+		bufs[i] = b
+	}
+
+	return bufs, nil
+}
+
+func (s *leveldbSnapshot) NewIterator(start, end []byte) Iterator {
+	return s.snapshot.NewIterator(&util.Range{Start: start, Limit: end}, nil)
+}
+
+func (s *leveldbSnapshot) Release() {
+	s.snapshot.Release()
+}
@@ -28,11 +28,24 @@ var (
 	ErrNotFound = errors.New("not found")
 )
 
+// Reader reads keys and iterates over ranges of keys.
+type Reader interface {
+	Get(key []byte) ([]byte, error)
+	MultiGet(keys ...[]byte) ([][]byte, error)
+
+	// NewIterator returns an iterator over all keys in the range [start, end) in ascending
+	// order. A nil start or end leaves the range unbounded on that side. The iterator must be
+	// released after use.
+	NewIterator(start, end []byte) Iterator
+}
+
 type KV interface {
 	io.Closer
+	Reader
 
-	Get(key []byte) ([]byte, error)
-	MultiGet(keys ...[]byte) ([][]byte, error)
+	// NewSnapshot returns a consistent read-only view of the store as of now, unaffected by
+	// any writes made afterwards. The snapshot must be released after use.
+	NewSnapshot() (Snapshot, error)
 
 	Put(key, value []byte) error
 
@@ -61,3 +74,49 @@ type WriteBatch interface {
 	Count() int
 	Destroy()
 }
+
+// Iterator iterates over key-value pairs in ascending order of keys. It starts positioned
+// before the first pair, such that Next must be called before accessing the first pair.
+//
+// The slices returned by Key and Value are only valid until the next call to Next.
+type Iterator interface {
+	Next() bool
+
+	Key() []byte
+	Value() []byte
+
+	// Error returns any error that stopped the iteration early.
+	Error() error
+
+	Release()
+}
+
+// Snapshot is a consistent read-only view of a store at a point in time.
+type Snapshot interface {
+	Reader
+
+	Release()
+}
+
+// PrefixRange returns the range of keys that starts with the given prefix, to be passed to
+// NewIterator.
+func PrefixRange(prefix []byte) (start, end []byte) {
+	start = append([]byte{}, prefix...)
+
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			end = append([]byte{}, prefix[:i+1]...)
+			end[i]++
+
+			return start, end
+		}
+	}
+
+	return start, nil
+}
+
+// NewPrefixIterator returns an iterator over all keys of a reader that start with the given
+// prefix.
+func NewPrefixIterator(r Reader, prefix []byte) Iterator {
+	return r.NewIterator(PrefixRange(prefix))
+}
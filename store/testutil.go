@@ -74,6 +74,25 @@ func NewTestKV(kv string, path string, opts ...TestKVOption) (KV, func(), error)
 		}
 
 		return badger, cleanup, nil
+	case "bbolt": // nolint:goconst
+		if cfg.RemoveExisting {
+			_ = os.RemoveAll(path)
+		}
+
+		bbolt, err := NewBbolt(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cleanup := func() {
+			_ = bbolt.Close()
+
+			if cfg.RemoveExisting {
+				_ = os.RemoveAll(path)
+			}
+		}
+
+		return bbolt, cleanup, nil
 	default:
 		return nil, nil, fmt.Errorf("unknown kv %s", kv)
 	}
@@ -3,45 +3,95 @@ package wavelet
 import (
 	"bytes"
 	"context"
-	"github.com/djherbis/buffer"
 	"github.com/perlin-network/noise/skademlia"
 	"github.com/perlin-network/wavelet/conf"
 	"github.com/perlin-network/wavelet/internal/backoff"
 	"github.com/perlin-network/wavelet/internal/filebuffer"
 	"github.com/perlin-network/wavelet/log"
-	"github.com/perlin-network/wavelet/sys"
+	"github.com/perlin-network/wavelet/store"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"go.uber.org/atomic"
 	"golang.org/x/crypto/blake2b"
-	"io"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 )
 
+var errBadSyncChunk = errors.New("bad sync chunk")
+
+// SyncProgress describes how far along our node is in downloading the latest state from its peers.
+type SyncProgress struct {
+	// Height of the block whose state is being downloaded.
+	Height uint64
+
+	ChunksDone  int
+	ChunksTotal int
+	BytesDone   uint64
+
+	// Number of chunks which were downloaded before our node restarted, and thus were loaded from
+	// disk rather than downloaded again.
+	ChunksResumed int
+
+	Started time.Time
+}
+
+// Syncing returns true if state is currently being downloaded.
+func (p SyncProgress) Syncing() bool {
+	return p.ChunksTotal > 0
+}
+
+// ETA estimates how long it will take for all remaining chunks to be downloaded given the rate
+// at which chunks have been downloaded so far. It returns zero if no chunks have been downloaded.
+func (p SyncProgress) ETA(now time.Time) time.Duration {
+	downloaded := p.ChunksDone - p.ChunksResumed
+	if downloaded <= 0 {
+		return 0
+	}
+
+	elapsed := now.Sub(p.Started)
+
+	return time.Duration(float64(elapsed) / float64(downloaded) * float64(p.ChunksTotal-p.ChunksDone))
+}
+
 type SyncManager struct {
-	client   *skademlia.Client
-	accounts *Accounts
-	blocks   *Blocks
+	client     *skademlia.Client
+	kv         store.KV
+	accounts   *Accounts
+	blocks     *Blocks
+	sampler    PeerSampler
+	reputation *PeerReputation
 
 	filePool *filebuffer.Pool
 
+	progress         SyncProgress
+	progressLock     sync.RWMutex
+	progressReported time.Time
+
 	logger zerolog.Logger
 	exit   chan struct{}
 	exited atomic.Bool
 
 	OnStateReconciled []func(outOfSync bool)
 	OnSynced          []func(block Block)
+
+	// ReplayBlocks, if set, is attempted before syncing state. It should catch our node up by
+	// replaying the blocks it has missed, and return false should state have to be synced instead.
+	ReplayBlocks func() bool
 }
 
 func NewSyncManager(
-	client *skademlia.Client, accounts *Accounts, blocks *Blocks, filePool *filebuffer.Pool,
+	client *skademlia.Client, kv store.KV, accounts *Accounts, blocks *Blocks,
+	sampler PeerSampler, reputation *PeerReputation, filePool *filebuffer.Pool,
 ) *SyncManager {
 	return &SyncManager{
-		client:   client,
-		accounts: accounts,
-		blocks:   blocks,
+		client:     client,
+		kv:         kv,
+		accounts:   accounts,
+		blocks:     blocks,
+		sampler:    sampler,
+		reputation: reputation,
 
 		filePool: filePool,
 
@@ -50,6 +100,14 @@ func NewSyncManager(
 	}
 }
 
+// Progress returns how far along our node is in downloading the latest state from its peers.
+func (s *SyncManager) Progress() SyncProgress {
+	s.progressLock.RLock()
+	defer s.progressLock.RUnlock()
+
+	return s.progress
+}
+
 func (s *SyncManager) Stop() {
 	s.exited.Store(true)
 	close(s.exit)
@@ -87,6 +145,10 @@ func (s *SyncManager) Start() {
 			break
 		}
 
+		if s.ReplayBlocks != nil && s.ReplayBlocks() {
+			continue
+		}
+
 		var (
 			block Block
 			err   error
@@ -120,7 +182,7 @@ func (s *SyncManager) sync(b *backoff.Backoff) (Block, error) {
 
 		block     Block
 		checksums [][blake2b.Size256]byte
-		streams   []Wavelet_SyncClient
+		sessions  []syncPeer
 
 		err error
 	)
@@ -137,7 +199,7 @@ func (s *SyncManager) sync(b *backoff.Backoff) (Block, error) {
 			continue
 		}
 
-		block, checksums, streams, err = s.collateLatestStateDetails(peers)
+		block, checksums, sessions, err = s.collateLatestStateDetails(peers)
 		if err != nil {
 			s.logger.Warn().Err(err).Msg("Got an error while collating the latest state details from our peers")
 			s.wait(b.Duration())
@@ -154,37 +216,45 @@ func (s *SyncManager) sync(b *backoff.Backoff) (Block, error) {
 
 	b.Reset()
 
-	chunksBuffer, err := s.filePool.GetBounded(int64(len(checksums)) * sys.SyncChunkSize)
+	pending, err := s.resumeFromCheckpoint(block, checksums)
 	if err != nil {
 		return block, err
 	}
 
-	diffBuffer := s.filePool.GetUnbounded()
-
-	defer func() {
-		s.filePool.Put(chunksBuffer)
-		s.filePool.Put(diffBuffer)
-	}()
-
-	err = nil
+	defer s.resetProgress()
 
-	// TODO(kenta): make number of attempts to download chunked state configurable
-	for i := 0; i < 3; i++ {
-		if err = s.downloadStateInChunks(checksums, streams, chunksBuffer, diffBuffer); err != nil {
+	for i := 0; i < conf.GetSyncDownloadAttempts() && len(pending) > 0; i++ {
+		if i > 0 {
 			s.wait(b.Duration())
 
 			if s.closed() {
 				return Block{}, nil
 			}
-
-			continue
 		}
 
-		break
+		pending = s.downloadStateInChunks(checksums, pending, sessions)
 	}
 
-	if err != nil {
-		return block, err
+	if len(pending) > 0 {
+		return block, errors.Errorf(
+			"only downloaded %d out of %d chunk(s) successfully",
+			len(checksums)-len(pending),
+			len(checksums),
+		)
+	}
+
+	diffBuffer := s.filePool.GetUnbounded()
+	defer s.filePool.Put(diffBuffer)
+
+	for _, checksum := range checksums {
+		chunk, err := LoadSyncChunk(s.kv, checksum)
+		if err != nil {
+			return block, err
+		}
+
+		if _, err := diffBuffer.Write(chunk); err != nil {
+			return block, err
+		}
 	}
 
 	b.Reset()
@@ -199,6 +269,10 @@ func (s *SyncManager) sync(b *backoff.Backoff) (Block, error) {
 		return block, errors.Errorf("got merkle root %x but expected %x", checksum, block.Merkle)
 	}
 
+	// Record the state under the height of the block we synced to, so that it may be looked
+	// up by height should we be archiving state.
+	snapshot.SetViewID(block.Index)
+
 	if _, err := s.blocks.Save(&block); err != nil {
 		return block, err
 	}
@@ -207,6 +281,10 @@ func (s *SyncManager) sync(b *backoff.Backoff) (Block, error) {
 		return block, err
 	}
 
+	if err := ClearSyncCheckpoint(s.kv); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to clear the checkpoint of our finished sync.")
+	}
+
 	s.logger.Info().
 		Int("num_chunks", len(checksums)).
 		Uint64("new_block_height", block.Index).
@@ -265,7 +343,7 @@ func (s *SyncManager) askIfWereOutOfSync(ctx context.Context, peer skademlia.Clo
 func (s *SyncManager) collectVotesFromPeers(sampler *Snowball, votes chan<- Vote, samplerK int) (bool, error) {
 	latestHeight := s.blocks.LatestHeight()
 
-	peers, err := SelectPeers(s.client.ClosestPeers(), samplerK)
+	peers, err := s.sampler.Sample(s.accounts.Snapshot(), s.reputation, s.client.ClosestPeers(), samplerK)
 	if err != nil {
 		s.logger.Warn().Msg("It looks like there are no peers for us to sync with. Retrying after 1 second...")
 		s.wait(1 * time.Second)
@@ -286,9 +364,12 @@ func (s *SyncManager) collectVotesFromPeers(sampler *Snowball, votes chan<- Vote
 
 			maybe, err := s.askIfWereOutOfSync(ctx, peer, latestHeight)
 			if err != nil {
+				s.reputation.Record(peer.ID().PublicKey(), ReputationUnresponsive)
 				return
 			}
 
+			s.reputation.Record(peer.ID().PublicKey(), ReputationResponded)
+
 			votes <- &syncVote{voter: peer.ID(), outOfSync: maybe}
 		}(p)
 	}
@@ -346,7 +427,7 @@ func (s *SyncManager) findPeersToDownloadStateFrom(numPeers int) ([]syncPeer, er
 	sessions := make([]syncPeer, 0, numPeers)
 	sessionsLock := sync.Mutex{}
 
-	peers, err := SelectPeers(s.client.ClosestPeers(), numPeers)
+	peers, err := s.sampler.Sample(s.accounts.Snapshot(), s.reputation, s.client.ClosestPeers(), numPeers)
 	if err != nil {
 		s.logger.Warn().
 			Msg("It looks like there are no peers for us to download state from. Retrying after 1 second...")
@@ -359,8 +440,10 @@ func (s *SyncManager) findPeersToDownloadStateFrom(numPeers int) ([]syncPeer, er
 		return nil, errors.New("no peers for us to sync with")
 	}
 
+	// Tell our peers which state we are at, so that they only send us what has changed since.
 	height := s.blocks.LatestHeight()
-	req := &SyncRequest{Data: &SyncRequest_BlockId{BlockId: height}}
+	root := s.accounts.Snapshot().Checksum()
+	req := &SyncRequest{Data: &SyncRequest_View{View: &SyncView{ViewId: height, MerkleRoot: root[:]}}}
 
 	var wg sync.WaitGroup
 
@@ -372,6 +455,7 @@ func (s *SyncManager) findPeersToDownloadStateFrom(numPeers int) ([]syncPeer, er
 
 			stream, err := NewWaveletClient(peer.Conn()).Sync(context.Background())
 			if err != nil {
+				s.reputation.Record(peer.ID().PublicKey(), ReputationUnresponsive)
 				return
 			}
 
@@ -451,12 +535,12 @@ func (s *SyncManager) askForLatestStateDetails(
 }
 
 func (s *SyncManager) collateLatestStateDetails(peers []syncPeer) (
-	block Block, checksums [][blake2b.Size256]byte, streams []Wavelet_SyncClient, err error,
+	block Block, checksums [][blake2b.Size256]byte, sessions []syncPeer, err error,
 ) {
 	var max []byte
 
 	counts := make(map[string]int)
-	clients := make(map[string][]Wavelet_SyncClient)
+	clients := make(map[string][]syncPeer)
 
 	for _, peer := range peers {
 		key := peer.block.ID[:]
@@ -464,7 +548,7 @@ func (s *SyncManager) collateLatestStateDetails(peers []syncPeer) (
 			key = append(key, checksum[:]...)
 		}
 
-		clients[string(key)] = append(clients[string(key)], peer.stream)
+		clients[string(key)] = append(clients[string(key)], peer)
 		counts[string(key)]++
 
 		if max == nil || counts[string(key)] > counts[string(max)] {
@@ -480,7 +564,7 @@ func (s *SyncManager) collateLatestStateDetails(peers []syncPeer) (
 	}
 
 	if counts[string(key)] < 2*len(peers)/3 {
-		return block, checksums, streams, errors.Errorf(
+		return block, checksums, sessions, errors.Errorf(
 			"majority of peers are not on the same state: got %d peers on the current state, but need a minimum of %d peers",
 			counts[string(key)],
 			2*len(peers)/3,
@@ -490,79 +574,211 @@ func (s *SyncManager) collateLatestStateDetails(peers []syncPeer) (
 	return block, checksums, clients[string(key)], nil
 }
 
+// resumeFromCheckpoint records that we are syncing to the state of a block, and returns the indices
+// of all chunks of the state which have yet to be downloaded. Chunks which were downloaded before
+// our node restarted are kept, while chunks of a sync towards some other state or from some other
+// base state are deleted.
+func (s *SyncManager) resumeFromCheckpoint(block Block, checksums [][blake2b.Size256]byte) ([]int, error) {
+	_, prevChecksums, err := LoadSyncCheckpoint(s.kv)
+	if err != nil && errors.Cause(err) != store.ErrNotFound {
+		s.logger.Warn().Err(err).Msg("Discarding the checkpoint of a prior sync.")
+	}
+
+	if err == nil {
+		wanted := make(map[[blake2b.Size256]byte]struct{}, len(checksums))
+		for _, checksum := range checksums {
+			wanted[checksum] = struct{}{}
+		}
+
+		for _, checksum := range prevChecksums {
+			if _, exists := wanted[checksum]; !exists {
+				_ = s.kv.Delete(append(keySyncChunks[:], checksum[:]...))
+			}
+		}
+	}
+
+	if err := StoreSyncCheckpoint(s.kv, block, checksums); err != nil {
+		return nil, err
+	}
+
+	progress := SyncProgress{Height: block.Index, ChunksTotal: len(checksums), Started: time.Now()}
+	pending := make([]int, 0, len(checksums))
+
+	for i, checksum := range checksums {
+		chunk, err := LoadSyncChunk(s.kv, checksum)
+		if err != nil {
+			pending = append(pending, i)
+			continue
+		}
+
+		progress.ChunksDone++
+		progress.ChunksResumed++
+		progress.BytesDone += uint64(len(chunk))
+	}
+
+	s.progressLock.Lock()
+	s.progress = progress
+	s.progressLock.Unlock()
+
+	if progress.ChunksResumed > 0 {
+		s.logger.Info().
+			Int("num_chunks_resumed", progress.ChunksResumed).
+			Int("num_chunks", progress.ChunksTotal).
+			Uint64("block_height", block.Index).
+			Msg("Resuming a sync from chunks of state that were downloaded before.")
+	}
+
+	s.reportProgress(true)
+
+	return pending, nil
+}
+
+// downloadStateInChunks downloads and persists the chunks of state at the given indices, and returns
+// the indices of all chunks which could not be downloaded.
 func (s *SyncManager) downloadStateInChunks(
-	checksums [][blake2b.Size256]byte,
-	streams []Wavelet_SyncClient,
-	chunksBuffer buffer.BufferAt,
-	diffBuffer io.Writer,
-) error {
+	checksums [][blake2b.Size256]byte, pending []int, sessions []syncPeer,
+) []int {
 	mutices := make(map[Wavelet_SyncClient]*sync.Mutex)
 
+	// Peers which served a bad chunk are not downloaded from for the rest of the sync.
+	corrupt := make(map[Wavelet_SyncClient]struct{})
+
 	var (
 		muticesLock sync.Mutex
-		chunkLock   sync.Mutex
 
-		downloadedCount atomic.Uint32
-		downloadedSize  atomic.Uint32
+		failed     []int
+		failedLock sync.Mutex
 	)
 
 	s.logger.Debug().
-		Int("num_chunks", len(checksums)).
+		Int("num_chunks", len(pending)).
 		Msg("Starting up workers to downloaded all chunks of data needed to sync to the latest block...")
 
-	var wg sync.WaitGroup
+	download := func(i int) bool {
+		checksum := checksums[i]
 
-	wg.Add(len(checksums))
+		for range sessions {
+			session := sessions[rand.Intn(len(sessions))]
+			stream := session.stream
+			id := session.peer.ID().PublicKey()
 
-	for i, checksum := range checksums {
-		go func(i int, checksum [blake2b.Size256]byte) {
-			defer wg.Done()
-
-			for range streams {
-				stream := streams[rand.Intn(len(streams))]
-
-				muticesLock.Lock()
-				if _, exists := mutices[stream]; !exists {
-					mutices[stream] = &sync.Mutex{}
-				}
-				mutex := mutices[stream]
+			muticesLock.Lock()
+			if _, exists := corrupt[stream]; exists {
 				muticesLock.Unlock()
+				continue
+			}
 
-				mutex.Lock()
-				chunk, err := s.downloadStateChunk(checksum, stream)
-				if err != nil {
-					mutex.Unlock()
-					continue
-				}
+			if _, exists := mutices[stream]; !exists {
+				mutices[stream] = &sync.Mutex{}
+			}
+			mutex := mutices[stream]
+			muticesLock.Unlock()
+
+			mutex.Lock()
+			chunk, err := s.downloadStateChunk(checksum, stream)
+			if err != nil {
 				mutex.Unlock()
 
-				chunkLock.Lock()
-				_, err = chunksBuffer.WriteAt(chunk, int64(i)*sys.SyncChunkSize)
-				chunkLock.Unlock()
+				if errors.Cause(err) == errBadSyncChunk {
+					muticesLock.Lock()
+					corrupt[stream] = struct{}{}
+					muticesLock.Unlock()
 
-				if err != nil {
-					continue
+					s.reputation.Record(id, ReputationBadChunk)
+				} else {
+					s.reputation.Record(id, ReputationUnresponsive)
 				}
 
-				downloadedCount.Add(1)
-				downloadedSize.Add(uint32(len(chunk)))
+				continue
+			}
+			mutex.Unlock()
 
-				break
+			if err := StoreSyncChunk(s.kv, checksum, chunk); err != nil {
+				s.logger.Warn().Err(err).Msg("Failed to persist a chunk of state downloaded from a peer.")
+				return false
 			}
-		}(i, checksum)
+
+			s.progressLock.Lock()
+			s.progress.ChunksDone++
+			s.progress.BytesDone += uint64(len(chunk))
+			s.progressLock.Unlock()
+
+			s.reportProgress(false)
+
+			return true
+		}
+
+		return false
 	}
 
-	wg.Wait()
+	workers := conf.GetSyncChunkConcurrency()
+	if workers < 1 {
+		workers = 1
+	}
+
+	indices := make(chan int, len(pending))
+	for _, i := range pending {
+		indices <- i
+	}
 
-	if d := downloadedCount.Load(); int(d) < len(checksums) {
-		return errors.Errorf("only downloaded %d out of %d chunk(s) successfully", d, len(checksums))
+	close(indices)
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				if !download(i) {
+					failedLock.Lock()
+					failed = append(failed, i)
+					failedLock.Unlock()
+				}
+			}
+		}()
 	}
 
-	if _, err := io.CopyN(diffBuffer, chunksBuffer, int64(downloadedSize.Load())); err != nil {
-		return err
+	wg.Wait()
+
+	sort.Ints(failed)
+
+	return failed
+}
+
+// reportProgress logs our sync progress to the network logger at most once every second, unless
+// forced to.
+func (s *SyncManager) reportProgress(force bool) {
+	now := time.Now()
+
+	s.progressLock.Lock()
+
+	if !force && now.Sub(s.progressReported) < 1*time.Second && s.progress.ChunksDone < s.progress.ChunksTotal {
+		s.progressLock.Unlock()
+		return
 	}
 
-	return nil
+	s.progressReported = now
+	progress := s.progress
+
+	s.progressLock.Unlock()
+
+	logger := log.Network("sync")
+	logger.Info().
+		Uint64("block_height", progress.Height).
+		Int("chunks_done", progress.ChunksDone).
+		Int("chunks_total", progress.ChunksTotal).
+		Uint64("bytes_done", progress.BytesDone).
+		Dur("eta", progress.ETA(now)).
+		Msg("Downloading the latest state from our peers.")
+}
+
+func (s *SyncManager) resetProgress() {
+	s.progressLock.Lock()
+	s.progress = SyncProgress{}
+	s.progressLock.Unlock()
 }
 
 func (s *SyncManager) downloadStateChunk(checksum [blake2b.Size256]byte, stream Wavelet_SyncClient) ([]byte, error) {
@@ -579,11 +795,12 @@ func (s *SyncManager) downloadStateChunk(checksum [blake2b.Size256]byte, stream
 
 	chunk := res.GetChunk()
 	if chunk == nil {
-		return nil, errors.New("peer did not send chunk")
+		return nil, errors.Wrap(errBadSyncChunk, "peer did not send chunk")
 	}
 
 	if len(chunk) > conf.GetSyncChunkSize() {
-		return nil, errors.Errorf(
+		return nil, errors.Wrapf(
+			errBadSyncChunk,
 			"got chunk of size %d but chunk can be no larger than %d bytes",
 			len(chunk),
 			conf.GetSyncChunkSize(),
@@ -593,7 +810,8 @@ func (s *SyncManager) downloadStateChunk(checksum [blake2b.Size256]byte, stream
 	recovered := blake2b.Sum256(chunk)
 
 	if recovered != checksum {
-		return nil, errors.Errorf(
+		return nil, errors.Wrapf(
+			errBadSyncChunk,
 			"chunk downloaded was hashed to %x, but was trying to download chunk with a has of %x",
 			recovered,
 			checksum,
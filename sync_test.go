@@ -0,0 +1,120 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build unit
+
+package wavelet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/perlin-network/wavelet/log"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestSyncManager_ResumeFromCheckpoint(t *testing.T) {
+	kv := store.NewInmem()
+	s := &SyncManager{kv: kv, logger: log.Sync("sync")}
+
+	chunks := [][]byte{[]byte("a"), []byte("bb"), []byte("ccc")}
+	checksums := make([][blake2b.Size256]byte, len(chunks))
+
+	for i, chunk := range chunks {
+		checksums[i] = blake2b.Sum256(chunk)
+	}
+
+	// Our node restarted having downloaded the first two chunks of some state.
+
+	prev := NewBlock(1, MerkleNodeID{1})
+	assert.NoError(t, StoreSyncCheckpoint(kv, prev, checksums[:2]))
+	assert.NoError(t, StoreSyncChunk(kv, checksums[0], chunks[0]))
+	assert.NoError(t, StoreSyncChunk(kv, checksums[1], chunks[1]))
+
+	// The network has since moved on to some state sharing only the second chunk.
+
+	block := NewBlock(2, MerkleNodeID{2})
+
+	pending, err := s.resumeFromCheckpoint(block, checksums[1:])
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, pending)
+
+	progress := s.Progress()
+	assert.True(t, progress.Syncing())
+	assert.EqualValues(t, 2, progress.Height)
+	assert.Equal(t, 1, progress.ChunksDone)
+	assert.Equal(t, 1, progress.ChunksResumed)
+	assert.Equal(t, 2, progress.ChunksTotal)
+	assert.EqualValues(t, len(chunks[1]), progress.BytesDone)
+
+	_, err = LoadSyncChunk(kv, checksums[0])
+	assert.Error(t, err)
+
+	_, loaded, err := LoadSyncCheckpoint(kv)
+	assert.NoError(t, err)
+	assert.Equal(t, checksums[1:], loaded)
+
+	s.resetProgress()
+	assert.False(t, s.Progress().Syncing())
+}
+
+func TestSyncProgress_ETA(t *testing.T) {
+	now := time.Now()
+
+	progress := SyncProgress{ChunksTotal: 10, ChunksDone: 4, ChunksResumed: 2, Started: now.Add(-2 * time.Second)}
+	assert.Equal(t, 6*time.Second, progress.ETA(now))
+
+	progress.ChunksDone = 2
+	assert.Equal(t, time.Duration(0), progress.ETA(now))
+}
+
+func TestSyncDiffBase(t *testing.T) {
+	// A fresh store has no latest block to load, which is of no concern here.
+	blocks, _ := NewBlocks(store.NewInmem(), 10)
+
+	var root MerkleNodeID
+	copy(root[:], "state")
+
+	block := NewBlock(42, root)
+
+	_, err := blocks.Save(&block)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	p := &Protocol{ledger: &Ledger{blocks: blocks}}
+
+	view := func(id uint64, root []byte) *SyncRequest {
+		return &SyncRequest{Data: &SyncRequest_View{View: &SyncView{ViewId: id, MerkleRoot: root}}}
+	}
+
+	// A peer at a state we have finalized before is only sent the changes made since.
+	assert.EqualValues(t, 42, p.syncDiffBase(view(42, root[:])))
+
+	// A peer at a state we do not have, or at a view we know nothing about, is sent the full state.
+	assert.EqualValues(t, 0, p.syncDiffBase(view(42, make([]byte, len(root)))))
+	assert.EqualValues(t, 0, p.syncDiffBase(view(43, root[:])))
+	assert.EqualValues(t, 0, p.syncDiffBase(view(0, root[:])))
+
+	// Peers that only send the ID of the block they are at have it honoured.
+	assert.EqualValues(t, 42, p.syncDiffBase(&SyncRequest{Data: &SyncRequest_BlockId{BlockId: 42}}))
+	assert.EqualValues(t, 0, p.syncDiffBase(&SyncRequest{}))
+}
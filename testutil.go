@@ -22,6 +22,7 @@ import (
 	"github.com/perlin-network/noise/edwards25519"
 	"github.com/perlin-network/noise/handshake"
 	"github.com/perlin-network/noise/skademlia"
+	"github.com/perlin-network/wavelet/conf"
 	"github.com/perlin-network/wavelet/store"
 	"github.com/perlin-network/wavelet/sys"
 	"google.golang.org/grpc"
@@ -34,10 +35,15 @@ var (
 type TestNetwork struct {
 	faucet *TestLedger
 	nodes  map[AccountID]*TestLedger
+	sim    *SimNetwork
+
+	replayLimit *uint64
 }
 
 type TestNetworkConfig struct {
-	AddFaucet bool
+	AddFaucet     bool
+	Sim           *SimNetwork
+	DisableReplay bool
 }
 
 func defaultTestNetworkConfig() TestNetworkConfig {
@@ -48,16 +54,49 @@ func defaultTestNetworkConfig() TestNetworkConfig {
 
 type TestNetworkOption func(cfg *TestNetworkConfig)
 
-func NewTestNetwork(opts ...TestNetworkOption) (*TestNetwork, error) {
-	n := &TestNetwork{
-		nodes: map[AccountID]*TestLedger{},
+// WithoutFaucet spawns the test network without any nodes. A faucet may be added
+// afterwards through SetFaucet.
+func WithoutFaucet() TestNetworkOption {
+	return func(cfg *TestNetworkConfig) {
+		cfg.AddFaucet = false
+	}
+}
+
+// WithSimNetwork has all nodes of the test network communicate through a simulated network
+// rather than over TCP, and store their state in memory.
+func WithSimNetwork(sim *SimNetwork) TestNetworkOption {
+	return func(cfg *TestNetworkConfig) {
+		cfg.Sim = sim
 	}
+}
 
+// WithoutReplay has nodes which fall behind catch up by syncing the latest state, rather than by
+// replaying the blocks they missed, so that tests may exercise state syncing. The replay limit is
+// restored once the test network is cleaned up.
+func WithoutReplay() TestNetworkOption {
+	return func(cfg *TestNetworkConfig) {
+		cfg.DisableReplay = true
+	}
+}
+
+func NewTestNetwork(opts ...TestNetworkOption) (*TestNetwork, error) {
 	cfg := defaultTestNetworkConfig()
 	for _, opt := range opts {
 		opt(&cfg)
 	}
 
+	n := &TestNetwork{
+		nodes: map[AccountID]*TestLedger{},
+		sim:   cfg.Sim,
+	}
+
+	if cfg.DisableReplay {
+		limit := conf.GetSyncReplayLimit()
+		n.replayLimit = &limit
+
+		conf.Update(conf.WithSyncReplayLimit(0))
+	}
+
 	var err error
 	if cfg.AddFaucet {
 		n.faucet, err = n.AddNode(WithWallet(FaucetWallet), WithRemoveExistingDB(true))
@@ -73,12 +112,20 @@ func (n *TestNetwork) Cleanup() {
 	for _, node := range n.nodes {
 		node.Cleanup(true)
 	}
+
+	if n.replayLimit != nil {
+		conf.Update(conf.WithSyncReplayLimit(*n.replayLimit))
+	}
 }
 
 func (n *TestNetwork) Faucet() *TestLedger {
 	return n.faucet
 }
 
+func (n *TestNetwork) Sim() *SimNetwork {
+	return n.sim
+}
+
 func (n *TestNetwork) SetFaucet(node *TestLedger) {
 	n.faucet = node
 }
@@ -103,6 +150,38 @@ func WithDBPath(path string) TestLedgerOption {
 	}
 }
 
+func WithSampler(sampler PeerSampler) TestLedgerOption {
+	return func(cfg *TestLedgerConfig) {
+		cfg.Sampler = sampler
+	}
+}
+
+// WithByzantine has the node misbehave towards its peers in the given ways.
+func WithByzantine(behavior ByzantineBehavior) TestLedgerOption {
+	return func(cfg *TestLedgerConfig) {
+		cfg.Byzantine = behavior
+	}
+}
+
+// WithPeers has the node bootstrap off of the first of the given peers that may be dialed,
+// rather than off of the faucet.
+func WithPeers(peers ...*TestLedger) TestLedgerOption {
+	return func(cfg *TestLedgerConfig) {
+		cfg.Peers = cfg.Peers[:0]
+
+		for _, peer := range peers {
+			cfg.Peers = append(cfg.Peers, peer.Addr())
+		}
+	}
+}
+
+// WithArchival has the node persist every finalized block and transaction.
+func WithArchival() TestLedgerOption {
+	return func(cfg *TestLedgerConfig) {
+		cfg.Archive = true
+	}
+}
+
 func (n *TestNetwork) AddNode(opts ...TestLedgerOption) (*TestLedger, error) {
 	var peers []string
 
@@ -113,6 +192,7 @@ func (n *TestNetwork) AddNode(opts ...TestLedgerOption) (*TestLedger, error) {
 	cfg := TestLedgerConfig{
 		Peers: peers,
 		N:     len(n.nodes),
+		Sim:   n.sim,
 	}
 
 	for _, opt := range opts {
@@ -224,6 +304,7 @@ type TestLedger struct {
 	kv        store.KV
 	kvCleanup func()
 	stopped   chan struct{}
+	byzantine *ByzantineProtocol
 
 	synced atomic2.Bool
 }
@@ -234,46 +315,101 @@ type TestLedgerConfig struct {
 	N                int
 	RemoveExistingDB bool
 	DBPath           string
+	Sampler          PeerSampler
+	Sim              *SimNetwork
+	Byzantine        ByzantineBehavior
+	Archive          bool
 }
 
 func NewTestLedger(cfg TestLedgerConfig) (*TestLedger, error) {
-	keys, err := loadKeys(cfg.Wallet)
-	if err != nil {
-		return nil, err
+	var (
+		keys *skademlia.Keypair
+		err  error
+	)
+
+	if cfg.Sim != nil && cfg.Wallet == "" {
+		keys, err = cfg.Sim.newKeys()
+	} else {
+		keys, err = loadKeys(cfg.Wallet)
 	}
 
-	ln, err := net.Listen("tcp", ":0") // nolint:gosec
 	if err != nil {
 		return nil, err
 	}
 
-	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+	var (
+		addr       string
+		ln         net.Listener
+		clientOpts = []skademlia.Option{skademlia.WithC1(sys.SKademliaC1), skademlia.WithC2(sys.SKademliaC2)}
+		kv         store.KV
+		cleanup    func()
+		path       string
+	)
 
-	client := skademlia.NewClient(addr, keys, skademlia.WithC1(sys.SKademliaC1), skademlia.WithC2(sys.SKademliaC2))
-	client.SetCredentials(noise.NewCredentials(addr, handshake.NewECDH(), cipher.NewAEAD(), client.Protocol()))
+	if cfg.Sim != nil {
+		addr, ln = cfg.Sim.listen()
+		clientOpts = append(clientOpts, skademlia.WithDialOptions(cfg.Sim.dialOptions(addr)...))
+
+		kv, cleanup, err = store.NewTestKV("inmem", "")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ln, err = net.Listen("tcp", ":0") // nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+
+		addr = net.JoinHostPort("127.0.0.1", strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+
+		var kvOpts []store.TestKVOption
+		if !cfg.RemoveExistingDB {
+			kvOpts = append(kvOpts, store.WithKeepExisting())
+		}
 
-	var kvOpts []store.TestKVOption
-	if !cfg.RemoveExistingDB {
-		kvOpts = append(kvOpts, store.WithKeepExisting())
+		path = fmt.Sprintf("db_%d", cfg.N)
+		if cfg.DBPath != "" {
+			path = cfg.DBPath
+		}
+
+		kv, cleanup, err = store.NewTestKV("level", path, kvOpts...)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	path := fmt.Sprintf("db_%d", cfg.N)
-	if cfg.DBPath != "" {
-		path = cfg.DBPath
+	client := skademlia.NewClient(addr, keys, clientOpts...)
+	client.SetCredentials(noise.NewCredentials(addr, handshake.NewECDH(), cipher.NewAEAD(), client.Protocol()))
+
+	opts := []Option{WithoutGC()}
+	if cfg.Sampler != nil {
+		opts = append(opts, WithPeerSampler(cfg.Sampler))
 	}
 
-	kv, cleanup, err := store.NewTestKV("level", path, kvOpts...)
-	if err != nil {
-		return nil, err
+	if cfg.Archive {
+		opts = append(opts, WithArchive())
 	}
 
-	ledger, err := NewLedger(kv, client, WithoutGC())
+	ledger, err := NewLedger(kv, client, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	var byzantine *ByzantineProtocol
+
 	server := client.Listen()
-	RegisterWaveletServer(server, ledger.Protocol())
+
+	if cfg.Byzantine != 0 {
+		seed := int64(cfg.N)
+		if cfg.Sim != nil {
+			seed += cfg.Sim.Seed()
+		}
+
+		byzantine = NewByzantineProtocol(ledger.Protocol(), cfg.Byzantine, seed)
+		RegisterWaveletServer(server, byzantine)
+	} else {
+		RegisterWaveletServer(server, ledger.Protocol())
+	}
 
 	stopped := make(chan struct{})
 	go func() {
@@ -283,8 +419,15 @@ func NewTestLedger(cfg TestLedgerConfig) (*TestLedger, error) {
 		}
 	}()
 
-	for _, addr := range cfg.Peers {
-		if _, err := client.Dial(addr); err != nil {
+	// Peers may turn the node away should their routing tables be full, so the node is
+	// bootstrapped off of the first peer that accepts it.
+	for i, addr := range cfg.Peers {
+		_, err := client.Dial(addr)
+		if err == nil {
+			break
+		}
+
+		if i == len(cfg.Peers)-1 {
 			return nil, err
 		}
 	}
@@ -300,6 +443,7 @@ func NewTestLedger(cfg TestLedgerConfig) (*TestLedger, error) {
 		kv:        kv,
 		kvCleanup: cleanup,
 		stopped:   stopped,
+		byzantine: byzantine,
 	}
 
 	tl.ledger.syncManager.OnStateReconciled = append(
@@ -339,6 +483,12 @@ func (l *TestLedger) Ledger() *Ledger {
 	return l.ledger
 }
 
+// Byzantine returns the protocol the node misbehaves towards its peers with, or nil
+// should the node be honest.
+func (l *TestLedger) Byzantine() *ByzantineProtocol {
+	return l.byzantine
+}
+
 func (l *TestLedger) Client() *skademlia.Client {
 	return l.client
 }
@@ -8,12 +8,22 @@ import (
 	"github.com/pkg/errors"
 )
 
+// TransactionReceipt is the outcome of a transaction which was finalized in a block.
+type TransactionReceipt struct {
+	// Height is the height of the block the transaction was finalized in.
+	Height uint64
+
+	// Err is the reason the transaction was rejected, or nil should it have been applied.
+	Err error
+}
+
 type Transactions struct {
 	sync.RWMutex
 
 	buffer    map[TransactionID]*Transaction
 	missing   map[TransactionID]uint64
 	finalized map[TransactionID]struct{}
+	receipts  map[TransactionID]TransactionReceipt
 	index     btree.BTree
 
 	latest Block // The latest block height the node is aware of.
@@ -24,6 +34,7 @@ func NewTransactions(latest Block) *Transactions {
 		buffer:    make(map[TransactionID]*Transaction),
 		missing:   make(map[TransactionID]uint64),
 		finalized: make(map[TransactionID]struct{}),
+		receipts:  make(map[TransactionID]TransactionReceipt),
 
 		latest: latest,
 	}
@@ -97,6 +108,32 @@ func (t *Transactions) BatchMarkFinalized(ids ...TransactionID) {
 	}
 }
 
+// BatchStoreReceipts records the outcomes of the transactions applied and rejected in a block
+// finalized at the given height, until the transactions are pruned.
+func (t *Transactions) BatchStoreReceipts(height uint64, applied, rejected []*Transaction, errs []error) {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, tx := range applied {
+		t.receipts[tx.ID] = TransactionReceipt{Height: height}
+	}
+
+	for i, tx := range rejected {
+		t.receipts[tx.ID] = TransactionReceipt{Height: height, Err: errs[i]}
+	}
+}
+
+// Receipt returns the outcome of a finalized transaction, should the node still hold it. Only
+// the outcomes of transactions finalized since the node was started are recorded.
+func (t *Transactions) Receipt(id TransactionID) (TransactionReceipt, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	receipt, exists := t.receipts[id]
+
+	return receipt, exists
+}
+
 // BatchMarkMissing is the same as MarkMissing, but it accepts a list of transaction IDs.
 // It returns false if at least 1 transaction ID is found missing.
 func (t *Transactions) BatchMarkMissing(ids ...TransactionID) bool {
@@ -172,6 +209,7 @@ func (t *Transactions) ReshufflePending(next Block) []TransactionID {
 		if next.Index >= tx.Block+uint64(conf.GetPruningLimit()) {
 			delete(t.buffer, tx.ID)
 			delete(t.finalized, tx.ID)
+			delete(t.receipts, tx.ID)
 
 			pruned = append(pruned, tx.ID)
 		}
@@ -370,3 +370,37 @@ func TestTransactionsPruneOnReshuffle(t *testing.T) { // nolint:gocognit
 
 	assert.NoError(t, quick.Check(fn, nil))
 }
+
+func TestTransactionsReceipts(t *testing.T) {
+	keys, err := skademlia.NewKeys(1, 1)
+	assert.NoError(t, err)
+
+	manager := NewTransactions(Block{Index: 0})
+
+	applied := NewTransaction(keys, 1, 0, sys.TagTransfer, nil)
+	rejected := NewTransaction(keys, 2, 0, sys.TagTransfer, nil)
+
+	manager.BatchAdd([]Transaction{applied, rejected})
+
+	_, exists := manager.Receipt(applied.ID)
+	assert.False(t, exists)
+
+	manager.BatchStoreReceipts(1, []*Transaction{&applied}, []*Transaction{&rejected}, []error{ErrTxInvalidSignature})
+
+	receipt, exists := manager.Receipt(applied.ID)
+	assert.True(t, exists)
+	assert.Equal(t, TransactionReceipt{Height: 1}, receipt)
+
+	receipt, exists = manager.Receipt(rejected.ID)
+	assert.True(t, exists)
+	assert.Equal(t, TransactionReceipt{Height: 1, Err: ErrTxInvalidSignature}, receipt)
+
+	// Receipts are pruned alongside their transactions.
+	manager.ReshufflePending(NewBlock(uint64(conf.GetPruningLimit()), ZeroMerkleNodeID))
+
+	_, exists = manager.Receipt(applied.ID)
+	assert.False(t, exists)
+
+	_, exists = manager.Receipt(rejected.ID)
+	assert.False(t, exists)
+}
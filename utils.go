@@ -20,7 +20,9 @@
 package wavelet
 
 import (
+	"math"
 	"math/rand"
+	"sort"
 
 	"github.com/perlin-network/noise/skademlia"
 
@@ -28,26 +30,54 @@ import (
 	"google.golang.org/grpc/connectivity"
 )
 
-func SelectPeers(peers []skademlia.ClosestPeer, amount int) ([]skademlia.ClosestPeer, error) {
+// SelectPeers randomly selects an amount of peers whose connections are ready to be used.
+// Given the reputation of peers, banned peers are never selected, and peers are selected
+// with a probability proportional to their reputation.
+func SelectPeers(
+	peers []skademlia.ClosestPeer, amount int, reputation *PeerReputation,
+) ([]skademlia.ClosestPeer, error) {
 	if len(peers) < amount {
 		return peers, errors.Errorf("only connected to %d peer(s), but require a minimum of %d peer(s)", len(peers), amount)
 	}
 
-	activePeers := make([]skademlia.ClosestPeer, 0, len(peers))
+	activePeers := reputation.Filter(filterActivePeers(peers))
 
-	for _, p := range peers {
-		if p.Conn().GetState() == connectivity.Ready {
-			activePeers = append(activePeers, p)
-		}
+	if len(activePeers) <= amount {
+		return activePeers, nil
 	}
 
-	if len(activePeers) > amount {
+	if reputation == nil {
 		rand.Shuffle(len(activePeers), func(i, j int) {
 			activePeers[i], activePeers[j] = activePeers[j], activePeers[i]
 		})
 
-		activePeers = activePeers[:amount]
+		return activePeers[:amount], nil
+	}
+
+	// Bias sampling away from peers with a low reputation by weighted random sampling
+	// without replacement (Efraimidis and Spirakis).
+	keys := make(map[AccountID]float64, len(activePeers))
+
+	for _, p := range activePeers {
+		keys[p.ID().PublicKey()] = math.Log(rand.Float64()) / reputation.Weight(p.ID().PublicKey())
+	}
+
+	sort.Slice(activePeers, func(i, j int) bool {
+		return keys[activePeers[i].ID().PublicKey()] > keys[activePeers[j].ID().PublicKey()]
+	})
+
+	return activePeers[:amount], nil
+}
+
+// filterActivePeers returns all peers whose connections are ready to be used.
+func filterActivePeers(peers []skademlia.ClosestPeer) []skademlia.ClosestPeer {
+	activePeers := make([]skademlia.ClosestPeer, 0, len(peers))
+
+	for _, p := range peers {
+		if p.Conn().GetState() == connectivity.Ready {
+			activePeers = append(activePeers, p)
+		}
 	}
 
-	return activePeers, nil
+	return activePeers
 }
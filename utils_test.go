@@ -46,7 +46,7 @@ func TestSelectPeers(t *testing.T) {
 
 	closest := nodes[0].ClosestPeers()
 
-	selected, err := SelectPeers(closest, 5)
+	selected, err := SelectPeers(closest, 5, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 5, len(selected))
 
@@ -76,13 +76,13 @@ func TestSelectPeers(t *testing.T) {
 	}
 
 	// SelectPeers should only return 4 peers
-	selected, err = SelectPeers(closest, 5)
+	selected, err = SelectPeers(closest, 5, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 4, len(selected))
 
 	// Calling ClosestPeers() should return 4 peers
 	closest = nodes[0].ClosestPeers()
-	selected, err = SelectPeers(closest, 5)
+	selected, err = SelectPeers(closest, 5, nil)
 	assert.EqualError(t, err, "only connected to 4 peer(s), but require a minimum of 5 peer(s)")
 	assert.Equal(t, 4, len(selected))
 }
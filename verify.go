@@ -0,0 +1,207 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"bytes"
+
+	"github.com/perlin-network/wavelet/avl"
+	"github.com/perlin-network/wavelet/conf"
+	"github.com/perlin-network/wavelet/store"
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/pkg/errors"
+)
+
+// Divergence describes the first finalized block whose merkle root could not be reproduced by
+// re-executing its transactions.
+type Divergence struct {
+	Block    Block
+	Expected MerkleNodeID
+	Actual   MerkleNodeID
+
+	// Keys of the state whose re-executed values differ from the values stored by the node. It is
+	// only populated should the node have retained the state at the height of the block.
+	Keys [][]byte
+
+	// Transaction is the first transaction in the block affecting an account whose state differs,
+	// if any.
+	Transaction *Transaction
+}
+
+// Verify re-executes the transactions of every finalized block stored in kv, and compares the
+// resulting merkle roots against the ones recorded in each block. Re-execution starts from the
+// genesis of the network should from be zero, or otherwise from the archived state at the given
+// height. It returns the height of the last block verified, and the first divergence found, if any.
+//
+// All state is re-executed in memory, such that kv is never written to. Blocks and transactions
+// older than the pruning limit are only available should the node have been running in archive
+// mode.
+func Verify(kv store.KV, genesis *string, from uint64) (uint64, *Divergence, error) {
+	blocks, err := NewBlocks(kv, conf.GetPruningLimit())
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to load blocks")
+	}
+
+	blocks.WithArchive(true)
+
+	latest := blocks.Latest()
+
+	if from > latest.Index {
+		return 0, nil, errors.Errorf("cannot verify from height %d past the latest block %d", from, latest.Index)
+	}
+
+	var (
+		current *Block
+		state   *avl.Tree
+	)
+
+	if from == 0 {
+		state = avl.New(store.NewInmem())
+
+		block := performInception(state, genesis)
+		current = &block
+
+		if stored, err := blocks.GetByIndex(0); err == nil && stored.ID != block.ID {
+			return 0, &Divergence{Block: *stored, Expected: stored.Merkle, Actual: block.Merkle}, nil
+		}
+	} else {
+		if current, err = blocks.GetByIndex(from); err != nil {
+			return 0, nil, errors.Wrapf(err, "block %d is neither held nor archived", from)
+		}
+
+		if state, err = NewAccounts(kv).WithArchive(true).SnapshotAt(from); err != nil {
+			return 0, nil, err
+		}
+
+		if checksum := state.Checksum(); checksum != current.Merkle {
+			return 0, nil, errors.Errorf(
+				"archived state at height %d does not match the merkle root of its block: expected %x but got %x",
+				from, current.Merkle, checksum,
+			)
+		}
+	}
+
+	for height := current.Index + 1; height <= latest.Index; height++ {
+		block, err := blocks.GetByIndex(height)
+		if err != nil {
+			return current.Index, nil, errors.Wrapf(err, "block %d is neither held nor archived", height)
+		}
+
+		txs := make([]*Transaction, 0, len(block.Transactions))
+
+		for _, id := range block.Transactions {
+			tx, err := LoadArchivedTransaction(kv, id)
+			if err != nil {
+				return current.Index, nil, errors.Wrapf(err, "transaction %x in block %d is not archived", id, height)
+			}
+
+			txs = append(txs, tx)
+		}
+
+		results, err := collapseTransactions(block.Index, txs, current, &Accounts{tree: state})
+		if err != nil {
+			return current.Index, nil, errors.Wrapf(err, "failed to re-execute block %d", height)
+		}
+
+		if checksum := results.snapshot.Checksum(); checksum != block.Merkle {
+			divergence := &Divergence{Block: *block, Expected: block.Merkle, Actual: checksum}
+
+			// Only the latest state is available should the node not have been running in archive mode.
+			stored := NewAccounts(kv).Snapshot()
+			if height != latest.Index {
+				stored, err = NewAccounts(kv).WithArchive(true).SnapshotAt(height)
+			}
+
+			if err == nil {
+				divergence.Keys = diffStateKeys(results.snapshot, stored, current.Index)
+				divergence.Transaction = findTransactionAffecting(txs, divergence.Keys)
+			}
+
+			return current.Index, divergence, nil
+		}
+
+		state = results.snapshot
+		current = block
+	}
+
+	return current.Index, nil, nil
+}
+
+// diffStateKeys returns the keys whose values differ between two states, considering only keys
+// which were modified in either state after the given view ID.
+func diffStateKeys(a, b *avl.Tree, prevViewID uint64) [][]byte {
+	seen := make(map[string]struct{})
+
+	var keys [][]byte
+
+	collect := func(key, _ []byte) bool {
+		if _, exists := seen[string(key)]; exists {
+			return true
+		}
+
+		seen[string(key)] = struct{}{}
+
+		va, oka := a.Lookup(key)
+		vb, okb := b.Lookup(key)
+
+		if oka != okb || !bytes.Equal(va, vb) {
+			keys = append(keys, append([]byte{}, key...))
+		}
+
+		return true
+	}
+
+	a.IterateLeafDiff(prevViewID, collect)
+	b.IterateLeafDiff(prevViewID, collect)
+
+	return keys
+}
+
+// findTransactionAffecting returns the first transaction that is sent by, sent to, or that spawns
+// an account stored under any of the given keys of the state.
+func findTransactionAffecting(txs []*Transaction, keys [][]byte) *Transaction {
+	accounts := make(map[AccountID]struct{})
+
+	for _, key := range keys {
+		if id, _, _, ok := decodeAccountKey(key); ok {
+			accounts[id] = struct{}{}
+		}
+	}
+
+	for _, tx := range txs {
+		if _, affected := accounts[tx.Sender]; affected {
+			return tx
+		}
+
+		if _, affected := accounts[tx.ID]; affected {
+			return tx
+		}
+
+		if tx.Tag == sys.TagTransfer {
+			if transfer, err := ParseTransfer(tx.Payload); err == nil {
+				if _, affected := accounts[transfer.Recipient]; affected {
+					return tx
+				}
+			}
+		}
+	}
+
+	return nil
+}
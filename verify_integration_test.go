@@ -0,0 +1,114 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// +build integration
+
+package wavelet
+
+import (
+	"testing"
+
+	"github.com/perlin-network/wavelet/sys"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	testnet, err := NewTestNetwork(WithSimNetwork(NewSimNetwork(7)))
+	FailTest(t, err)
+
+	defer testnet.Cleanup()
+
+	alice, err := testnet.AddNode(WithArchival())
+	FailTest(t, err)
+
+	_, err = testnet.AddNode()
+	FailTest(t, err)
+
+	FailTest(t, testnet.WaitUntilSync())
+
+	var txs []Transaction
+
+	for i := uint64(1); i <= 3; i++ {
+		tx, err := testnet.Faucet().Pay(alice, 1)
+		FailTest(t, err)
+
+		FailTest(t, alice.WaitUntilBalance(i))
+
+		txs = append(txs, tx)
+	}
+
+	latest := alice.BlockIndex()
+
+	height, divergence, err := Verify(alice.KV(), nil, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, latest, height)
+		assert.Nil(t, divergence)
+	}
+
+	// Re-executing from archived state at a later height also reproduces all blocks.
+	height, divergence, err = Verify(alice.KV(), nil, latest-1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, latest, height)
+		assert.Nil(t, divergence)
+	}
+
+	// Tamper with the archived copy of the second payment, such that it pays more than it did.
+	tampered := testnet.Faucet().newSignedTransaction(sys.TagTransfer, mustMarshalTransfer(t, alice, 100))
+	FailTest(t, alice.KV().Put(append(keyTransactionArchive[:], txs[1].ID[:]...), tampered.Marshal()))
+
+	block, err := alice.Ledger().Blocks().GetByIndex(txs[1].Block + 1)
+	FailTest(t, err)
+
+	for !containsTransaction(block, txs[1].ID) {
+		block, err = alice.Ledger().Blocks().GetByIndex(block.Index + 1)
+		FailTest(t, err)
+	}
+
+	height, divergence, err = Verify(alice.KV(), nil, 0)
+	if !assert.NoError(t, err) || !assert.NotNil(t, divergence) {
+		return
+	}
+
+	assert.Equal(t, block.Index-1, height)
+	assert.Equal(t, block.ID, divergence.Block.ID)
+	assert.Equal(t, block.Merkle, divergence.Expected)
+	assert.NotEqual(t, block.Merkle, divergence.Actual)
+	assert.NotEmpty(t, divergence.Keys)
+
+	if assert.NotNil(t, divergence.Transaction) {
+		assert.Equal(t, tampered.ID, divergence.Transaction.ID)
+	}
+}
+
+func mustMarshalTransfer(t *testing.T, recipient *TestLedger, amount uint64) []byte {
+	payload, err := Transfer{Recipient: recipient.PublicKey(), Amount: amount}.Marshal()
+	FailTest(t, err)
+
+	return payload
+}
+
+func containsTransaction(block *Block, id TransactionID) bool {
+	for _, tx := range block.Transactions {
+		if tx == id {
+			return true
+		}
+	}
+
+	return false
+}
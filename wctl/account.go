@@ -15,6 +15,10 @@ func (c *Client) GetSelf() (*Account, error) {
 
 // GetAccount calls the /accounts endpoint of the API.
 func (c *Client) GetAccount(account [32]byte) (*Account, error) {
+	if c.grpc != nil {
+		return c.grpcGetAccount(account)
+	}
+
 	path := RouteAccount + "/" + hex.EncodeToString(account[:])
 
 	var res Account
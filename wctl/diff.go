@@ -0,0 +1,98 @@
+package wctl
+
+import (
+	"net/url"
+
+	"github.com/perlin-network/wavelet"
+	"github.com/valyala/fastjson"
+)
+
+var _ UnmarshalableJSON = (*StateDiff)(nil)
+
+// StateDiff calls the /ledger/diff endpoint of the API. from and to are either block heights, or
+// hex-encoded merkle roots. to is optional, and defaults to the latest state.
+func (c *Client) StateDiff(from, to string) (*StateDiff, error) {
+	query := url.Values{"from": {from}}
+	if len(to) > 0 {
+		query.Set("to", to)
+	}
+
+	var res StateDiff
+
+	if err := c.RequestJSON(RouteLedgerDiff+"?"+query.Encode(), ReqGet, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+type StateDiff struct {
+	From     [16]byte
+	To       [16]byte
+	Accounts []wavelet.AccountDiff
+}
+
+func (s *StateDiff) UnmarshalJSON(b []byte) error {
+	var parser fastjson.Parser
+
+	v, err := parser.ParseBytes(b)
+	if err != nil {
+		return err
+	}
+
+	if err := jsonHex(v, s.From[:], "from"); err != nil {
+		return err
+	}
+
+	if err := jsonHex(v, s.To[:], "to"); err != nil {
+		return err
+	}
+
+	accounts := v.GetArray("accounts")
+	s.Accounts = make([]wavelet.AccountDiff, len(accounts))
+
+	for i, a := range accounts {
+		diff := &s.Accounts[i]
+
+		if err := jsonHex(a, diff.ID[:], "account_id"); err != nil {
+			return err
+		}
+
+		diff.Balance = jsonUint64Change(a, "balance")
+		diff.Stake = jsonUint64Change(a, "stake")
+		diff.Reward = jsonUint64Change(a, "reward")
+		diff.GasBalance = jsonUint64Change(a, "gas_balance")
+		diff.NumPages = jsonUint64Change(a, "num_pages")
+
+		if a.Exists("code") {
+			diff.Code = &wavelet.CodeChange{}
+
+			if err := jsonHex(a, diff.Code.Before[:], "code", "before"); err != nil {
+				return err
+			}
+
+			if err := jsonHex(a, diff.Code.After[:], "code", "after"); err != nil {
+				return err
+			}
+		}
+
+		for _, page := range a.GetArray("pages") {
+			diff.Pages = append(diff.Pages, page.GetUint64())
+		}
+
+		diff.Globals = a.GetBool("globals")
+	}
+
+	return nil
+}
+
+func jsonUint64Change(v *fastjson.Value, key string) *wavelet.Uint64Change {
+	if !v.Exists(key) {
+		return nil
+	}
+
+	return &wavelet.Uint64Change{
+		Before: v.GetUint64(key, "before"),
+		After:  v.GetUint64(key, "after"),
+	}
+}
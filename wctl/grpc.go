@@ -0,0 +1,180 @@
+package wctl
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/perlin-network/wavelet/api/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// dialGRPC connects to the gRPC API of the node, which serves some calls in place of the HTTP API.
+func (c *Client) dialGRPC() error {
+	opt := grpc.WithInsecure()
+	if c.UseHTTPS {
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{ServerName: c.APIHost}))
+	}
+
+	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", c.APIHost, c.GRPCPort), opt)
+	if err != nil {
+		return err
+	}
+
+	c.grpcConn = conn
+	c.grpc = pb.NewAPIClient(conn)
+
+	return nil
+}
+
+// grpcContext returns a context for a call to the gRPC API, which times out and is
+// authorized as requests to the HTTP API are.
+func (c *Client) grpcContext() (context.Context, context.CancelFunc) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+c.APISecret)
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+func (c *Client) grpcLedgerStatus() (*LedgerStatusResponse, error) {
+	ctx, cancel := c.grpcContext()
+	defer cancel()
+
+	res, err := c.grpc.GetLedgerStatus(ctx, &pb.GetLedgerStatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	l := &LedgerStatusResponse{
+		HostAddress:    res.Address,
+		NumAccounts:    int(res.NumAccounts),
+		NumTx:          res.NumTx,
+		NumMissingTx:   res.NumMissingTx,
+		NumTxInStore:   res.NumTxInStore,
+		PreferredVotes: int(res.PreferredVotes),
+	}
+
+	copy(l.PublicKey[:], res.PublicKey)
+
+	if res.Block != nil {
+		copy(l.Block.MerkleRoot[:], res.Block.MerkleRoot)
+		copy(l.Block.ID[:], res.Block.Id)
+		l.Block.Index = res.Block.Height
+		l.Block.Txs = res.Block.NumTransactions
+	}
+
+	if res.Preferred != nil {
+		l.Preferred = &struct {
+			MerkleRoot [16]byte `json:"merkle_root"`
+			Index      uint64   `json:"height"`
+			ID         [32]byte `json:"id"`
+			Txs        uint64   `json:"transactions"`
+		}{}
+
+		copy(l.Preferred.MerkleRoot[:], res.Preferred.MerkleRoot)
+		copy(l.Preferred.ID[:], res.Preferred.Id)
+		l.Preferred.Index = res.Preferred.Height
+		l.Preferred.Txs = res.Preferred.NumTransactions
+	}
+
+	if res.Sync != nil {
+		l.Sync = &SyncProgress{
+			Height:        res.Sync.Height,
+			ChunksDone:    int(res.Sync.ChunksDone),
+			ChunksTotal:   int(res.Sync.ChunksTotal),
+			ChunksResumed: int(res.Sync.ChunksResumed),
+			BytesDone:     res.Sync.BytesDone,
+			ETAMillis:     res.Sync.EtaMs,
+		}
+	}
+
+	l.Peers = make([]Peer, len(res.Peers))
+
+	for i, peer := range res.Peers {
+		l.Peers[i].Address = peer.Address
+		copy(l.Peers[i].PublicKey[:], peer.PublicKey)
+	}
+
+	return l, nil
+}
+
+func (c *Client) grpcGetAccount(account [32]byte) (*Account, error) {
+	ctx, cancel := c.grpcContext()
+	defer cancel()
+
+	res, err := c.grpc.GetAccount(ctx, &pb.GetAccountRequest{Id: account[:]})
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Account{
+		Balance:    res.Balance,
+		GasBalance: res.GasBalance,
+		Stake:      res.Stake,
+		Reward:     res.Reward,
+		IsContract: res.IsContract,
+		NumPages:   res.NumPages,
+	}
+
+	copy(a.PublicKey[:], res.Id)
+
+	return a, nil
+}
+
+func (c *Client) grpcGetTransaction(txID [32]byte) (*Transaction, error) {
+	ctx, cancel := c.grpcContext()
+	defer cancel()
+
+	res, err := c.grpc.GetTransaction(ctx, &pb.GetTransactionRequest{Id: txID[:]})
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &Transaction{
+		Status:  strings.ToLower(res.Status.String()),
+		Nonce:   res.Nonce,
+		Tag:     byte(res.Tag),
+		Payload: res.Payload,
+	}
+
+	copy(tx.ID[:], res.Id)
+	copy(tx.Sender[:], res.Sender)
+	copy(tx.Signature[:], res.Signature)
+
+	return tx, nil
+}
+
+func (c *Client) grpcSendTransaction(req *TxRequest) (*TxResponse, error) {
+	ctx, cancel := c.grpcContext()
+	defer cancel()
+
+	res, err := c.grpc.SendTransaction(ctx, &pb.SendTransactionRequest{
+		Sender:    req.Sender[:],
+		Nonce:     req.Nonce,
+		Block:     req.Block,
+		Tag:       uint32(req.Tag),
+		Payload:   req.Payload,
+		Signature: req.Signature[:],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var tx TxResponse
+	copy(tx.ID[:], res.Id)
+
+	return &tx, nil
+}
+
+func (c *Client) grpcGetContractPage(contractID [32]byte, index uint64) ([]byte, error) {
+	ctx, cancel := c.grpcContext()
+	defer cancel()
+
+	res, err := c.grpc.GetContractPage(ctx, &pb.GetContractPageRequest{Id: contractID[:], Index: index})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Page, nil
+}
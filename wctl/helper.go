@@ -48,6 +48,11 @@ func (c *Client) RequestJSON(path, method string, body MarshalableJSON, out Unma
 // Request will make a request to a given path, with a given body and return
 // the result in raw bytes.
 func (c *Client) Request(path string, method string, body []byte) ([]byte, error) {
+	return c.requestTimeout(path, method, body, 5*time.Second)
+}
+
+// requestTimeout is Request, waiting up to timeout for a response rather than 5 seconds.
+func (c *Client) requestTimeout(path string, method string, body []byte, timeout time.Duration) ([]byte, error) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
@@ -65,11 +70,11 @@ func (c *Client) Request(path string, method string, body []byte) ([]byte, error
 	res := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(res)
 
-	if err := fasthttp.DoTimeout(req, res, 5*time.Second); err != nil {
+	if err := fasthttp.DoTimeout(req, res, timeout); err != nil {
 		return nil, err
 	}
 
-	if res.StatusCode() != http.StatusOK {
+	if res.StatusCode() != http.StatusOK && res.StatusCode() != http.StatusAccepted {
 		if err := ParseRequestError(res.Body()); err != nil {
 			return nil, err
 		}
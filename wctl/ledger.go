@@ -1,6 +1,8 @@
 package wctl
 
 import (
+	"time"
+
 	"github.com/valyala/fastjson"
 )
 
@@ -9,6 +11,10 @@ var _ UnmarshalableJSON = (*LedgerStatusResponse)(nil)
 // GetLedgerStatus calls the /ledger endpoint of the API. All arguments are
 // optional.
 func (c *Client) LedgerStatus() (*LedgerStatusResponse, error) {
+	if c.grpc != nil {
+		return c.grpcLedgerStatus()
+	}
+
 	var res LedgerStatusResponse
 
 	if err := c.RequestJSON(RouteLedger, ReqGet, nil, &res); err != nil {
@@ -45,9 +51,26 @@ type LedgerStatusResponse struct {
 
 	PreferredVotes int `json:"preferred_votes"`
 
+	Sync *SyncProgress `json:"sync"`
+
 	Peers []Peer `json:"peers"`
 }
 
+// SyncProgress is how far along a node is in downloading the latest state from its peers.
+type SyncProgress struct {
+	Height        uint64 `json:"height"`
+	ChunksDone    int    `json:"chunks_done"`
+	ChunksTotal   int    `json:"chunks_total"`
+	ChunksResumed int    `json:"chunks_resumed"`
+	BytesDone     uint64 `json:"bytes_done"`
+	ETAMillis     int64  `json:"eta_ms"`
+}
+
+// ETA returns the estimated time left until the node finishes syncing.
+func (s SyncProgress) ETA() time.Duration {
+	return time.Duration(s.ETAMillis) * time.Millisecond
+}
+
 type Peer struct {
 	Address   string   `json:"address"`
 	PublicKey [32]byte `json:"public_key"`
@@ -110,6 +133,17 @@ func (l *LedgerStatusResponse) UnmarshalJSON(b []byte) error {
 
 	l.PreferredVotes = v.GetInt("preferred_votes")
 
+	if v.Exists("sync") && v.Get("sync").Type() != fastjson.TypeNull {
+		l.Sync = &SyncProgress{
+			Height:        v.GetUint64("sync", "height"),
+			ChunksDone:    v.GetInt("sync", "chunks_done"),
+			ChunksTotal:   v.GetInt("sync", "chunks_total"),
+			ChunksResumed: v.GetInt("sync", "chunks_resumed"),
+			BytesDone:     v.GetUint64("sync", "bytes_done"),
+			ETAMillis:     v.GetInt64("sync", "eta_ms"),
+		}
+	}
+
 	peerValue := v.GetArray("peers")
 	l.Peers = make([]Peer, len(peerValue))
 
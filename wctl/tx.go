@@ -14,6 +14,7 @@ import (
 
 var (
 	_ UnmarshalableJSON = (*TxResponse)(nil)
+	_ UnmarshalableJSON = (*TxReceipt)(nil)
 	_ UnmarshalableJSON = (*Transaction)(nil)
 	_ UnmarshalableJSON = (*TransactionList)(nil)
 	_ MarshalableJSON   = (*TxRequest)(nil)
@@ -22,6 +23,18 @@ var (
 var (
 	// ErrInsufficientPerls is returned when you don't have enough PERLs.
 	ErrInsufficientPerls = errors.New("insufficient PERLs")
+
+	// ErrTxNotFinalized is returned when a transaction waited for is not finalized in time.
+	ErrTxNotFinalized = errors.New("transaction was not finalized in time")
+)
+
+const (
+	TxStatusApplied  = "applied"
+	TxStatusRejected = "rejected"
+	TxStatusPending  = "pending"
+
+	// defaultTxWaitTimeout is how long a node waits for a transaction to be finalized by default.
+	defaultTxWaitTimeout = 30 * time.Second
 )
 
 type TransactionEvent struct {
@@ -67,6 +80,10 @@ func (c *Client) ListTransactions(
 
 // GetTransaction calls the /tx endpoint to query a single transaction.
 func (c *Client) GetTransaction(txID [32]byte) (*Transaction, error) {
+	if c.grpc != nil {
+		return c.grpcGetTransaction(txID)
+	}
+
 	path := RouteTxList + "/" + hex.EncodeToString(txID[:])
 
 	var res Transaction
@@ -82,6 +99,64 @@ func (c *Client) GetTransaction(txID [32]byte) (*Transaction, error) {
 func (c *Client) SendTransaction(tag byte, payload []byte) (*TxResponse, error) {
 	var res TxResponse
 
+	req := c.signTransaction(tag, payload)
+
+	if c.grpc != nil {
+		return c.grpcSendTransaction(&req)
+	}
+
+	if err := c.RequestJSON(RouteTxSend, ReqPost, &req, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// SendAndWait calls the /tx/send endpoint to send a raw payload, and waits up
+// to timeout for it to be applied or rejected in a finalized block. A zero
+// timeout waits as long as the node does by default.
+//
+// The receipt tells whether the transaction was applied or rejected, and the
+// height of the block it was finalized in. Should the transaction not be
+// finalized in time, the receipt is pending and ErrTxNotFinalized is returned.
+func (c *Client) SendAndWait(tag byte, payload []byte, timeout time.Duration) (*TxReceipt, error) {
+	req := c.signTransaction(tag, payload)
+
+	body, err := req.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	vals := url.Values{}
+	vals.Set("wait", "finalized")
+
+	// The node answers once it is done waiting, so the request itself may take a while longer.
+	requestTimeout := defaultTxWaitTimeout
+
+	if timeout > 0 {
+		vals.Set("timeout", timeout.String())
+		requestTimeout = timeout
+	}
+
+	resBody, err := c.requestTimeout(RouteTxSend+"?"+vals.Encode(), ReqPost, body, requestTimeout+5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var res TxReceipt
+	if err := res.UnmarshalJSON(resBody); err != nil {
+		return nil, err
+	}
+
+	if res.Status == TxStatusPending {
+		return &res, ErrTxNotFinalized
+	}
+
+	return &res, nil
+}
+
+// signTransaction signs a raw payload to be sent as a transaction.
+func (c *Client) signTransaction(tag byte, payload []byte) TxRequest {
 	nonce := uint64(time.Now().UnixNano())
 	block := c.Block.Load()
 
@@ -98,7 +173,7 @@ func (c *Client) SendTransaction(tag byte, payload []byte) (*TxResponse, error)
 		append(nonceBuf[:], append(blockBuf[:], append([]byte{tag}, payload...)...)...),
 	)
 
-	req := TxRequest{
+	return TxRequest{
 		Sender:    c.PublicKey,
 		Nonce:     nonce,
 		Block:     block,
@@ -106,12 +181,6 @@ func (c *Client) SendTransaction(tag byte, payload []byte) (*TxResponse, error)
 		Payload:   payload,
 		Signature: signature,
 	}
-
-	if err := c.RequestJSON(RouteTxSend, ReqPost, &req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
 }
 
 // SendTransfer sends a wavelet.Transfer instead of a Payload.
@@ -224,6 +293,33 @@ func (s *TxRequest) MarshalJSON() ([]byte, error) {
 	return o.MarshalTo(nil), nil
 }
 
+// TxReceipt is the outcome of a transaction waited for to be finalized.
+type TxReceipt struct {
+	ID     [32]byte `json:"id"`
+	Status string   `json:"status"`
+	Height uint64   `json:"height"`
+	Error  string   `json:"error"`
+}
+
+func (r *TxReceipt) UnmarshalJSON(b []byte) error {
+	var parser fastjson.Parser
+
+	v, err := parser.ParseBytes(b)
+	if err != nil {
+		return err
+	}
+
+	if err := jsonHex(v, r.ID[:], "id"); err != nil {
+		return err
+	}
+
+	r.Status = string(v.GetStringBytes("status"))
+	r.Height = v.GetUint64("height")
+	r.Error = string(v.GetStringBytes("error"))
+
+	return nil
+}
+
 type TxResponse struct {
 	ID [32]byte `json:"id"`
 	// Parents  [][32]byte `json:"parent_ids"`
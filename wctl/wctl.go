@@ -22,6 +22,7 @@ package wctl
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -30,25 +31,32 @@ import (
 	"time"
 
 	"github.com/perlin-network/noise/edwards25519"
+	"github.com/perlin-network/wavelet/api/pb"
 	"github.com/perlin-network/wavelet/cmd/wavelet/node"
 	"github.com/valyala/fastjson"
 	"go.uber.org/atomic"
+	"google.golang.org/grpc"
 )
 
 const (
-	RouteLedger   = "/ledger"
-	RouteAccount  = "/accounts"
-	RouteContract = "/contract"
-	RouteTxList   = "/tx"
-	RouteTxSend   = "/tx/send"
+	RouteLedger     = "/ledger"
+	RouteLedgerDiff = RouteLedger + "/diff"
+	RouteAccount    = "/accounts"
+	RouteContract   = "/contract"
+	RouteTxList     = "/tx"
+	RouteTxSend     = "/tx/send"
 
 	RouteNode       = "/node"
 	RouteConnect    = RouteNode + "/connect"
 	RouteDisconnect = RouteNode + "/disconnect"
 	RouteRestart    = RouteNode + "/restart"
 
-	ReqPost = "POST"
-	ReqGet  = "GET"
+	RouteWebhooks     = "/webhooks"
+	RouteWebhooksDead = RouteWebhooks + "/dead"
+
+	ReqPost   = "POST"
+	ReqGet    = "GET"
+	ReqDelete = "DELETE"
 )
 
 var ErrNoHost = errors.New("no host provided")
@@ -65,6 +73,10 @@ type Config struct {
 	UseHTTPS   bool
 	Timeout    time.Duration
 
+	// Optional. If set, the calls that the gRPC API serves go through it
+	// instead of the HTTP API.
+	GRPCPort uint16
+
 	// Optional
 	Server *node.Wavelet
 }
@@ -80,6 +92,9 @@ type Client struct {
 	jsonPool fastjson.ParserPool
 	url      string
 
+	grpc     pb.APIClient
+	grpcConn *grpc.ClientConn
+
 	// Local state counters
 	Block *atomic.Uint64
 
@@ -157,6 +172,12 @@ func NewClient(config Config) (*Client, error) {
 		Block: atomic.NewUint64(0),
 	}
 
+	if config.GRPCPort != 0 {
+		if err := c.dialGRPC(); err != nil {
+			return nil, err
+		}
+	}
+
 	ls, err := c.LedgerStatus()
 	if err != nil {
 		return c, err
@@ -183,6 +204,10 @@ func (c *Client) Close() {
 	for _, c := range c.stopSockets {
 		c()
 	}
+
+	if c.grpcConn != nil {
+		_ = c.grpcConn.Close()
+	}
 }
 
 func (c *Client) GetContractCode(contractID string) (string, error) {
@@ -193,6 +218,24 @@ func (c *Client) GetContractCode(contractID string) (string, error) {
 }
 
 func (c *Client) GetContractPages(contractID string, index *uint64) (string, error) {
+	if c.grpc != nil && index != nil {
+		buf, err := hex.DecodeString(contractID)
+		if err != nil {
+			return "", err
+		}
+
+		var id [32]byte
+		if len(buf) != len(id) {
+			return "", fmt.Errorf("contract ID must be %d bytes long", len(id))
+		}
+
+		copy(id[:], buf)
+
+		page, err := c.grpcGetContractPage(id, *index)
+
+		return base64.StdEncoding.EncodeToString(page), err
+	}
+
 	path := fmt.Sprintf("%s/%s/page", RouteContract, contractID)
 	if index != nil {
 		path = fmt.Sprintf("%s/%d", path, *index)
@@ -0,0 +1,216 @@
+package wctl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/valyala/fastjson"
+)
+
+var _ UnmarshalableJSON = (*Webhook)(nil)
+var _ UnmarshalableJSON = (*Webhooks)(nil)
+var _ UnmarshalableJSON = (*DeadLetters)(nil)
+
+// Webhook is a URL the events of a topic which pass its filters are posted to.
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+
+	// Topic is the module whose events are posted, as streamed under /poll.
+	Topic string `json:"topic"`
+
+	// Filters are keyed by the query parameters of the topic under /poll, e.g. "id" for accounts.
+	Filters map[string]string `json:"filters"`
+
+	// Event, if not empty, is the only type of event posted.
+	Event string `json:"event"`
+
+	// Secret keys the signatures of the events posted. It is only returned upon registering a
+	// webhook, and is generated if empty.
+	Secret string `json:"secret"`
+}
+
+func (w *Webhook) MarshalJSON() ([]byte, error) {
+	var arena fastjson.Arena
+
+	o := arena.NewObject()
+	o.Set("url", arena.NewString(w.URL))
+	o.Set("topic", arena.NewString(w.Topic))
+
+	filters := arena.NewObject()
+	for key, value := range w.Filters {
+		filters.Set(key, arena.NewString(value))
+	}
+
+	o.Set("filters", filters)
+
+	if len(w.Event) > 0 {
+		o.Set("event", arena.NewString(w.Event))
+	}
+
+	if len(w.Secret) > 0 {
+		o.Set("secret", arena.NewString(w.Secret))
+	}
+
+	return o.MarshalTo(nil), nil
+}
+
+func (w *Webhook) UnmarshalJSON(b []byte) error {
+	var parser fastjson.Parser
+
+	v, err := parser.ParseBytes(b)
+	if err != nil {
+		return err
+	}
+
+	w.ParseJSON(v)
+
+	return nil
+}
+
+func (w *Webhook) ParseJSON(v *fastjson.Value) {
+	w.ID = string(v.GetStringBytes("id"))
+	w.URL = string(v.GetStringBytes("url"))
+	w.Topic = string(v.GetStringBytes("topic"))
+	w.Event = string(v.GetStringBytes("event"))
+	w.Secret = string(v.GetStringBytes("secret"))
+	w.Filters = make(map[string]string)
+
+	v.GetObject("filters").Visit(func(key []byte, v *fastjson.Value) {
+		w.Filters[string(key)] = string(v.GetStringBytes())
+	})
+}
+
+type Webhooks []Webhook
+
+func (w *Webhooks) UnmarshalJSON(b []byte) error {
+	var parser fastjson.Parser
+
+	v, err := parser.ParseBytes(b)
+	if err != nil {
+		return err
+	}
+
+	a, err := v.Array()
+	if err != nil {
+		return err
+	}
+
+	*w = make(Webhooks, len(a))
+
+	for i := range a {
+		(*w)[i].ParseJSON(a[i])
+	}
+
+	return nil
+}
+
+// DeadLetter is an event which failed to be delivered to a webhook.
+type DeadLetter struct {
+	WebhookID string    `json:"webhook_id"`
+	URL       string    `json:"url"`
+	Event     []byte    `json:"event"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+type DeadLetters []DeadLetter
+
+func (d *DeadLetters) UnmarshalJSON(b []byte) error {
+	var parser fastjson.Parser
+
+	v, err := parser.ParseBytes(b)
+	if err != nil {
+		return err
+	}
+
+	a, err := v.Array()
+	if err != nil {
+		return err
+	}
+
+	*d = make(DeadLetters, len(a))
+
+	for i := range a {
+		letter := &(*d)[i]
+
+		letter.WebhookID = string(a[i].GetStringBytes("webhook_id"))
+		letter.URL = string(a[i].GetStringBytes("url"))
+		letter.Event = a[i].Get("event").MarshalTo(nil)
+		letter.Attempts = a[i].GetInt("attempts")
+		letter.Error = string(a[i].GetStringBytes("error"))
+
+		failedAt, err := time.Parse(time.RFC3339, string(a[i].GetStringBytes("failed_at")))
+		if err != nil {
+			return err
+		}
+
+		letter.FailedAt = failedAt
+	}
+
+	return nil
+}
+
+// RegisterWebhook calls the /webhooks endpoint of the API to register a webhook. The webhook
+// registered is returned along with its secret.
+func (c *Client) RegisterWebhook(hook Webhook) (*Webhook, error) {
+	var res Webhook
+
+	if err := c.RequestJSON(RouteWebhooks, ReqPost, &hook, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// ListWebhooks calls the /webhooks endpoint of the API to list the webhooks registered.
+func (c *Client) ListWebhooks() (Webhooks, error) {
+	var res Webhooks
+
+	if err := c.RequestJSON(RouteWebhooks, ReqGet, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteWebhook calls the /webhooks/:id endpoint of the API to delete a webhook.
+func (c *Client) DeleteWebhook(id string) (*MsgResponse, error) {
+	var res MsgResponse
+
+	if err := c.RequestJSON(RouteWebhooks+"/"+id, ReqDelete, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// WebhookDeadLetters calls the /webhooks/dead endpoint of the API to list the latest events
+// which failed to be delivered to webhooks.
+func (c *Client) WebhookDeadLetters() (DeadLetters, error) {
+	var res DeadLetters
+
+	if err := c.RequestJSON(RouteWebhooksDead, ReqGet, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// VerifyWebhookSignature returns whether or not signature, the X-Wavelet-Signature header of an
+// event posted to a webhook, is the signature of body by the secret of the webhook.
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+
+	return hmac.Equal(sig, mac.Sum(nil))
+}